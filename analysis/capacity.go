@@ -0,0 +1,284 @@
+package analysis
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	qradar "github.com/ilyaglow/go-qradar"
+)
+
+// DefaultCapacityPercentile is the share of the measured rules flagged as
+// expensive if the capacity threshold is not set.
+const DefaultCapacityPercentile = 0.1
+
+// CapacityOptions represents options of the capacity report.
+type CapacityOptions struct {
+	// Threshold is the average capacity in EPS at or below which a rule is
+	// flagged as expensive. If zero, the threshold is the capacity of the
+	// Percentile share of the cheapest measured rules.
+	Threshold int
+	// Percentile defaults to DefaultCapacityPercentile.
+	Percentile float64
+}
+
+// CapacityEntry represents the performance of a rule or a building block.
+//
+// The capacity is the number of events per second the rule can process, so
+// the lower the capacity the more expensive the rule.
+type CapacityEntry struct {
+	Kind            NodeKind   `json:"kind"`
+	ID              int        `json:"id"`
+	Identifier      string     `json:"identifier,omitempty"`
+	Name            string     `json:"name"`
+	Owner           string     `json:"owner,omitempty"`
+	Origin          string     `json:"origin,omitempty"`
+	Enabled         bool       `json:"enabled"`
+	Groups          []string   `json:"groups,omitempty"`
+	AverageCapacity int        `json:"average_capacity"`
+	BaseCapacity    int        `json:"base_capacity"`
+	BaseHostID      int        `json:"base_host_id,omitempty"`
+	MeasuredAt      *time.Time `json:"measured_at,omitempty"`
+	// Expensive is set if the capacity is at or below the threshold.
+	Expensive bool `json:"expensive"`
+	// DisabledCostly is set if the rule is expensive but disabled, it's
+	// likely to hurt the performance once enabled.
+	DisabledCostly bool `json:"disabled_costly"`
+}
+
+// Measured returns true if the capacity of the rule was measured.
+func (e *CapacityEntry) Measured() bool {
+	return e.AverageCapacity > 0
+}
+
+// CapacityReport represents the rules and building blocks ranked by their
+// cost: measured ones from the lowest capacity, followed by unmeasured ones.
+type CapacityReport struct {
+	GeneratedAt time.Time       `json:"generated_at"`
+	Threshold   int             `json:"threshold"`
+	Entries     []CapacityEntry `json:"entries"`
+}
+
+// LoadCapacityReport fetches the rules, building blocks and rule groups and
+// builds the capacity report.
+func LoadCapacityReport(ctx context.Context, client *qradar.Client, opts *CapacityOptions) (*CapacityReport, error) {
+	rules, err := client.Rule.Get(ctx, "", "", 0, 0)
+	if err != nil {
+		return nil, err
+	}
+	bbs, err := client.BuildingBlock.Get(ctx, "", "", 0, 0)
+	if err != nil {
+		return nil, err
+	}
+	groups, err := client.RuleGroup.Get(ctx, "id,name,child_items", "", 0, 0)
+	if err != nil {
+		return nil, err
+	}
+	return BuildCapacityReport(rules, bbs, groups, opts), nil
+}
+
+// BuildCapacityReport builds the capacity report of the rules and building
+// blocks joined with the rule groups.
+func BuildCapacityReport(rules []qradar.Rule, bbs []qradar.BuildingBlock, groups []qradar.RuleGroup, opts *CapacityOptions) *CapacityReport {
+	var o CapacityOptions
+	if opts != nil {
+		o = *opts
+	}
+	if o.Percentile <= 0 {
+		o.Percentile = DefaultCapacityPercentile
+	}
+
+	memberOf := make(map[string][]string)
+	for _, g := range groups {
+		if g.Name == nil {
+			continue
+		}
+		for _, item := range g.ChildItems {
+			memberOf[item] = append(memberOf[item], *g.Name)
+		}
+	}
+
+	r := &CapacityReport{GeneratedAt: time.Now()}
+	seen := make(map[int]bool)
+	for _, rule := range rules {
+		if rule.ID == nil {
+			continue
+		}
+		seen[*rule.ID] = true
+		r.Entries = append(r.Entries, capacityEntry(KindRule, rule.ID, rule.Identifier, rule.Name, rule.Owner, rule.Origin,
+			rule.Enabled, rule.AverageCapacity, rule.BaseCapacity, rule.BaseHostID, rule.CapacityTimestamp))
+	}
+	for _, bb := range bbs {
+		if bb.ID == nil || seen[*bb.ID] {
+			continue
+		}
+		r.Entries = append(r.Entries, capacityEntry(KindBuildingBlock, bb.ID, bb.Identifier, bb.Name, bb.Owner, bb.Origin,
+			bb.Enabled, bb.AverageCapacity, bb.BaseCapacity, bb.BaseHostID, bb.CapacityTimestamp))
+	}
+	for i := range r.Entries {
+		e := &r.Entries[i]
+		e.Groups = memberOf[strconv.Itoa(e.ID)]
+		sort.Strings(e.Groups)
+	}
+
+	sort.SliceStable(r.Entries, func(i, j int) bool {
+		a, b := &r.Entries[i], &r.Entries[j]
+		if a.Measured() != b.Measured() {
+			return a.Measured()
+		}
+		if a.AverageCapacity != b.AverageCapacity {
+			return a.AverageCapacity < b.AverageCapacity
+		}
+		return a.ID < b.ID
+	})
+
+	r.Threshold = o.Threshold
+	if r.Threshold <= 0 {
+		measured := 0
+		for i := range r.Entries {
+			if r.Entries[i].Measured() {
+				measured++
+			}
+		}
+		if n := int(math.Ceil(float64(measured) * o.Percentile)); n > 0 {
+			r.Threshold = r.Entries[n-1].AverageCapacity
+		}
+	}
+
+	for i := range r.Entries {
+		e := &r.Entries[i]
+		e.Expensive = e.Measured() && e.AverageCapacity <= r.Threshold
+		e.DisabledCostly = e.Expensive && !e.Enabled
+	}
+
+	return r
+}
+
+func capacityEntry(kind NodeKind, id *int, identifier, name, owner, origin *string, enabled *bool, avg, base, host, ts *int) CapacityEntry {
+	e := CapacityEntry{
+		Kind:            kind,
+		ID:              *id,
+		Identifier:      stringValue(identifier),
+		Name:            stringValue(name),
+		Owner:           stringValue(owner),
+		Origin:          stringValue(origin),
+		Enabled:         enabled != nil && *enabled,
+		AverageCapacity: intValue(avg),
+		BaseCapacity:    intValue(base),
+		BaseHostID:      intValue(host),
+	}
+	if ts != nil && *ts > 0 {
+		t := time.UnixMilli(int64(*ts)).UTC()
+		e.MeasuredAt = &t
+	}
+	return e
+}
+
+// Expensive returns the entries flagged as expensive.
+func (r *CapacityReport) Expensive() []CapacityEntry {
+	var result []CapacityEntry
+	for _, e := range r.Entries {
+		if e.Expensive {
+			result = append(result, e)
+		}
+	}
+	return result
+}
+
+// WriteJSON writes the report as indented JSON.
+func (r *CapacityReport) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r)
+}
+
+var capacityColumns = []string{
+	"rank", "kind", "id", "name", "owner", "enabled", "groups",
+	"average_capacity", "base_capacity", "measured_at", "flags",
+}
+
+func (e *CapacityEntry) row(rank int) []string {
+	measured := ""
+	if e.MeasuredAt != nil {
+		measured = e.MeasuredAt.Format(time.RFC3339)
+	}
+	return []string{
+		strconv.Itoa(rank),
+		string(e.Kind),
+		strconv.Itoa(e.ID),
+		e.Name,
+		e.Owner,
+		strconv.FormatBool(e.Enabled),
+		strings.Join(e.Groups, "; "),
+		strconv.Itoa(e.AverageCapacity),
+		strconv.Itoa(e.BaseCapacity),
+		measured,
+		e.flags(),
+	}
+}
+
+func (e *CapacityEntry) flags() string {
+	var flags []string
+	if e.Expensive {
+		flags = append(flags, "expensive")
+	}
+	if e.DisabledCostly {
+		flags = append(flags, "disabled_costly")
+	}
+	if !e.Measured() {
+		flags = append(flags, "unmeasured")
+	}
+	return strings.Join(flags, ",")
+}
+
+// WriteCSV writes the report as CSV with a header.
+func (r *CapacityReport) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	err := cw.Write(capacityColumns)
+	if err != nil {
+		return err
+	}
+	for i := range r.Entries {
+		err = cw.Write(r.Entries[i].row(i + 1))
+		if err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// WriteTable writes the first limit entries of the report as an aligned
+// table for the terminal, all entries are written if limit is not positive.
+func (r *CapacityReport) WriteTable(w io.Writer, limit int) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	_, err := fmt.Fprintln(tw, strings.ToUpper(strings.Join(capacityColumns, "\t")))
+	if err != nil {
+		return err
+	}
+	for i := range r.Entries {
+		if limit > 0 && i >= limit {
+			break
+		}
+		_, err = fmt.Fprintln(tw, strings.Join(r.Entries[i].row(i+1), "\t"))
+		if err != nil {
+			return err
+		}
+	}
+	return tw.Flush()
+}
+
+func intValue(n *int) int {
+	if n == nil {
+		return 0
+	}
+	return *n
+}
@@ -0,0 +1,97 @@
+package analysis
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	qradar "github.com/ilyaglow/go-qradar"
+)
+
+func TestBuildCapacityReport(t *testing.T) {
+	rules := []qradar.Rule{
+		{ID: ptr(1), Name: ptr("cheap"), Enabled: ptr(true), AverageCapacity: ptr(9000)},
+		{ID: ptr(2), Name: ptr("costly"), Enabled: ptr(false), AverageCapacity: ptr(100), CapacityTimestamp: ptr(1700000000000)},
+		{ID: ptr(3), Name: ptr("unmeasured"), Enabled: ptr(true)},
+		{ID: ptr(4), Name: ptr("medium"), Enabled: ptr(true), AverageCapacity: ptr(500)},
+		{Name: ptr("no id")},
+	}
+	bbs := []qradar.BuildingBlock{
+		{ID: ptr(4), Name: ptr("duplicate of the rule")},
+		{ID: ptr(5), Name: ptr("bb"), Enabled: ptr(true), AverageCapacity: ptr(300)},
+	}
+	groups := []qradar.RuleGroup{
+		{Name: ptr("Recon"), ChildItems: []string{"2", "5"}},
+		{Name: ptr("Anomaly"), ChildItems: []string{"2"}},
+	}
+
+	tests := []struct {
+		name   string
+		opts   *CapacityOptions
+		order  []int
+		thresh int
+		flags  []string
+	}{
+		{
+			name:   "percentile",
+			opts:   &CapacityOptions{Percentile: 0.5},
+			order:  []int{2, 5, 4, 1, 3},
+			thresh: 300,
+			flags:  []string{"expensive,disabled_costly", "expensive", "", "", "unmeasured"},
+		},
+		{
+			name:   "default percentile",
+			order:  []int{2, 5, 4, 1, 3},
+			thresh: 100,
+			flags:  []string{"expensive,disabled_costly", "", "", "", "unmeasured"},
+		},
+		{
+			name:   "threshold",
+			opts:   &CapacityOptions{Threshold: 500},
+			order:  []int{2, 5, 4, 1, 3},
+			thresh: 500,
+			flags:  []string{"expensive,disabled_costly", "expensive", "expensive", "", "unmeasured"},
+		},
+	}
+	for _, tt := range tests {
+		r := BuildCapacityReport(rules, bbs, groups, tt.opts)
+		if r.Threshold != tt.thresh {
+			t.Errorf("%s: threshold %d, want %d", tt.name, r.Threshold, tt.thresh)
+		}
+		if len(r.Entries) != len(tt.order) {
+			t.Fatalf("%s: got %d entries, want %d", tt.name, len(r.Entries), len(tt.order))
+		}
+		for i, id := range tt.order {
+			e := &r.Entries[i]
+			if e.ID != id || e.flags() != tt.flags[i] {
+				t.Errorf("%s: entry %d is %d %q, want %d %q", tt.name, i, e.ID, e.flags(), id, tt.flags[i])
+			}
+		}
+	}
+
+	r := BuildCapacityReport(rules, bbs, groups, nil)
+	costly := r.Entries[0]
+	if strings.Join(costly.Groups, ",") != "Anomaly,Recon" || costly.MeasuredAt == nil || costly.MeasuredAt.UnixMilli() != 1700000000000 {
+		t.Errorf("unexpected entry %+v", costly)
+	}
+	if r.Entries[1].Kind != KindBuildingBlock {
+		t.Errorf("entry 5 kind %s, want %s", r.Entries[1].Kind, KindBuildingBlock)
+	}
+}
+
+func TestCapacityReportWriteCSV(t *testing.T) {
+	r := BuildCapacityReport([]qradar.Rule{
+		{ID: ptr(1), Name: ptr("a, b"), Owner: ptr("admin"), Enabled: ptr(true), AverageCapacity: ptr(10), BaseCapacity: ptr(20)},
+	}, nil, nil, nil)
+
+	var buf bytes.Buffer
+	err := r.WriteCSV(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "rank,kind,id,name,owner,enabled,groups,average_capacity,base_capacity,measured_at,flags\n" +
+		`1,rule,1,"a, b",admin,true,,10,20,,expensive` + "\n"
+	if buf.String() != want {
+		t.Errorf("got\n%s\nwant\n%s", buf.String(), want)
+	}
+}
@@ -0,0 +1,498 @@
+// Package analysis provides offline analysis of QRadar's detection content:
+// dependencies between rules, building blocks and the objects they use.
+package analysis
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	qradar "github.com/ilyaglow/go-qradar"
+)
+
+// NodeKind represents a kind of the dependency graph node.
+type NodeKind string
+
+const (
+	// KindRule rule
+	KindRule NodeKind = "rule"
+
+	// KindBuildingBlock building block
+	KindBuildingBlock NodeKind = "building_block"
+
+	// KindReference reference set, map, map of sets or table
+	KindReference NodeKind = "reference"
+
+	// KindProperty custom property
+	KindProperty NodeKind = "property"
+
+	// KindLogSourceType log source type
+	KindLogSourceType NodeKind = "log_source_type"
+)
+
+// Node represents a rule, a building block or an object they depend on.
+type Node struct {
+	Kind NodeKind `json:"kind"`
+	ID   string   `json:"id"`
+	Name string   `json:"name,omitempty"`
+}
+
+// Key returns the unique key of the node.
+func (n Node) Key() string {
+	return string(n.Kind) + ":" + n.ID
+}
+
+// Edge represents the dependency of the From node on the To node.
+type Edge struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// Graph represents dependencies of the rules and building blocks.
+type Graph struct {
+	nodes   map[string]Node
+	deps    map[string]map[string]struct{}
+	reverse map[string]map[string]struct{}
+}
+
+// Inputs represents the content the dependency graph is built from.
+type Inputs struct {
+	Rules          []qradar.RuleWithData
+	BuildingBlocks []qradar.BuildingBlockWithData
+	References     []string
+	Properties     []qradar.RegexProperty
+	LogSourceTypes []qradar.LogSourceType
+}
+
+// Load fetches the rules, building blocks, reference collections, custom
+// properties and log source types and builds the dependency graph.
+func Load(ctx context.Context, client *qradar.Client) (*Graph, error) {
+	var in Inputs
+	var err error
+
+	in.Rules, err = client.RuleWithData.Get(ctx, "", "", 0, 0)
+	if err != nil {
+		return nil, err
+	}
+	in.BuildingBlocks, err = client.BuildingBlockWithData.Get(ctx, "", "", 0, 0)
+	if err != nil {
+		return nil, err
+	}
+	in.Properties, err = client.RegexProperty.Get(ctx, "id,identifier,name", "", 0, 0)
+	if err != nil {
+		return nil, err
+	}
+	in.LogSourceTypes, err = client.LogSourceType.Get(ctx, "id,name", "", 0, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	sets, err := client.ReferenceSet.Get(ctx, "name", "", 0, 0)
+	if err != nil {
+		return nil, err
+	}
+	for _, s := range sets {
+		in.References = appendName(in.References, s.Name)
+	}
+	maps, err := client.ReferenceMap.Get(ctx, "name", "", 0, 0)
+	if err != nil {
+		return nil, err
+	}
+	for _, m := range maps {
+		in.References = appendName(in.References, m.Name)
+	}
+	mapsOfSets, err := client.ReferenceMapOfSets.Get(ctx, "name", "", 0, 0)
+	if err != nil {
+		return nil, err
+	}
+	for _, m := range mapsOfSets {
+		in.References = appendName(in.References, m.Name)
+	}
+	tables, err := client.ReferenceTable.Get(ctx, "name", "", 0, 0)
+	if err != nil {
+		return nil, err
+	}
+	for _, t := range tables {
+		in.References = appendName(in.References, t.Name)
+	}
+
+	return Build(&in)
+}
+
+func appendName(names []string, name *string) []string {
+	if name == nil {
+		return names
+	}
+	return append(names, *name)
+}
+
+// resolver finds the nodes by the tokens of the rule test parameters.
+type resolver struct {
+	rules      map[string]string
+	references map[string]string
+	properties map[string]string
+	types      map[string]string
+}
+
+// Build builds the dependency graph from the content. The rule XML of every
+// rule and building block is scanned for the tokens of the test parameters
+// that match the known rules, reference collections, custom properties and
+// log source types.
+func Build(in *Inputs) (*Graph, error) {
+	g := &Graph{
+		nodes:   make(map[string]Node),
+		deps:    make(map[string]map[string]struct{}),
+		reverse: make(map[string]map[string]struct{}),
+	}
+	r := resolver{
+		rules:      make(map[string]string),
+		references: make(map[string]string),
+		properties: make(map[string]string),
+		types:      make(map[string]string),
+	}
+
+	type definition struct {
+		key string
+		xml *string
+	}
+	var defs []definition
+
+	for _, rule := range in.Rules {
+		kind := KindRule
+		if rule.IsBuildingBlock != nil && *rule.IsBuildingBlock {
+			kind = KindBuildingBlock
+		}
+		n := ruleNode(kind, rule.ID, rule.Identifier, rule.Name)
+		g.add(n)
+		r.addRule(n.Key(), rule.ID, rule.Identifier, rule.Name)
+		defs = append(defs, definition{n.Key(), rule.RuleXML})
+	}
+	for _, bb := range in.BuildingBlocks {
+		n := ruleNode(KindBuildingBlock, bb.ID, bb.Identifier, bb.Name)
+		if _, ok := g.nodes[n.Key()]; ok {
+			continue
+		}
+		g.add(n)
+		r.addRule(n.Key(), bb.ID, bb.Identifier, bb.Name)
+		defs = append(defs, definition{n.Key(), bb.RuleXML})
+	}
+	for _, name := range in.References {
+		n := Node{Kind: KindReference, ID: name, Name: name}
+		g.add(n)
+		r.references[name] = n.Key()
+	}
+	for _, p := range in.Properties {
+		if p.Identifier == nil {
+			continue
+		}
+		n := Node{Kind: KindProperty, ID: *p.Identifier, Name: stringValue(p.Name)}
+		g.add(n)
+		r.properties[*p.Identifier] = n.Key()
+	}
+	for _, t := range in.LogSourceTypes {
+		if t.ID == nil {
+			continue
+		}
+		n := Node{Kind: KindLogSourceType, ID: strconv.Itoa(*t.ID), Name: stringValue(t.Name)}
+		g.add(n)
+		r.types[n.ID] = n.Key()
+	}
+
+	for _, d := range defs {
+		if d.xml == nil || *d.xml == "" {
+			continue
+		}
+		def, err := qradar.ParseRuleXML(*d.xml)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", d.key, err)
+		}
+		for _, to := range r.resolve(def) {
+			if to != d.key {
+				g.link(d.key, to)
+			}
+		}
+	}
+
+	return g, nil
+}
+
+func ruleNode(kind NodeKind, id *int, identifier, name *string) Node {
+	n := Node{Kind: kind, Name: stringValue(name)}
+	if identifier != nil && *identifier != "" {
+		n.ID = *identifier
+	} else if id != nil {
+		n.ID = strconv.Itoa(*id)
+	}
+	return n
+}
+
+func (r *resolver) addRule(key string, id *int, identifier, name *string) {
+	if id != nil {
+		r.rules[strconv.Itoa(*id)] = key
+	}
+	if identifier != nil && *identifier != "" {
+		r.rules[*identifier] = key
+	}
+	if name != nil && *name != "" {
+		r.rules[*name] = key
+	}
+}
+
+// resolve returns keys of the nodes the rule definition depends on.
+func (r *resolver) resolve(def *qradar.RuleDefinition) []string {
+	found := make(map[string]struct{})
+
+	for i := range def.Tests {
+		t := &def.Tests[i]
+		name := strings.ToLower(t.Name)
+		for _, p := range t.Parameters {
+			for _, token := range tokens(p.UserSelection) {
+				if key, ok := r.properties[token]; ok {
+					found[key] = struct{}{}
+				}
+				if strings.Contains(name, "reference") {
+					if key, ok := r.references[token]; ok {
+						found[key] = struct{}{}
+					}
+				}
+				if strings.Contains(name, "devicetype") || strings.Contains(name, "logsourcetype") {
+					if key, ok := r.types[token]; ok {
+						found[key] = struct{}{}
+					}
+				}
+			}
+		}
+		// only the parameters that refer to the rules are followed, so the
+		// counts don't match the rules by ID
+		for _, p := range t.RuleReferences() {
+			for _, token := range tokens(p.UserSelection) {
+				if key, ok := r.rules[token]; ok {
+					found[key] = struct{}{}
+				}
+			}
+		}
+	}
+
+	if def.Responses != nil {
+		for _, item := range def.Responses.Items {
+			for _, a := range item.Attrs {
+				if !strings.Contains(strings.ToLower(a.Name.Local), "reference") {
+					continue
+				}
+				if key, ok := r.references[a.Value]; ok {
+					found[key] = struct{}{}
+				}
+			}
+		}
+	}
+
+	keys := make([]string, 0, len(found))
+	for k := range found {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// tokens splits the user selection of the test parameter into values.
+func tokens(s string) []string {
+	var result []string
+	for _, t := range strings.FieldsFunc(s, func(r rune) bool { return r == ',' || r == '\n' || r == '\t' }) {
+		t = strings.TrimSpace(t)
+		if t != "" {
+			result = append(result, t)
+		}
+	}
+	if s = strings.TrimSpace(s); s != "" && len(result) > 1 {
+		// names of the reference collections may contain commas
+		result = append(result, s)
+	}
+	return result
+}
+
+func (g *Graph) add(n Node) {
+	g.nodes[n.Key()] = n
+}
+
+func (g *Graph) link(from, to string) {
+	if g.deps[from] == nil {
+		g.deps[from] = make(map[string]struct{})
+	}
+	g.deps[from][to] = struct{}{}
+	if g.reverse[to] == nil {
+		g.reverse[to] = make(map[string]struct{})
+	}
+	g.reverse[to][from] = struct{}{}
+}
+
+// Node returns the node by key.
+func (g *Graph) Node(key string) (Node, bool) {
+	n, ok := g.nodes[key]
+	return n, ok
+}
+
+// Nodes returns all nodes sorted by key.
+func (g *Graph) Nodes() []Node {
+	return g.sorted(g.nodes)
+}
+
+// Edges returns all edges sorted by the keys.
+func (g *Graph) Edges() []Edge {
+	var edges []Edge
+	for _, from := range sortedKeys(g.deps) {
+		for _, to := range sortedKeys(g.deps[from]) {
+			edges = append(edges, Edge{from, to})
+		}
+	}
+	return edges
+}
+
+// Dependencies returns the nodes the node by key directly depends on.
+func (g *Graph) Dependencies(key string) []Node {
+	return g.lookup(g.deps[key])
+}
+
+// Dependents returns the nodes directly depending on the node by key.
+func (g *Graph) Dependents(key string) []Node {
+	return g.lookup(g.reverse[key])
+}
+
+// Impacted returns all nodes directly or transitively depending on the node
+// by key, i.e. the rules that break if the node is disabled or deleted.
+func (g *Graph) Impacted(key string) []Node {
+	seen := make(map[string]struct{})
+	queue := []string{key}
+	for len(queue) > 0 {
+		k := queue[0]
+		queue = queue[1:]
+		for dep := range g.reverse[k] {
+			if _, ok := seen[dep]; ok || dep == key {
+				continue
+			}
+			seen[dep] = struct{}{}
+			queue = append(queue, dep)
+		}
+	}
+	return g.lookup(seen)
+}
+
+// Cycles returns the cycles of the dependencies, each as a list of nodes
+// where the last node depends on the first one.
+func (g *Graph) Cycles() [][]Node {
+	const (
+		white = iota
+		grey
+		black
+	)
+	color := make(map[string]int)
+	var stack []string
+	var cycles [][]Node
+
+	var visit func(k string)
+	visit = func(k string) {
+		color[k] = grey
+		stack = append(stack, k)
+		for _, dep := range sortedKeys(g.deps[k]) {
+			switch color[dep] {
+			case white:
+				visit(dep)
+			case grey:
+				var cycle []Node
+				for i := len(stack) - 1; i >= 0; i-- {
+					cycle = append([]Node{g.nodes[stack[i]]}, cycle...)
+					if stack[i] == dep {
+						break
+					}
+				}
+				cycles = append(cycles, cycle)
+			}
+		}
+		stack = stack[:len(stack)-1]
+		color[k] = black
+	}
+
+	for _, k := range sortedKeys(g.nodes) {
+		if color[k] == white {
+			visit(k)
+		}
+	}
+	return cycles
+}
+
+// MarshalJSON satisfies the json.Marshaler interface.
+func (g *Graph) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Nodes []Node `json:"nodes"`
+		Edges []Edge `json:"edges"`
+	}{g.Nodes(), g.Edges()})
+}
+
+// WriteDOT writes the graph in the Graphviz DOT language.
+func (g *Graph) WriteDOT(w io.Writer) error {
+	shapes := map[NodeKind]string{
+		KindRule:          "box",
+		KindBuildingBlock: "component",
+		KindReference:     "cylinder",
+		KindProperty:      "note",
+		KindLogSourceType: "ellipse",
+	}
+
+	_, err := fmt.Fprintln(w, "digraph dependencies {")
+	if err != nil {
+		return err
+	}
+	for _, n := range g.Nodes() {
+		label := n.Name
+		if label == "" {
+			label = n.ID
+		}
+		_, err = fmt.Fprintf(w, "  %q [label=%q, shape=%s];\n", n.Key(), label, shapes[n.Kind])
+		if err != nil {
+			return err
+		}
+	}
+	for _, e := range g.Edges() {
+		_, err = fmt.Fprintf(w, "  %q -> %q;\n", e.From, e.To)
+		if err != nil {
+			return err
+		}
+	}
+	_, err = fmt.Fprintln(w, "}")
+	return err
+}
+
+func (g *Graph) lookup(keys map[string]struct{}) []Node {
+	nodes := make([]Node, 0, len(keys))
+	for _, k := range sortedKeys(keys) {
+		nodes = append(nodes, g.nodes[k])
+	}
+	return nodes
+}
+
+func (g *Graph) sorted(m map[string]Node) []Node {
+	nodes := make([]Node, 0, len(m))
+	for _, k := range sortedKeys(m) {
+		nodes = append(nodes, m[k])
+	}
+	return nodes
+}
+
+func stringValue(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
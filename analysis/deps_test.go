@@ -0,0 +1,146 @@
+package analysis
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	qradar "github.com/ilyaglow/go-qradar"
+)
+
+func ptr[T any](v T) *T {
+	return &v
+}
+
+// ruleTest returns a test of the name with a parameter per ID and user
+// selection pair.
+func ruleTest(name string, params ...string) string {
+	var b strings.Builder
+	b.WriteString(`<test name="com.q1labs.semsources.cre.tests.` + name + `"><text>t</text>`)
+	for i := 0; i+1 < len(params); i += 2 {
+		b.WriteString(`<parameter id="` + params[i] + `"><userSelection>` + params[i+1] + `</userSelection></parameter>`)
+	}
+	b.WriteString(`</test>`)
+	return b.String()
+}
+
+// ruleXML returns a rule definition with the tests, and the referenceSet
+// response if set isn't empty.
+func ruleXML(set string, tests ...string) *string {
+	var b strings.Builder
+	b.WriteString(`<rule><name>r</name><testDefinitions>`)
+	for _, t := range tests {
+		b.WriteString(t)
+	}
+	b.WriteString(`</testDefinitions>`)
+	if set != "" {
+		b.WriteString(`<responses><referenceSet referenceSetName="` + set + `"/></responses>`)
+	}
+	b.WriteString(`</rule>`)
+	s := b.String()
+	return &s
+}
+
+func testGraph(t *testing.T) *Graph {
+	t.Helper()
+	g, err := Build(&Inputs{
+		Rules: []qradar.RuleWithData{
+			{
+				Rule: qradar.Rule{ID: ptr(1), Identifier: ptr("A"), Name: ptr("Rule A")},
+				RuleXML: ruleXML("",
+					ruleTest("RuleMatch_Test", "1", "0", "2", "B"),
+					ruleTest("ReferenceSetTest", "1", "bad ips"),
+					ruleTest("EventPropertyTest", "1", "prop-1")),
+			},
+			{
+				Rule: qradar.Rule{ID: ptr(3), Identifier: ptr("C"), Name: ptr("Rule C")},
+				RuleXML: ruleXML("seen hosts",
+					ruleTest("DeviceTypeID_Test", "1", "12, 13"),
+					// the count equals the ID of rule A but isn't a reference
+					ruleTest("MultiRuleCountMatchTest", "1", "1")),
+			},
+		},
+		BuildingBlocks: []qradar.BuildingBlockWithData{
+			{
+				BuildingBlock: qradar.BuildingBlock{ID: ptr(2), Identifier: ptr("B"), Name: ptr("BB B")},
+				RuleXML:       ruleXML("", ruleTest("RuleMatch_Test", "1", "1", "2", "Rule A")),
+			},
+		},
+		References:     []string{"bad ips", "seen hosts"},
+		Properties:     []qradar.RegexProperty{{Identifier: ptr("prop-1"), Name: ptr("Prop")}},
+		LogSourceTypes: []qradar.LogSourceType{{ID: ptr(12), Name: ptr("Cisco PIX")}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return g
+}
+
+func keys(nodes []Node) []string {
+	result := []string{}
+	for _, n := range nodes {
+		result = append(result, n.Key())
+	}
+	return result
+}
+
+func TestGraph(t *testing.T) {
+	g := testGraph(t)
+
+	tests := []struct {
+		name string
+		got  []Node
+		want []string
+	}{
+		{"dependencies of A", g.Dependencies("rule:A"), []string{"building_block:B", "property:prop-1", "reference:bad ips"}},
+		{"dependencies of C", g.Dependencies("rule:C"), []string{"log_source_type:12", "reference:seen hosts"}},
+		{"dependents of bad ips", g.Dependents("reference:bad ips"), []string{"rule:A"}},
+		{"impacted by prop-1", g.Impacted("property:prop-1"), []string{"building_block:B", "rule:A"}},
+		{"impacted by C", g.Impacted("rule:C"), []string{}},
+	}
+	for _, tt := range tests {
+		if got := keys(tt.got); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+
+	cycles := g.Cycles()
+	if len(cycles) != 1 || !reflect.DeepEqual(keys(cycles[0]), []string{"building_block:B", "rule:A"}) {
+		t.Errorf("unexpected cycles %v", cycles)
+	}
+}
+
+func TestGraphWriteDOT(t *testing.T) {
+	var buf bytes.Buffer
+	err := testGraph(t).WriteDOT(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		`"rule:A" [label="Rule A", shape=box];`,
+		`"reference:bad ips" [label="bad ips", shape=cylinder];`,
+		`"rule:A" -> "building_block:B";`,
+	} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("DOT output has no %s:\n%s", want, buf.String())
+		}
+	}
+}
+
+func TestTokens(t *testing.T) {
+	tests := []struct {
+		s    string
+		want []string
+	}{
+		{"", nil},
+		{" 6 ", []string{"6"}},
+		{"6,7", []string{"6", "7", "6,7"}},
+		{"a\n b\t", []string{"a", "b", "a\n b"}},
+	}
+	for _, tt := range tests {
+		if got := tokens(tt.s); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("tokens(%q) = %q, want %q", tt.s, got, tt.want)
+		}
+	}
+}
@@ -43,8 +43,10 @@ type Search struct {
 type Event map[string]interface{}
 
 // SearchResult represents search result.
+// Results of the flows queries are returned under the flows key.
 type SearchResult struct {
 	Events []Event `json:"events,omitempty"`
+	Flows  []Event `json:"flows,omitempty"`
 }
 
 // SearchMetadata represents search metadata.
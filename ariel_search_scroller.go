@@ -17,6 +17,7 @@ type SearchResultsScroller struct {
 	startIdx, currIdx int
 	window            int
 	events            []Event
+	err               error
 }
 
 // NewSearchResultsScroller initializes struct to scroll the records.
@@ -55,6 +56,7 @@ func (s *SearchResultsScroller) Next(ctx context.Context) bool {
 		s.startIdx += s.window
 		err := s.getEvents(ctx)
 		if err != nil {
+			s.err = err
 			return false
 		}
 	}
@@ -77,6 +79,9 @@ func (s *SearchResultsScroller) getEvents(ctx context.Context) error {
 	}
 
 	s.events = r.Events
+	if len(r.Flows) > 0 {
+		s.events = r.Flows
+	}
 
 	return nil
 }
@@ -94,6 +99,11 @@ func (s *SearchResultsScroller) Length() int {
 	return s.count
 }
 
+// Err returns the error that stopped the scrolling.
+func (s *SearchResultsScroller) Err() error {
+	return s.err
+}
+
 // ScrollByQuery events in the QRadar API.
 // Recommended way to retrieve large amount of events.
 func (a *ArielService) ScrollByQuery(ctx context.Context, sqlQuery string) (*SearchResultsScroller, *SearchMetadata, error) {
@@ -0,0 +1,157 @@
+// Package attack maps QRadar's rules to MITRE ATT&CK tactics and techniques,
+// computes the coverage of the techniques by the enabled rules and exports it
+// as an ATT&CK Navigator layer.
+//
+// Mappings are read and written as JSON only. YAML isn't supported to keep
+// the module free of dependencies, YAML mappings have to be converted to
+// JSON first.
+package attack
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+
+	qradar "github.com/ilyaglow/go-qradar"
+)
+
+var (
+	techniqueID = regexp.MustCompile(`(?i)\bT\d{4}(?:\.\d{3})?\b`)
+	tacticID    = regexp.MustCompile(`(?i)\bTA\d{4}\b`)
+)
+
+// RuleMapping represents the tactics and techniques of a rule.
+type RuleMapping struct {
+	Tactics    []string `json:"tactics,omitempty"`
+	Techniques []string `json:"techniques"`
+}
+
+// Mapping represents the ATT&CK mapping of the rules by their identifiers.
+// The numeric IDs of the rules are accepted as keys as well.
+//
+// The JSON form is:
+//
+//	{"rules": {"<identifier>": {"tactics": ["TA0002"], "techniques": ["T1059.001"]}}}
+type Mapping struct {
+	Rules map[string]RuleMapping `json:"rules"`
+}
+
+// NewMapping returns an empty mapping.
+func NewMapping() *Mapping {
+	return &Mapping{Rules: make(map[string]RuleMapping)}
+}
+
+// Read reads the mapping in the JSON form, YAML isn't supported.
+func Read(r io.Reader) (*Mapping, error) {
+	m := NewMapping()
+	err := json.NewDecoder(r).Decode(m)
+	if err != nil {
+		return nil, err
+	}
+	if m.Rules == nil {
+		m.Rules = make(map[string]RuleMapping)
+	}
+	for k, rm := range m.Rules {
+		m.Rules[k] = rm.normalize()
+	}
+	return m, nil
+}
+
+// ReadFile reads the mapping in the JSON form from the file.
+func ReadFile(path string) (*Mapping, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return Read(f)
+}
+
+// Write writes the mapping in the JSON form.
+func (m *Mapping) Write(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(m)
+}
+
+// FromRules extracts the technique and tactic IDs, e.g. T1059.001 and TA0002,
+// case-insensitively from the names and the notes of the rule XML of the
+// rules. Rules without IDs are not mapped.
+func FromRules(rules []qradar.RuleWithData) (*Mapping, error) {
+	m := NewMapping()
+	for i := range rules {
+		r := &rules[i]
+		if r.Identifier == nil {
+			continue
+		}
+		text := ""
+		if r.Name != nil {
+			text = *r.Name
+		}
+		if r.RuleXML != nil {
+			def, err := r.Definition()
+			if err != nil {
+				return nil, err
+			}
+			text += "\n" + def.Name + "\n" + def.Notes
+		}
+		rm := RuleMapping{
+			Tactics:    tacticID.FindAllString(text, -1),
+			Techniques: techniqueID.FindAllString(text, -1),
+		}.normalize()
+		if len(rm.Techniques) > 0 || len(rm.Tactics) > 0 {
+			m.Rules[*r.Identifier] = rm
+		}
+	}
+	return m, nil
+}
+
+// Merge adds the tactics and techniques of the other mapping.
+func (m *Mapping) Merge(other *Mapping) {
+	for k, o := range other.Rules {
+		rm := m.Rules[k]
+		rm.Tactics = append(rm.Tactics, o.Tactics...)
+		rm.Techniques = append(rm.Techniques, o.Techniques...)
+		m.Rules[k] = rm.normalize()
+	}
+}
+
+// Lookup returns the mapping of the rule by identifier or ID.
+func (m *Mapping) Lookup(identifier, id string) (RuleMapping, bool) {
+	if rm, ok := m.Rules[identifier]; ok && identifier != "" {
+		return rm, true
+	}
+	if rm, ok := m.Rules[id]; ok && id != "" {
+		return rm, true
+	}
+	return RuleMapping{}, false
+}
+
+// normalize upper-cases, deduplicates and sorts the IDs.
+func (rm RuleMapping) normalize() RuleMapping {
+	return RuleMapping{
+		Tactics:    normalizeIDs(rm.Tactics),
+		Techniques: normalizeIDs(rm.Techniques),
+	}
+}
+
+func normalizeIDs(ids []string) []string {
+	if len(ids) == 0 {
+		return nil
+	}
+	seen := make(map[string]struct{}, len(ids))
+	var result []string
+	for _, id := range ids {
+		id = strings.ToUpper(strings.TrimSpace(id))
+		if _, ok := seen[id]; ok || id == "" {
+			continue
+		}
+		seen[id] = struct{}{}
+		result = append(result, id)
+	}
+	sort.Strings(result)
+	return result
+}
@@ -0,0 +1,124 @@
+package attack
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	qradar "github.com/ilyaglow/go-qradar"
+)
+
+func ptr[T any](v T) *T {
+	return &v
+}
+
+func TestRead(t *testing.T) {
+	m, err := Read(strings.NewReader(`{"rules": {"uuid-a": {"tactics": ["ta0002"], "techniques": [" t1059.001", "T1059.001", "T1078"]}}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := RuleMapping{Tactics: []string{"TA0002"}, Techniques: []string{"T1059.001", "T1078"}}
+	if !reflect.DeepEqual(m.Rules["uuid-a"], want) {
+		t.Errorf("got %+v, want %+v", m.Rules["uuid-a"], want)
+	}
+}
+
+func TestFromRules(t *testing.T) {
+	rules := []qradar.RuleWithData{
+		{
+			Rule:    qradar.Rule{Identifier: ptr("uuid-a"), Name: ptr("T1110 Brute force")},
+			RuleXML: ptr(`<rule><name>T1110 Brute force</name><notes>Tactic ta0006, see t1110.001</notes></rule>`),
+		},
+		{Rule: qradar.Rule{Identifier: ptr("uuid-b"), Name: ptr("No techniques")}},
+		{Rule: qradar.Rule{Name: ptr("T1078 without identifier")}},
+	}
+	m, err := FromRules(rules)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]RuleMapping{
+		"uuid-a": {Tactics: []string{"TA0006"}, Techniques: []string{"T1110", "T1110.001"}},
+	}
+	if !reflect.DeepEqual(m.Rules, want) {
+		t.Errorf("got %+v, want %+v", m.Rules, want)
+	}
+}
+
+func TestMappingLookup(t *testing.T) {
+	m := &Mapping{Rules: map[string]RuleMapping{
+		"uuid-a": {Techniques: []string{"T1"}},
+		"7":      {Techniques: []string{"T2"}},
+		"":       {Techniques: []string{"T3"}},
+	}}
+	tests := []struct {
+		identifier, id string
+		want           string
+		ok             bool
+	}{
+		{"uuid-a", "1", "T1", true},
+		{"uuid-x", "7", "T2", true},
+		{"", "", "", false},
+		{"uuid-x", "8", "", false},
+	}
+	for _, tt := range tests {
+		rm, ok := m.Lookup(tt.identifier, tt.id)
+		got := ""
+		if len(rm.Techniques) > 0 {
+			got = rm.Techniques[0]
+		}
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("Lookup(%q, %q) = %q, %v, want %q, %v", tt.identifier, tt.id, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestComputeCoverage(t *testing.T) {
+	m := &Mapping{Rules: map[string]RuleMapping{
+		"uuid-a": {Tactics: []string{"TA0001"}, Techniques: []string{"T1078", "T1110"}},
+		"2":      {Tactics: []string{"TA0006"}, Techniques: []string{"T1110"}},
+		"uuid-z": {Techniques: []string{"T1059"}},
+		"":       {Techniques: []string{"T1000"}},
+	}}
+	rules := []qradar.Rule{
+		{ID: ptr(1), Identifier: ptr("uuid-a"), Name: ptr("A"), Enabled: ptr(true)},
+		{ID: ptr(2), Name: ptr("B"), Enabled: ptr(false)},
+		{ID: ptr(3), Identifier: ptr("uuid-c"), Name: ptr("C"), Enabled: ptr(true)},
+	}
+	c := ComputeCoverage(m, rules)
+
+	if c.Unmapped != 1 {
+		t.Errorf("unmapped %d, want 1", c.Unmapped)
+	}
+	// the rule without an identifier doesn't consume the empty key
+	if want := []string{"", "uuid-z"}; !reflect.DeepEqual(c.Missing, want) {
+		t.Errorf("missing %q, want %q", c.Missing, want)
+	}
+
+	tests := []struct {
+		technique string
+		tactics   []string
+		rules     []int
+		enabled   int
+	}{
+		{"T1078", []string{"TA0001"}, []int{1}, 1},
+		{"T1110", []string{"TA0001", "TA0006"}, []int{1, 2}, 1},
+	}
+	if len(c.Techniques) != len(tests) {
+		t.Fatalf("got %d techniques, want %d", len(c.Techniques), len(tests))
+	}
+	for i, tt := range tests {
+		tc := &c.Techniques[i]
+		var ids []int
+		for _, r := range tc.Rules {
+			ids = append(ids, r.ID)
+		}
+		if tc.Technique != tt.technique || !reflect.DeepEqual(tc.Tactics, tt.tactics) || !reflect.DeepEqual(ids, tt.rules) || tc.Enabled() != tt.enabled {
+			t.Errorf("technique %d = %+v, want %+v", i, tc, tt)
+		}
+	}
+
+	l := c.Layer("QRadar", "")
+	if l.Gradient.MaxValue != 1 || len(l.Techniques) != 2 || l.Techniques[1].Comment != "Disabled: B" {
+		t.Errorf("unexpected layer %+v", l)
+	}
+}
@@ -0,0 +1,223 @@
+package attack
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	qradar "github.com/ilyaglow/go-qradar"
+)
+
+// Versions of the ATT&CK Navigator layer.
+const (
+	LayerVersion     = "4.5"
+	NavigatorVersion = "4.9.1"
+	AttackVersion    = "14"
+	LayerDomain      = "enterprise-attack"
+)
+
+// MappedRule represents a rule covering a technique.
+type MappedRule struct {
+	ID         int    `json:"id"`
+	Identifier string `json:"identifier,omitempty"`
+	Name       string `json:"name"`
+	Enabled    bool   `json:"enabled"`
+}
+
+// TechniqueCoverage represents the rules covering a technique.
+type TechniqueCoverage struct {
+	Technique string       `json:"technique"`
+	Tactics   []string     `json:"tactics,omitempty"`
+	Rules     []MappedRule `json:"rules"`
+}
+
+// Enabled returns the number of the enabled rules.
+func (t *TechniqueCoverage) Enabled() int {
+	n := 0
+	for _, r := range t.Rules {
+		if r.Enabled {
+			n++
+		}
+	}
+	return n
+}
+
+// Covered returns true if any of the rules is enabled.
+func (t *TechniqueCoverage) Covered() bool {
+	return t.Enabled() > 0
+}
+
+// Coverage represents the coverage of the techniques by the rules.
+type Coverage struct {
+	// Techniques are sorted by ID.
+	Techniques []TechniqueCoverage `json:"techniques"`
+	// Unmapped is the number of the rules without a mapping.
+	Unmapped int `json:"unmapped"`
+	// Missing are the keys of the mapping without a rule.
+	Missing []string `json:"missing,omitempty"`
+}
+
+// LoadCoverage fetches the rules and computes their coverage of the
+// techniques of the mapping.
+func LoadCoverage(ctx context.Context, client *qradar.Client, m *Mapping) (*Coverage, error) {
+	rules, err := client.Rule.Get(ctx, "id,identifier,name,enabled", "", 0, 0)
+	if err != nil {
+		return nil, err
+	}
+	return ComputeCoverage(m, rules), nil
+}
+
+// ComputeCoverage computes the coverage of the techniques of the mapping by
+// the rules.
+func ComputeCoverage(m *Mapping, rules []qradar.Rule) *Coverage {
+	c := &Coverage{}
+	byTechnique := make(map[string]*TechniqueCoverage)
+	used := make(map[string]bool)
+
+	for _, r := range rules {
+		if r.ID == nil {
+			continue
+		}
+		mr := MappedRule{ID: *r.ID, Identifier: stringValue(r.Identifier), Name: stringValue(r.Name), Enabled: r.Enabled != nil && *r.Enabled}
+		id := strconv.Itoa(mr.ID)
+		rm, ok := m.Lookup(mr.Identifier, id)
+		if !ok || len(rm.Techniques) == 0 {
+			c.Unmapped++
+			continue
+		}
+		if mr.Identifier != "" {
+			used[mr.Identifier] = true
+		}
+		used[id] = true
+
+		for _, t := range rm.Techniques {
+			tc, ok := byTechnique[t]
+			if !ok {
+				tc = &TechniqueCoverage{Technique: t}
+				byTechnique[t] = tc
+			}
+			tc.Tactics = normalizeIDs(append(tc.Tactics, rm.Tactics...))
+			tc.Rules = append(tc.Rules, mr)
+		}
+	}
+
+	for k := range m.Rules {
+		if !used[k] {
+			c.Missing = append(c.Missing, k)
+		}
+	}
+	sort.Strings(c.Missing)
+
+	for _, tc := range byTechnique {
+		sort.Slice(tc.Rules, func(i, j int) bool { return tc.Rules[i].ID < tc.Rules[j].ID })
+		c.Techniques = append(c.Techniques, *tc)
+	}
+	sort.Slice(c.Techniques, func(i, j int) bool { return c.Techniques[i].Technique < c.Techniques[j].Technique })
+
+	return c
+}
+
+// WriteJSON writes the coverage as indented JSON.
+func (c *Coverage) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(c)
+}
+
+// Layer represents the ATT&CK Navigator layer.
+type Layer struct {
+	Name        string           `json:"name"`
+	Versions    LayerVersions    `json:"versions"`
+	Domain      string           `json:"domain"`
+	Description string           `json:"description,omitempty"`
+	Techniques  []LayerTechnique `json:"techniques"`
+	Gradient    LayerGradient    `json:"gradient"`
+}
+
+// LayerVersions represents the versions of the layer format.
+type LayerVersions struct {
+	Attack    string `json:"attack"`
+	Navigator string `json:"navigator"`
+	Layer     string `json:"layer"`
+}
+
+// LayerTechnique represents the technique of the layer.
+type LayerTechnique struct {
+	TechniqueID string          `json:"techniqueID"`
+	Score       int             `json:"score"`
+	Comment     string          `json:"comment,omitempty"`
+	Enabled     bool            `json:"enabled"`
+	Metadata    []LayerMetadata `json:"metadata,omitempty"`
+}
+
+// LayerMetadata represents the metadata of the technique of the layer.
+type LayerMetadata struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// LayerGradient represents the score colors of the layer.
+type LayerGradient struct {
+	Colors   []string `json:"colors"`
+	MinValue int      `json:"minValue"`
+	MaxValue int      `json:"maxValue"`
+}
+
+// Layer returns the ATT&CK Navigator layer of the coverage. The score of the
+// technique is the number of the enabled rules, techniques covered by the
+// disabled rules only have the score of zero.
+func (c *Coverage) Layer(name, description string) *Layer {
+	l := &Layer{
+		Name:        name,
+		Versions:    LayerVersions{Attack: AttackVersion, Navigator: NavigatorVersion, Layer: LayerVersion},
+		Domain:      LayerDomain,
+		Description: description,
+		Techniques:  []LayerTechnique{},
+		Gradient:    LayerGradient{Colors: []string{"#ff6666", "#ffe766", "#8ec843"}},
+	}
+
+	for i := range c.Techniques {
+		tc := &c.Techniques[i]
+		lt := LayerTechnique{
+			TechniqueID: tc.Technique,
+			Score:       tc.Enabled(),
+			Enabled:     true,
+		}
+		var disabled []string
+		for _, r := range tc.Rules {
+			state := "enabled"
+			if !r.Enabled {
+				state = "disabled"
+				disabled = append(disabled, r.Name)
+			}
+			lt.Metadata = append(lt.Metadata, LayerMetadata{Name: "rule", Value: fmt.Sprintf("%s (%s)", r.Name, state)})
+		}
+		if len(disabled) > 0 {
+			lt.Comment = "Disabled: " + strings.Join(disabled, "; ")
+		}
+		if lt.Score > l.Gradient.MaxValue {
+			l.Gradient.MaxValue = lt.Score
+		}
+		l.Techniques = append(l.Techniques, lt)
+	}
+
+	return l
+}
+
+// WriteJSON writes the layer as indented JSON.
+func (l *Layer) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(l)
+}
+
+func stringValue(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
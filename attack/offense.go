@@ -0,0 +1,74 @@
+package attack
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	qradar "github.com/ilyaglow/go-qradar"
+)
+
+// OffenseRule represents a contributing rule of the offense with its tactics
+// and techniques.
+type OffenseRule struct {
+	ID         int    `json:"id"`
+	Identifier string `json:"identifier,omitempty"`
+	Name       string `json:"name,omitempty"`
+	RuleMapping
+}
+
+// OffenseMapping represents the tactics and techniques of the offense.
+type OffenseMapping struct {
+	OffenseID int           `json:"offense_id"`
+	Rules     []OffenseRule `json:"rules"`
+	// Tactics and Techniques are the union of the ones of the rules.
+	Tactics    []string `json:"tactics,omitempty"`
+	Techniques []string `json:"techniques,omitempty"`
+}
+
+// AnnotateOffense maps the contributing rules of the offense to the tactics
+// and techniques. Rules are fetched to resolve their identifiers.
+func AnnotateOffense(ctx context.Context, client *qradar.Client, m *Mapping, o *qradar.Offense) (*OffenseMapping, error) {
+	om := &OffenseMapping{}
+	if o.ID != nil {
+		om.OffenseID = *o.ID
+	}
+
+	var ids []string
+	for _, r := range o.Rules {
+		if r.ID != nil {
+			ids = append(ids, strconv.Itoa(*r.ID))
+		}
+	}
+	if len(ids) == 0 {
+		return om, nil
+	}
+
+	rules, err := client.Rule.Get(ctx, "id,identifier,name", fmt.Sprintf("id in (%s)", strings.Join(ids, ",")), 0, 0)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[string]qradar.Rule, len(rules))
+	for _, r := range rules {
+		if r.ID != nil {
+			byID[strconv.Itoa(*r.ID)] = r
+		}
+	}
+
+	for _, id := range ids {
+		r := byID[id]
+		or := OffenseRule{Identifier: stringValue(r.Identifier), Name: stringValue(r.Name)}
+		or.ID, _ = strconv.Atoi(id)
+		if rm, ok := m.Lookup(or.Identifier, id); ok {
+			or.RuleMapping = rm
+			om.Tactics = append(om.Tactics, rm.Tactics...)
+			om.Techniques = append(om.Techniques, rm.Techniques...)
+		}
+		om.Rules = append(om.Rules, or)
+	}
+	om.Tactics = normalizeIDs(om.Tactics)
+	om.Techniques = normalizeIDs(om.Techniques)
+
+	return om, nil
+}
@@ -0,0 +1,493 @@
+package qradar
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// DSMOnboardingSpec represents the specification of a custom DSM: the Log
+// Source Type, its Log Source Extension and the mapping of its events to
+// QIDs.
+//
+// The JSON form is:
+//
+//	{
+//	  "log_source_type": {"name": "Acme VPN", "protocol_ids": [0]},
+//	  "extension": {"name": "AcmeVPNCustom_ext", "file": "acme_vpn.xml"},
+//	  "events": [
+//	    {"event_id": "login", "category": "auth",
+//	     "qid": {"name": "Acme VPN Login", "severity": 3, "low_level_category_id": 3014}}
+//	  ]
+//	}
+type DSMOnboardingSpec struct {
+	LogSourceType DSMLogSourceTypeSpec `json:"log_source_type"`
+	Extension     *DSMExtensionSpec    `json:"extension,omitempty"`
+	Events        []DSMEventSpec       `json:"events,omitempty"`
+}
+
+// DSMLogSourceTypeSpec represents the custom Log Source Type of the DSM.
+type DSMLogSourceTypeSpec struct {
+	Name    string `json:"name"`
+	Version string `json:"version,omitempty"`
+	// ProtocolIDs are the IDs of the supported Protocol Types, the first one
+	// is the default if DefaultProtocolID is not set.
+	ProtocolIDs       []int `json:"protocol_ids"`
+	DefaultProtocolID *int  `json:"default_protocol_id,omitempty"`
+}
+
+// DSMExtensionSpec represents the Log Source Extension of the DSM. The
+// document is either inline in XML or in File relative to the spec file.
+type DSMExtensionSpec struct {
+	Name         string `json:"name"`
+	Description  string `json:"description,omitempty"`
+	File         string `json:"file,omitempty"`
+	XML          string `json:"xml,omitempty"`
+	UseCondition *int   `json:"use_condition,omitempty"`
+}
+
+// DSMEventSpec represents the mapping of an event of the Log Source Type to
+// the QID.
+type DSMEventSpec struct {
+	EventID  string     `json:"event_id"`
+	Category string     `json:"category"`
+	QID      DSMQIDSpec `json:"qid"`
+}
+
+// DSMQIDSpec represents the QID of the mapped event. The QID is looked up by
+// UUID if set and by name within the Log Source Type if the UUID is not set
+// or not found.
+type DSMQIDSpec struct {
+	UUID               string `json:"uuid,omitempty"`
+	Name               string `json:"name"`
+	Description        string `json:"description,omitempty"`
+	Severity           int    `json:"severity"`
+	LowLevelCategoryID int    `json:"low_level_category_id"`
+}
+
+// ReadDSMOnboardingSpec reads the spec in the JSON form from the file. The
+// extension document in a file is read in the XML of the spec.
+func ReadDSMOnboardingSpec(path string) (*DSMOnboardingSpec, error) {
+	bs, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var spec DSMOnboardingSpec
+	err = json.Unmarshal(bs, &spec)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if spec.Extension != nil && spec.Extension.File != "" {
+		file := spec.Extension.File
+		if !filepath.IsAbs(file) {
+			file = filepath.Join(filepath.Dir(path), file)
+		}
+		xml, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		spec.Extension.XML = string(xml)
+	}
+	err = spec.Validate()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return &spec, nil
+}
+
+// Validate checks the spec for missing names, duplicate events and, if set,
+// the extension document.
+func (s *DSMOnboardingSpec) Validate() error {
+	if s.LogSourceType.Name == "" {
+		return fmt.Errorf("log source type without name")
+	}
+	if len(s.LogSourceType.ProtocolIDs) == 0 {
+		return fmt.Errorf("log source type %q without protocol_ids", s.LogSourceType.Name)
+	}
+	if s.Extension != nil {
+		if s.Extension.Name == "" {
+			return fmt.Errorf("extension without name")
+		}
+		if s.Extension.XML == "" {
+			return fmt.Errorf("extension %q without xml", s.Extension.Name)
+		}
+		_, issues := ValidateDeviceExtension([]byte(s.Extension.XML))
+		if issues.HasErrors() {
+			return fmt.Errorf("extension %q: %w", s.Extension.Name, issues[0])
+		}
+	}
+
+	seen := make(map[dsmEventKey]bool)
+	for _, e := range s.Events {
+		key := dsmEventKey{e.EventID, e.Category}
+		if e.EventID == "" {
+			return fmt.Errorf("event without event_id")
+		}
+		if seen[key] {
+			return fmt.Errorf("duplicate event %q of category %q", e.EventID, e.Category)
+		}
+		seen[key] = true
+		if e.QID.Name == "" && e.QID.UUID == "" {
+			return fmt.Errorf("event %q: qid without name or uuid", e.EventID)
+		}
+		if e.QID.Severity < 0 || e.QID.Severity > 10 {
+			return fmt.Errorf("event %q: severity %d is not from 0 to 10", e.EventID, e.QID.Severity)
+		}
+	}
+	return nil
+}
+
+type dsmEventKey struct {
+	eventID  string
+	category string
+}
+
+// Actions of the DSM onboarding.
+const (
+	DSMOnboardingCreated   = "created"
+	DSMOnboardingUpdated   = "updated"
+	DSMOnboardingUnchanged = "unchanged"
+)
+
+// DSMOnboardingAction represents a change of the DSM onboarding.
+type DSMOnboardingAction struct {
+	// Kind is one of "log_source_type", "extension", "qid" or "dsm".
+	Kind   string `json:"kind"`
+	Name   string `json:"name"`
+	ID     int    `json:"id"`
+	Action string `json:"action"`
+}
+
+// DSMOnboardingResult represents the outcome of the DSM onboarding.
+type DSMOnboardingResult struct {
+	LogSourceTypeID int                   `json:"log_source_type_id"`
+	ExtensionID     int                   `json:"extension_id,omitempty"`
+	Actions         []DSMOnboardingAction `json:"actions"`
+}
+
+func (r *DSMOnboardingResult) add(kind, name string, id int, action string) {
+	r.Actions = append(r.Actions, DSMOnboardingAction{Kind: kind, Name: name, ID: id, Action: action})
+}
+
+// DSMOnboarding creates or updates the custom DSM by the spec. Every object
+// is looked up before the change, so running it again only applies the
+// differences:
+//
+//   - the Log Source Extension is uploaded by name, before the type;
+//   - the Log Source Type is looked up by name and attached to the extension
+//     by log_source_extension_id;
+//   - QIDs are looked up by UUID or by name within the type;
+//   - DSM event mappings are looked up by event ID and category within the
+//     type.
+type DSMOnboarding struct {
+	client *Client
+	spec   *DSMOnboardingSpec
+}
+
+// NewDSMOnboarding returns the onboarding of the DSM by the spec.
+func NewDSMOnboarding(client *Client, spec *DSMOnboardingSpec) *DSMOnboarding {
+	return &DSMOnboarding{client: client, spec: spec}
+}
+
+// Run applies the spec. The result lists the changes made before an error.
+func (o *DSMOnboarding) Run(ctx context.Context) (*DSMOnboardingResult, error) {
+	r := &DSMOnboardingResult{}
+	err := o.spec.Validate()
+	if err != nil {
+		return r, err
+	}
+
+	if o.spec.Extension != nil {
+		r.ExtensionID, err = o.extension(ctx, r)
+		if err != nil {
+			return r, err
+		}
+	}
+
+	r.LogSourceTypeID, err = o.logSourceType(ctx, r)
+	if err != nil {
+		return r, err
+	}
+
+	mappings, err := o.client.DSM.Get(ctx, "", fmt.Sprintf("log_source_type_id=%d", r.LogSourceTypeID), 0, 0)
+	if err != nil {
+		return r, err
+	}
+	byEvent := make(map[dsmEventKey]DSM, len(mappings))
+	for _, m := range mappings {
+		byEvent[dsmEventKey{stringValue(m.LogSourceEventID), stringValue(m.LogSourceEventCategory)}] = m
+	}
+
+	for _, e := range o.spec.Events {
+		qid, err := o.qid(ctx, r, r.LogSourceTypeID, e.QID)
+		if err != nil {
+			return r, fmt.Errorf("event %q: %w", e.EventID, err)
+		}
+		err = o.mapping(ctx, r, r.LogSourceTypeID, e, qid, byEvent)
+		if err != nil {
+			return r, fmt.Errorf("event %q: %w", e.EventID, err)
+		}
+	}
+	return r, nil
+}
+
+func (o *DSMOnboarding) extension(ctx context.Context, r *DSMOnboardingResult) (int, error) {
+	spec := o.spec.Extension
+	existing, err := o.client.LogSourceExtension.GetByName(ctx, "", spec.Name)
+	if err != nil {
+		return 0, err
+	}
+
+	data := &LogSourceExtension{
+		Name:         &spec.Name,
+		Description:  &spec.Description,
+		UseCondition: spec.UseCondition,
+		XML:          &spec.XML,
+	}
+	if existing == nil {
+		created, err := o.client.LogSourceExtension.Create(ctx, "", data)
+		if err != nil {
+			return 0, err
+		}
+		if created.ID == nil {
+			return 0, fmt.Errorf("extension %q created without id", spec.Name)
+		}
+		r.add("extension", spec.Name, *created.ID, DSMOnboardingCreated)
+		return *created.ID, nil
+	}
+
+	if existing.ID == nil {
+		return 0, fmt.Errorf("extension %q has no id", spec.Name)
+	}
+	if stringValue(existing.XML) == spec.XML && stringValue(existing.Description) == spec.Description &&
+		(spec.UseCondition == nil || existing.UseCondition != nil && *existing.UseCondition == *spec.UseCondition) {
+		r.add("extension", spec.Name, *existing.ID, DSMOnboardingUnchanged)
+		return *existing.ID, nil
+	}
+	_, err = o.client.LogSourceExtension.UpdateByID(ctx, "", *existing.ID, data)
+	if err != nil {
+		return 0, err
+	}
+	r.add("extension", spec.Name, *existing.ID, DSMOnboardingUpdated)
+	return *existing.ID, nil
+}
+
+func (o *DSMOnboarding) logSourceType(ctx context.Context, r *DSMOnboardingResult) (int, error) {
+	spec := o.spec.LogSourceType
+	defaultProtocolID := spec.ProtocolIDs[0]
+	if spec.DefaultProtocolID != nil {
+		defaultProtocolID = *spec.DefaultProtocolID
+	}
+	protocols := make([]map[string]interface{}, len(spec.ProtocolIDs))
+	for i, id := range spec.ProtocolIDs {
+		protocols[i] = map[string]interface{}{"protocol_id": id, "documented": false}
+	}
+
+	existing, err := o.client.LogSourceType.GetByName(ctx, "", spec.Name)
+	if err != nil {
+		return 0, err
+	}
+
+	if existing == nil {
+		data := map[string]interface{}{
+			"name":                spec.Name,
+			"protocol_types":      protocols,
+			"default_protocol_id": defaultProtocolID,
+		}
+		if spec.Version != "" {
+			data["version"] = spec.Version
+		}
+		if r.ExtensionID != 0 {
+			data["log_source_extension_id"] = r.ExtensionID
+		}
+		created, err := o.client.LogSourceType.Create(ctx, "", data)
+		if err != nil {
+			return 0, err
+		}
+		if created.ID == nil {
+			return 0, fmt.Errorf("log source type %q created without id", spec.Name)
+		}
+		r.add("log_source_type", spec.Name, *created.ID, DSMOnboardingCreated)
+		return *created.ID, nil
+	}
+
+	if existing.ID == nil {
+		return 0, fmt.Errorf("log source type %q has no id", spec.Name)
+	}
+	if existing.Custom != nil && !*existing.Custom {
+		return 0, fmt.Errorf("log source type %q is not custom", spec.Name)
+	}
+
+	data := make(map[string]interface{})
+	if intValue(existing.DefaultProtocolID) != defaultProtocolID {
+		data["default_protocol_id"] = defaultProtocolID
+	}
+	if !sameProtocols(existing, spec.ProtocolIDs) {
+		data["protocol_types"] = protocols
+	}
+	if spec.Version != "" && stringValue(existing.Version) != spec.Version {
+		data["version"] = spec.Version
+	}
+	if r.ExtensionID != 0 && intValue(existing.LogSourceExtensionID) != r.ExtensionID {
+		data["log_source_extension_id"] = r.ExtensionID
+	}
+	if len(data) == 0 {
+		r.add("log_source_type", spec.Name, *existing.ID, DSMOnboardingUnchanged)
+		return *existing.ID, nil
+	}
+	_, err = o.client.LogSourceType.UpdateByID(ctx, "", *existing.ID, data)
+	if err != nil {
+		return 0, err
+	}
+	r.add("log_source_type", spec.Name, *existing.ID, DSMOnboardingUpdated)
+	return *existing.ID, nil
+}
+
+func sameProtocols(t *LogSourceType, ids []int) bool {
+	want := make(map[int]bool, len(ids))
+	for _, id := range ids {
+		want[id] = true
+	}
+	have := make(map[int]bool, len(t.ProtocolTypes))
+	for _, p := range t.ProtocolTypes {
+		if p.ProtocolID != nil {
+			have[*p.ProtocolID] = true
+		}
+	}
+	if len(want) != len(have) {
+		return false
+	}
+	for id := range want {
+		if !have[id] {
+			return false
+		}
+	}
+	return true
+}
+
+// findQID returns the QID of the spec or nil. The QID is looked up by UUID
+// if set and by name within the Log Source Type if the UUID is not set or
+// not found. A QID found by UUID must belong to the Log Source Type.
+func (o *DSMOnboarding) findQID(ctx context.Context, typeID int, spec DSMQIDSpec) (*QID, error) {
+	if spec.UUID != "" {
+		qids, err := o.client.QID.Get(ctx, "", "uuid="+filterString(spec.UUID), 0, 0)
+		if err != nil {
+			return nil, err
+		}
+		if len(qids) > 0 {
+			if t := intValue(qids[0].LogSourceTypeID); t != typeID {
+				return nil, fmt.Errorf("qid %s belongs to log source type %d, not %d", spec.UUID, t, typeID)
+			}
+			return &qids[0], nil
+		}
+		if spec.Name == "" {
+			return nil, fmt.Errorf("qid %s not found", spec.UUID)
+		}
+	}
+
+	filter := fmt.Sprintf("log_source_type_id=%d and name=%s", typeID, filterString(spec.Name))
+	qids, err := o.client.QID.Get(ctx, "", filter, 0, 0)
+	if err != nil {
+		return nil, err
+	}
+	if len(qids) > 1 {
+		return nil, fmt.Errorf("found more qids than expected by %s - %d", filter, len(qids))
+	}
+	if len(qids) == 0 {
+		return nil, nil
+	}
+	return &qids[0], nil
+}
+
+func (o *DSMOnboarding) qid(ctx context.Context, r *DSMOnboardingResult, typeID int, spec DSMQIDSpec) (int, error) {
+	existing, err := o.findQID(ctx, typeID, spec)
+	if err != nil {
+		return 0, err
+	}
+
+	if existing == nil {
+		data := &QID{
+			Name:               &spec.Name,
+			Description:        &spec.Description,
+			Severity:           &spec.Severity,
+			LowLevelCategoryID: &spec.LowLevelCategoryID,
+			LogSourceTypeID:    &typeID,
+		}
+		created, err := o.client.QID.Create(ctx, "", data)
+		if err != nil {
+			return 0, err
+		}
+		if created.ID == nil {
+			return 0, fmt.Errorf("qid %q created without id", spec.Name)
+		}
+		r.add("qid", spec.Name, *created.ID, DSMOnboardingCreated)
+		return *created.ID, nil
+	}
+
+	if existing.ID == nil {
+		return 0, fmt.Errorf("qid %q has no id", spec.Name)
+	}
+	name := spec.Name
+	if name == "" {
+		name = stringValue(existing.Name)
+	}
+	data := make(map[string]interface{})
+	if spec.Name != "" && stringValue(existing.Name) != spec.Name {
+		data["name"] = spec.Name
+	}
+	if stringValue(existing.Description) != spec.Description {
+		data["description"] = spec.Description
+	}
+	if intValue(existing.Severity) != spec.Severity {
+		data["severity"] = spec.Severity
+	}
+	if spec.LowLevelCategoryID != 0 && intValue(existing.LowLevelCategoryID) != spec.LowLevelCategoryID {
+		data["low_level_category_id"] = spec.LowLevelCategoryID
+	}
+	if len(data) == 0 {
+		r.add("qid", name, *existing.ID, DSMOnboardingUnchanged)
+		return *existing.ID, nil
+	}
+	_, err = o.client.QID.UpdateByID(ctx, "", *existing.ID, data)
+	if err != nil {
+		return 0, err
+	}
+	r.add("qid", name, *existing.ID, DSMOnboardingUpdated)
+	return *existing.ID, nil
+}
+
+func (o *DSMOnboarding) mapping(ctx context.Context, r *DSMOnboardingResult, typeID int, spec DSMEventSpec, qid int, byEvent map[dsmEventKey]DSM) error {
+	name := strings.TrimSuffix(spec.EventID+"/"+spec.Category, "/")
+	existing, ok := byEvent[dsmEventKey{spec.EventID, spec.Category}]
+	if !ok {
+		data := &DSM{
+			LogSourceTypeID:        &typeID,
+			LogSourceEventID:       &spec.EventID,
+			LogSourceEventCategory: &spec.Category,
+			QIDRecordID:            &qid,
+		}
+		created, err := o.client.DSM.Create(ctx, "", data)
+		if err != nil {
+			return err
+		}
+		r.add("dsm", name, intValue(created.ID), DSMOnboardingCreated)
+		return nil
+	}
+
+	if existing.ID == nil {
+		return fmt.Errorf("dsm event mapping %q has no id", name)
+	}
+	if intValue(existing.QIDRecordID) == qid {
+		r.add("dsm", name, *existing.ID, DSMOnboardingUnchanged)
+		return nil
+	}
+	_, err := o.client.DSM.UpdateByID(ctx, "", *existing.ID, &DSM{QIDRecordID: &qid})
+	if err != nil {
+		return err
+	}
+	r.add("dsm", name, *existing.ID, DSMOnboardingUpdated)
+	return nil
+}
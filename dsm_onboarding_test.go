@@ -0,0 +1,311 @@
+package qradar
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestDSMOnboardingQID(t *testing.T) {
+	const (
+		byUUID = `uuid="u1"`
+		byName = `log_source_type_id=7 and name="Login"`
+	)
+	tests := []struct {
+		name    string
+		spec    DSMQIDSpec
+		found   map[string]string
+		want    int
+		filters []string
+		action  string
+		err     string
+	}{
+		{
+			name:    "uuid",
+			spec:    DSMQIDSpec{UUID: "u1", Name: "Login", Severity: 3},
+			found:   map[string]string{byUUID: `[{"id":1,"uuid":"u1","name":"Login","severity":3,"log_source_type_id":7}]`},
+			want:    1,
+			filters: []string{byUUID},
+			action:  DSMOnboardingUnchanged,
+		},
+		{
+			name:    "uuid not found falls back to name",
+			spec:    DSMQIDSpec{UUID: "u1", Name: "Login", Severity: 5},
+			found:   map[string]string{byName: `[{"id":2,"name":"Login","severity":3,"log_source_type_id":7}]`},
+			want:    2,
+			filters: []string{byUUID, byName},
+			action:  DSMOnboardingUpdated,
+		},
+		{
+			name:    "name",
+			spec:    DSMQIDSpec{Name: "Login", Severity: 3},
+			found:   map[string]string{},
+			want:    3,
+			filters: []string{byName},
+			action:  DSMOnboardingCreated,
+		},
+		{
+			name:    "uuid of another type",
+			spec:    DSMQIDSpec{UUID: "u1", Name: "Login"},
+			found:   map[string]string{byUUID: `[{"id":1,"uuid":"u1","log_source_type_id":8}]`},
+			filters: []string{byUUID},
+			err:     "qid u1 belongs to log source type 8, not 7",
+		},
+		{
+			name:    "uuid without name not found",
+			spec:    DSMQIDSpec{UUID: "u1"},
+			found:   map[string]string{},
+			filters: []string{byUUID},
+			err:     "qid u1 not found",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var filters []string
+			c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+				switch r.Method {
+				case http.MethodGet:
+					filter := r.URL.Query().Get("filter")
+					filters = append(filters, filter)
+					if qids, ok := tt.found[filter]; ok {
+						w.Write([]byte(qids))
+						return
+					}
+					w.Write([]byte(`[]`))
+				case http.MethodPost:
+					body, _ := io.ReadAll(r.Body)
+					if strings.HasSuffix(r.URL.Path, "/qid_records") {
+						w.Write([]byte(`{"id":3}`))
+						return
+					}
+					w.Write(body)
+				}
+			})
+
+			r := &DSMOnboardingResult{}
+			got, err := NewDSMOnboarding(c, nil).qid(context.Background(), r, 7, tt.spec)
+			if tt.err != "" {
+				if err == nil || err.Error() != tt.err {
+					t.Errorf("qid() error %v, want %q", err, tt.err)
+				}
+			} else if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("qid() = %d, want %d", got, tt.want)
+			}
+			if !reflect.DeepEqual(filters, tt.filters) {
+				t.Errorf("filters %q, want %q", filters, tt.filters)
+			}
+			if tt.action != "" && (len(r.Actions) != 1 || r.Actions[0].Action != tt.action) {
+				t.Errorf("actions %+v, want %s", r.Actions, tt.action)
+			}
+		})
+	}
+}
+
+func TestReadDSMOnboardingSpec(t *testing.T) {
+	xml, err := os.ReadFile(filepath.Join("testdata", "extensions", "regex.xml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		spec string
+		err  string
+	}{
+		{"valid", `{"log_source_type":{"name":"Acme VPN","protocol_ids":[0]},"extension":{"name":"Acme_ext","file":"ext.xml"},"events":[{"event_id":"login","qid":{"name":"Login"}}]}`, ""},
+		{"no protocols", `{"log_source_type":{"name":"Acme VPN"}}`, `log source type "Acme VPN" without protocol_ids`},
+		{"duplicate event", `{"log_source_type":{"name":"Acme VPN","protocol_ids":[0]},"events":[{"event_id":"a","qid":{"name":"A"}},{"event_id":"a","qid":{"name":"A"}}]}`, `duplicate event "a" of category ""`},
+		{"no extension file", `{"log_source_type":{"name":"Acme VPN","protocol_ids":[0]},"extension":{"name":"Acme_ext","file":"missing.xml"}}`, "missing.xml"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			path := filepath.Join(dir, "spec.json")
+			err := os.WriteFile(path, []byte(tt.spec), 0o644)
+			if err == nil {
+				err = os.WriteFile(filepath.Join(dir, "ext.xml"), xml, 0o644)
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			spec, err := ReadDSMOnboardingSpec(path)
+			if tt.err == "" {
+				if err != nil {
+					t.Fatal(err)
+				}
+				if spec.Extension.XML != string(xml) {
+					t.Error("extension document is not read from the file")
+				}
+				return
+			}
+			if spec != nil {
+				t.Errorf("ReadDSMOnboardingSpec() = %+v, want nil", spec)
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("ReadDSMOnboardingSpec() error %v, want %q", err, tt.err)
+			}
+		})
+	}
+}
+
+// fakeQRadar keeps the objects created and updated through the API in memory
+// and serves them by the equality filters.
+type fakeQRadar struct {
+	t       *testing.T
+	objects map[string][]map[string]interface{}
+}
+
+var filterClause = regexp.MustCompile(`(\w+)=("(?:[^"\\]|\\.)*"|\d+)`)
+
+// matches returns true if the object satisfies every clause of the filter.
+func (f *fakeQRadar) matches(o map[string]interface{}, filter string) (bool, error) {
+	var clauses []string
+	ok := true
+	for _, m := range filterClause.FindAllStringSubmatch(filter, -1) {
+		clauses = append(clauses, m[0])
+		want := m[2]
+		if strings.HasPrefix(want, `"`) {
+			var err error
+			want, err = strconv.Unquote(want)
+			if err != nil {
+				return false, err
+			}
+		}
+		if o[m[1]] == nil || fmt.Sprint(o[m[1]]) != want {
+			ok = false
+		}
+	}
+	if strings.Join(clauses, " and ") != filter {
+		return false, fmt.Errorf("malformed filter %s", filter)
+	}
+	return ok, nil
+}
+
+func (f *fakeQRadar) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	path := strings.TrimPrefix(r.URL.Path, "/")
+	var prefix string
+	for p := range f.objects {
+		if path == p || strings.HasPrefix(path, p+"/") {
+			prefix = p
+		}
+	}
+	if prefix == "" {
+		f.t.Errorf("unexpected request %s %s", r.Method, r.URL)
+		http.NotFound(w, r)
+		return
+	}
+
+	if r.Method == http.MethodGet {
+		result := []map[string]interface{}{}
+		for _, o := range f.objects[prefix] {
+			ok, err := f.matches(o, r.URL.Query().Get("filter"))
+			if err != nil {
+				f.t.Error(err)
+				http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+				return
+			}
+			if ok {
+				result = append(result, o)
+			}
+		}
+		json.NewEncoder(w).Encode(result)
+		return
+	}
+
+	data := make(map[string]interface{})
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+		err := r.ParseMultipartForm(1 << 20)
+		if err != nil {
+			f.t.Error(err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		for k, v := range r.MultipartForm.Value {
+			data[k] = v[0]
+		}
+		if files := r.MultipartForm.File["file"]; len(files) > 0 {
+			file, err := files[0].Open()
+			if err == nil {
+				var bs []byte
+				bs, err = io.ReadAll(file)
+				data["xml"] = string(bs)
+			}
+			if err != nil {
+				f.t.Error(err)
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+		}
+	} else if err := json.NewDecoder(r.Body).Decode(&data); err != nil {
+		f.t.Error(err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if path == prefix {
+		data["id"] = len(f.objects[prefix]) + 1
+		f.objects[prefix] = append(f.objects[prefix], data)
+		json.NewEncoder(w).Encode(data)
+		return
+	}
+	id, err := strconv.Atoi(strings.TrimPrefix(path, prefix+"/"))
+	if err != nil || id < 1 || id > len(f.objects[prefix]) {
+		http.NotFound(w, r)
+		return
+	}
+	o := f.objects[prefix][id-1]
+	for k, v := range data {
+		o[k] = v
+	}
+	json.NewEncoder(w).Encode(o)
+}
+
+func TestDSMOnboardingRunTwice(t *testing.T) {
+	xml, err := os.ReadFile(filepath.Join("testdata", "extensions", "regex.xml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	spec := &DSMOnboardingSpec{
+		LogSourceType: DSMLogSourceTypeSpec{Name: `Acme "VPN"`, ProtocolIDs: []int{0, 1}},
+		Extension:     &DSMExtensionSpec{Name: `Acme "VPN" ext`, XML: string(xml)},
+		Events: []DSMEventSpec{
+			{EventID: "login", Category: "auth", QID: DSMQIDSpec{Name: `Acme "VPN" Login`, Severity: 3, LowLevelCategoryID: 3014}},
+			{EventID: "logout", QID: DSMQIDSpec{UUID: "u1", Name: `Acme \ Logout`, Severity: 1}},
+		},
+	}
+	f := &fakeQRadar{t: t, objects: map[string][]map[string]interface{}{
+		logSourceExtensionAPIPrefix: nil,
+		logSourceTypeAPIPrefix:      nil,
+		qidAPIPrefix:                nil,
+		dsmAPIPrefix:                nil,
+	}}
+	c := newTestClient(t, f.ServeHTTP)
+
+	for i, want := range []string{DSMOnboardingCreated, DSMOnboardingUnchanged} {
+		r, err := NewDSMOnboarding(c, spec).Run(context.Background())
+		if err != nil {
+			t.Fatalf("run %d: %v", i+1, err)
+		}
+		if len(r.Actions) != 6 {
+			t.Fatalf("run %d: actions %+v, want 6", i+1, r.Actions)
+		}
+		for _, a := range r.Actions {
+			if a.Action != want {
+				t.Errorf("run %d: %s %q is %s, want %s", i+1, a.Kind, a.Name, a.Action, want)
+			}
+		}
+	}
+}
@@ -0,0 +1,192 @@
+package qradar
+
+import (
+	"sort"
+	"strings"
+)
+
+// group is a constraint of the groups that form a hierarchy.
+type group interface {
+	RuleGroup | LogSourceGroup
+	groupID() *int
+	groupName() *string
+	groupParentID() *int
+	childGroupIDs() []int
+}
+
+func (g RuleGroup) groupID() *int        { return g.ID }
+func (g RuleGroup) groupName() *string   { return g.Name }
+func (g RuleGroup) groupParentID() *int  { return g.ParentID }
+func (g RuleGroup) childGroupIDs() []int { return g.ChildGroups }
+
+func (g LogSourceGroup) groupID() *int        { return g.ID }
+func (g LogSourceGroup) groupName() *string   { return g.Name }
+func (g LogSourceGroup) groupParentID() *int  { return g.ParentID }
+func (g LogSourceGroup) childGroupIDs() []int { return g.ChildGroupIDs }
+
+// GroupNode represents a group within the hierarchy.
+type GroupNode[G group] struct {
+	Group    G
+	Parent   *GroupNode[G]
+	Children []*GroupNode[G]
+}
+
+// GroupTree represents the hierarchy of the groups.
+type GroupTree[G group] struct {
+	Roots []*GroupNode[G]
+	nodes map[int]*GroupNode[G]
+}
+
+// newGroupTree builds the hierarchy of the groups from their parent IDs and
+// child group IDs. Groups with an unknown parent become roots, a cycle is
+// broken at the group with the lowest ID. Children are ordered by name.
+func newGroupTree[G group](groups []G) *GroupTree[G] {
+	t := &GroupTree[G]{nodes: make(map[int]*GroupNode[G])}
+	for _, g := range groups {
+		if g.groupID() == nil {
+			continue
+		}
+		t.nodes[*g.groupID()] = &GroupNode[G]{Group: g}
+	}
+
+	for _, n := range t.nodes {
+		for _, id := range n.Group.childGroupIDs() {
+			if child, ok := t.nodes[id]; ok && child.Parent == nil && child != n {
+				child.Parent = n
+			}
+		}
+	}
+	for _, n := range t.nodes {
+		parentID := n.Group.groupParentID()
+		if parentID == nil {
+			continue
+		}
+		if parent, ok := t.nodes[*parentID]; ok && parent != n {
+			n.Parent = parent
+		}
+	}
+
+	ids := make([]int, 0, len(t.nodes))
+	for id := range t.nodes {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	for _, id := range ids {
+		n := t.nodes[id]
+		if n.Parent == nil || n.isAncestor(n.Parent, len(t.nodes)) {
+			n.Parent = nil
+			t.Roots = append(t.Roots, n)
+			continue
+		}
+		n.Parent.Children = append(n.Parent.Children, n)
+	}
+
+	sortGroupNodes(t.Roots)
+	for _, n := range t.nodes {
+		sortGroupNodes(n.Children)
+	}
+	return t
+}
+
+// isAncestor returns true if the node is within max ancestors of the other
+// one, so linking them would create a cycle.
+func (n *GroupNode[G]) isAncestor(other *GroupNode[G], max int) bool {
+	for p := other; p != nil && max >= 0; p, max = p.Parent, max-1 {
+		if p == n {
+			return true
+		}
+	}
+	return false
+}
+
+func sortGroupNodes[G group](nodes []*GroupNode[G]) {
+	sort.Slice(nodes, func(i, j int) bool {
+		return nodes[i].Name() < nodes[j].Name()
+	})
+}
+
+// Node returns the node of the group by ID or nil.
+func (t *GroupTree[G]) Node(id int) *GroupNode[G] {
+	return t.nodes[id]
+}
+
+// Lookup returns the node of the group by path, e.g. "Site A/Firewalls", or
+// nil. The path starts from a root and the names are compared as is.
+func (t *GroupTree[G]) Lookup(path string) *GroupNode[G] {
+	names := splitGroupPath(path)
+	if len(names) == 0 {
+		return nil
+	}
+	nodes := t.Roots
+	var found *GroupNode[G]
+	for _, name := range names {
+		found = childByName(nodes, name)
+		if found == nil {
+			return nil
+		}
+		nodes = found.Children
+	}
+	return found
+}
+
+func childByName[G group](nodes []*GroupNode[G], name string) *GroupNode[G] {
+	for _, n := range nodes {
+		if n.Name() == name {
+			return n
+		}
+	}
+	return nil
+}
+
+// Walk calls fn for every node depth-first starting from the roots, depth of
+// the roots is zero. Walk stops on the first error of fn.
+func (t *GroupTree[G]) Walk(fn func(n *GroupNode[G], depth int) error) error {
+	var walk func(nodes []*GroupNode[G], depth int) error
+	walk = func(nodes []*GroupNode[G], depth int) error {
+		for _, n := range nodes {
+			err := fn(n, depth)
+			if err != nil {
+				return err
+			}
+			err = walk(n.Children, depth+1)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return walk(t.Roots, 0)
+}
+
+// Name returns the name of the group.
+func (n *GroupNode[G]) Name() string {
+	return stringValue(n.Group.groupName())
+}
+
+// Path returns the names of the group and its ancestors joined by slashes,
+// e.g. "Site A/Firewalls".
+func (n *GroupNode[G]) Path() string {
+	var names []string
+	for p := n; p != nil; p = p.Parent {
+		names = append([]string{p.Name()}, names...)
+	}
+	return strings.Join(names, "/")
+}
+
+func splitGroupPath(path string) []string {
+	var names []string
+	for _, name := range strings.Split(path, "/") {
+		name = strings.TrimSpace(name)
+		if name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+func stringValue(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
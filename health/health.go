@@ -0,0 +1,424 @@
+// Package health checks the health of QRadar's log sources: silent, failing,
+// disabled, unassigned and idle ones, grouped by log source group and event
+// collector.
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	qradar "github.com/ilyaglow/go-qradar"
+)
+
+// DefaultSilentThreshold is the time without events after which an enabled
+// log source is silent if no threshold of its type or group is set.
+const DefaultSilentThreshold = 24 * time.Hour
+
+// Finding represents a problem of the log source.
+type Finding string
+
+// Findings of the log sources.
+const (
+	// FindingSilent is set if the last event of the enabled log source is
+	// older than the threshold or it has never sent events.
+	FindingSilent Finding = "silent"
+	// FindingError is set if the status of the log source is error.
+	FindingError Finding = "error"
+	// FindingDisabled is set if the log source is disabled.
+	FindingDisabled Finding = "disabled"
+	// FindingUnassigned is set if the log source is auto-discovered and
+	// doesn't belong to any group but the unassigned ones.
+	FindingUnassigned Finding = "unassigned"
+	// FindingZeroEPS is set if the average EPS of the enabled log source is
+	// zero.
+	FindingZeroEPS Finding = "zero_eps"
+)
+
+// Findings are all the findings in the order of severity.
+var Findings = []Finding{FindingError, FindingSilent, FindingZeroEPS, FindingUnassigned, FindingDisabled}
+
+// Options represents options of the health check.
+type Options struct {
+	// Now is the time of the check, defaults to the current time.
+	Now time.Time
+	// SilentThreshold defaults to DefaultSilentThreshold.
+	SilentThreshold time.Duration
+	// TypeThresholds are the silent thresholds by log source type ID, they
+	// override SilentThreshold.
+	TypeThresholds map[int]time.Duration
+	// GroupThresholds are the silent thresholds by log source group ID, they
+	// override the type ones. The lowest one is used for the log sources of
+	// several groups.
+	GroupThresholds map[int]time.Duration
+	// UnassignedGroupIDs are the groups that don't count as an assignment,
+	// e.g. the "Other" group of the auto-discovered log sources.
+	UnassignedGroupIDs []int
+}
+
+// Entry represents the health of a log source.
+type Entry struct {
+	ID             int        `json:"id"`
+	Name           string     `json:"name"`
+	TypeID         int        `json:"type_id"`
+	TypeName       string     `json:"type_name,omitempty"`
+	CollectorID    int        `json:"collector_id,omitempty"`
+	CollectorName  string     `json:"collector_name,omitempty"`
+	GroupIDs       []int      `json:"group_ids,omitempty"`
+	Groups         []string   `json:"groups,omitempty"`
+	Enabled        bool       `json:"enabled"`
+	AutoDiscovered bool       `json:"auto_discovered"`
+	AverageEPS     int        `json:"average_eps"`
+	LastEventTime  *time.Time `json:"last_event_time,omitempty"`
+	// SilentFor is the time since the last event, zero if the log source
+	// has never sent events.
+	SilentFor       time.Duration `json:"silent_for,omitempty"`
+	SilentThreshold time.Duration `json:"silent_threshold"`
+	Status          string        `json:"status,omitempty"`
+	Messages        []string      `json:"messages,omitempty"`
+	Findings        []Finding     `json:"findings,omitempty"`
+}
+
+// Healthy returns true if the log source has no findings.
+func (e *Entry) Healthy() bool {
+	return len(e.Findings) == 0
+}
+
+// Has returns true if the log source has the finding.
+func (e *Entry) Has(f Finding) bool {
+	for _, ef := range e.Findings {
+		if ef == f {
+			return true
+		}
+	}
+	return false
+}
+
+// Summary represents the numbers of the log sources of a group or an event
+// collector by finding.
+type Summary struct {
+	ID        int             `json:"id"`
+	Name      string          `json:"name"`
+	Total     int             `json:"total"`
+	Unhealthy int             `json:"unhealthy"`
+	Findings  map[Finding]int `json:"findings"`
+	// LogSourceIDs are the IDs of the unhealthy log sources.
+	LogSourceIDs []int `json:"log_source_ids,omitempty"`
+}
+
+func (s *Summary) add(e *Entry) {
+	s.Total++
+	if e.Healthy() {
+		return
+	}
+	s.Unhealthy++
+	s.LogSourceIDs = append(s.LogSourceIDs, e.ID)
+	for _, f := range e.Findings {
+		s.Findings[f]++
+	}
+}
+
+// Report represents the health of the log sources. Entries are ordered by
+// ID, the log sources without a group are summarized under the group with
+// zero ID.
+type Report struct {
+	GeneratedAt time.Time `json:"generated_at"`
+	Entries     []Entry   `json:"entries"`
+	Groups      []Summary `json:"groups"`
+	Collectors  []Summary `json:"collectors"`
+}
+
+// logSourcePage is the number of log sources fetched per request.
+var logSourcePage = 500
+
+// Check fetches the log sources, their types, groups and event collectors and
+// builds the health report. Log sources are fetched page by page, so large
+// installations don't hit the API limits.
+func Check(ctx context.Context, client *qradar.Client, opts *Options) (*Report, error) {
+	var sources []qradar.LogSource
+	for from := 0; ; from += logSourcePage {
+		page, err := client.LogSource.Get(ctx, "", "", from, from+logSourcePage-1)
+		if err != nil {
+			return nil, err
+		}
+		sources = append(sources, page...)
+		if len(page) < logSourcePage {
+			break
+		}
+	}
+	types, err := client.LogSourceType.Get(ctx, "id,name", "", 0, 0)
+	if err != nil {
+		return nil, err
+	}
+	groups, err := client.LogSourceGroup.Get(ctx, "id,name", "", 0, 0)
+	if err != nil {
+		return nil, err
+	}
+	collectors, err := client.EventCollector.Get(ctx, "id,name", "", 0, 0)
+	if err != nil {
+		return nil, err
+	}
+	return BuildReport(sources, types, groups, collectors, opts), nil
+}
+
+// BuildReport classifies the log sources and summarizes them by group and
+// event collector.
+func BuildReport(sources []qradar.LogSource, types []qradar.LogSourceType, groups []qradar.LogSourceGroup, collectors []qradar.EventCollector, opts *Options) *Report {
+	var o Options
+	if opts != nil {
+		o = *opts
+	}
+	if o.Now.IsZero() {
+		o.Now = time.Now()
+	}
+	if o.SilentThreshold <= 0 {
+		o.SilentThreshold = DefaultSilentThreshold
+	}
+	unassigned := make(map[int]bool, len(o.UnassignedGroupIDs))
+	for _, id := range o.UnassignedGroupIDs {
+		unassigned[id] = true
+	}
+
+	typeNames := make(map[int]string, len(types))
+	for _, t := range types {
+		if t.ID != nil {
+			typeNames[*t.ID] = stringValue(t.Name)
+		}
+	}
+	groupNames := make(map[int]string, len(groups))
+	for _, g := range groups {
+		if g.ID != nil {
+			groupNames[*g.ID] = stringValue(g.Name)
+		}
+	}
+	collectorNames := make(map[int]string, len(collectors))
+	for _, c := range collectors {
+		if c.ID != nil {
+			collectorNames[*c.ID] = stringValue(c.Name)
+		}
+	}
+
+	r := &Report{GeneratedAt: o.Now}
+	for i := range sources {
+		s := &sources[i]
+		if s.ID == nil {
+			continue
+		}
+		e := Entry{
+			ID:             *s.ID,
+			Name:           stringValue(s.Name),
+			TypeID:         intValue(s.TypeID),
+			CollectorID:    intValue(s.TargetEventCollectorID),
+			GroupIDs:       append([]int(nil), s.GroupIDs...),
+			Enabled:        s.Enabled != nil && *s.Enabled,
+			AutoDiscovered: s.AutoDiscovered != nil && *s.AutoDiscovered,
+			AverageEPS:     intValue(s.AverageEps),
+		}
+		e.TypeName = typeNames[e.TypeID]
+		e.CollectorName = collectorNames[e.CollectorID]
+		sort.Ints(e.GroupIDs)
+		for _, id := range e.GroupIDs {
+			e.Groups = append(e.Groups, groupNames[id])
+		}
+		if s.LastEventTime != nil && *s.LastEventTime > 0 {
+			t := time.UnixMilli(int64(*s.LastEventTime)).UTC()
+			e.LastEventTime = &t
+			e.SilentFor = o.Now.Sub(t)
+		}
+		if s.Status != nil {
+			e.Status = stringValue(s.Status.Status)
+			for _, m := range s.Status.Messages {
+				if m.Text != nil {
+					e.Messages = append(e.Messages, *m.Text)
+				}
+			}
+		}
+		e.SilentThreshold = o.threshold(&e)
+
+		if strings.EqualFold(e.Status, "ERROR") {
+			e.Findings = append(e.Findings, FindingError)
+		}
+		if e.Enabled {
+			if e.LastEventTime == nil || e.SilentFor > e.SilentThreshold {
+				e.Findings = append(e.Findings, FindingSilent)
+			}
+			if e.AverageEPS == 0 {
+				e.Findings = append(e.Findings, FindingZeroEPS)
+			}
+		}
+		if e.AutoDiscovered && !assigned(e.GroupIDs, unassigned) {
+			e.Findings = append(e.Findings, FindingUnassigned)
+		}
+		if !e.Enabled {
+			e.Findings = append(e.Findings, FindingDisabled)
+		}
+
+		r.Entries = append(r.Entries, e)
+	}
+	sort.Slice(r.Entries, func(i, j int) bool { return r.Entries[i].ID < r.Entries[j].ID })
+
+	byGroup := make(map[int]*Summary)
+	byCollector := make(map[int]*Summary)
+	summary := func(m map[int]*Summary, id int, names map[int]string) *Summary {
+		s, ok := m[id]
+		if !ok {
+			s = &Summary{ID: id, Name: names[id], Findings: make(map[Finding]int)}
+			m[id] = s
+		}
+		return s
+	}
+	for i := range r.Entries {
+		e := &r.Entries[i]
+		if len(e.GroupIDs) == 0 {
+			summary(byGroup, 0, groupNames).add(e)
+		}
+		for _, id := range e.GroupIDs {
+			summary(byGroup, id, groupNames).add(e)
+		}
+		summary(byCollector, e.CollectorID, collectorNames).add(e)
+	}
+	r.Groups = sortedSummaries(byGroup)
+	r.Collectors = sortedSummaries(byCollector)
+
+	return r
+}
+
+func (o *Options) threshold(e *Entry) time.Duration {
+	threshold := o.SilentThreshold
+	if t, ok := o.TypeThresholds[e.TypeID]; ok && t > 0 {
+		threshold = t
+	}
+	var group time.Duration
+	for _, id := range e.GroupIDs {
+		if t, ok := o.GroupThresholds[id]; ok && t > 0 && (group == 0 || t < group) {
+			group = t
+		}
+	}
+	if group > 0 {
+		threshold = group
+	}
+	return threshold
+}
+
+func assigned(groupIDs []int, unassigned map[int]bool) bool {
+	for _, id := range groupIDs {
+		if !unassigned[id] {
+			return true
+		}
+	}
+	return false
+}
+
+func sortedSummaries(m map[int]*Summary) []Summary {
+	result := make([]Summary, 0, len(m))
+	for _, s := range m {
+		result = append(result, *s)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].ID < result[j].ID })
+	return result
+}
+
+// Unhealthy returns the entries with findings.
+func (r *Report) Unhealthy() []Entry {
+	var result []Entry
+	for _, e := range r.Entries {
+		if !e.Healthy() {
+			result = append(result, e)
+		}
+	}
+	return result
+}
+
+// WithFinding returns the entries with the finding.
+func (r *Report) WithFinding(f Finding) []Entry {
+	var result []Entry
+	for _, e := range r.Entries {
+		if e.Has(f) {
+			result = append(result, e)
+		}
+	}
+	return result
+}
+
+// WriteJSON writes the report as indented JSON.
+func (r *Report) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r)
+}
+
+// WriteTable writes the unhealthy log sources as an aligned table for the
+// terminal, followed by the summaries of the groups and event collectors.
+func (r *Report) WriteTable(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "ID\tNAME\tTYPE\tCOLLECTOR\tGROUPS\tLAST_EVENT\tEPS\tFINDINGS")
+	for _, e := range r.Unhealthy() {
+		last := "never"
+		if e.LastEventTime != nil {
+			last = e.LastEventTime.Format(time.RFC3339)
+		}
+		fmt.Fprintf(tw, "%d\t%s\t%s\t%s\t%s\t%s\t%d\t%s\n",
+			e.ID, e.Name, e.TypeName, e.CollectorName, strings.Join(e.Groups, "; "),
+			last, e.AverageEPS, joinFindings(e.Findings))
+	}
+
+	for _, section := range []struct {
+		title     string
+		summaries []Summary
+	}{
+		{"GROUP", r.Groups},
+		{"COLLECTOR", r.Collectors},
+	} {
+		fmt.Fprintf(tw, "\n%s\tTOTAL\tUNHEALTHY", section.title)
+		for _, f := range Findings {
+			fmt.Fprintf(tw, "\t%s", strings.ToUpper(string(f)))
+		}
+		fmt.Fprintln(tw)
+		for _, s := range section.summaries {
+			fmt.Fprintf(tw, "%s\t%d\t%d", summaryName(s), s.Total, s.Unhealthy)
+			for _, f := range Findings {
+				fmt.Fprintf(tw, "\t%d", s.Findings[f])
+			}
+			fmt.Fprintln(tw)
+		}
+	}
+	return tw.Flush()
+}
+
+func joinFindings(findings []Finding) string {
+	s := make([]string, len(findings))
+	for i, f := range findings {
+		s[i] = string(f)
+	}
+	return strings.Join(s, ",")
+}
+
+func summaryName(s Summary) string {
+	if s.Name != "" {
+		return s.Name
+	}
+	if s.ID == 0 {
+		return "(none)"
+	}
+	return strconv.Itoa(s.ID)
+}
+
+func stringValue(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+func intValue(n *int) int {
+	if n == nil {
+		return 0
+	}
+	return *n
+}
@@ -0,0 +1,142 @@
+package health
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	qradar "github.com/ilyaglow/go-qradar"
+)
+
+func ptr[T any](v T) *T {
+	return &v
+}
+
+var now = time.Date(2024, 1, 2, 12, 0, 0, 0, time.UTC)
+
+func testReport() *Report {
+	hourAgo := int(now.Add(-time.Hour).UnixMilli())
+	weekAgo := int(now.Add(-7 * 24 * time.Hour).UnixMilli())
+
+	errorStatus := qradar.LogSource{ID: ptr(5), Name: ptr("broken"), Enabled: ptr(true), AverageEps: ptr(1), LastEventTime: &hourAgo, TargetEventCollectorID: ptr(8), GroupIDs: []int{2}, Status: &qradar.LogSourceStatus{Status: ptr("ERROR")}}
+
+	sources := []qradar.LogSource{
+		{ID: ptr(3), Name: ptr("healthy"), TypeID: ptr(10), Enabled: ptr(true), AverageEps: ptr(5), LastEventTime: &hourAgo, TargetEventCollectorID: ptr(8), GroupIDs: []int{2}},
+		{ID: ptr(1), Name: ptr("silent"), TypeID: ptr(10), Enabled: ptr(true), AverageEps: ptr(5), LastEventTime: &weekAgo, TargetEventCollectorID: ptr(8), GroupIDs: []int{2}},
+		{ID: ptr(2), Name: ptr("new"), TypeID: ptr(11), Enabled: ptr(true), AutoDiscovered: ptr(true), TargetEventCollectorID: ptr(9), GroupIDs: []int{99}},
+		{ID: ptr(4), Name: ptr("off"), TypeID: ptr(11), Enabled: ptr(false), TargetEventCollectorID: ptr(9)},
+		errorStatus,
+		{Name: ptr("no id")},
+	}
+	types := []qradar.LogSourceType{{ID: ptr(10), Name: ptr("Cisco ASA")}}
+	groups := []qradar.LogSourceGroup{{ID: ptr(2), Name: ptr("Firewalls")}, {ID: ptr(99), Name: ptr("Other")}}
+	collectors := []qradar.EventCollector{{ID: ptr(8), Name: ptr("ec01")}, {ID: ptr(9), Name: ptr("ec01")}}
+
+	return BuildReport(sources, types, groups, collectors, &Options{
+		Now:                now,
+		UnassignedGroupIDs: []int{99},
+		GroupThresholds:    map[int]time.Duration{2: 2 * 24 * time.Hour},
+	})
+}
+
+func TestBuildReport(t *testing.T) {
+	r := testReport()
+
+	tests := []struct {
+		id       int
+		findings []Finding
+	}{
+		{1, []Finding{FindingSilent}},
+		{2, []Finding{FindingSilent, FindingZeroEPS, FindingUnassigned}},
+		{3, nil},
+		{4, []Finding{FindingDisabled}},
+		{5, []Finding{FindingError}},
+	}
+	if len(r.Entries) != len(tests) {
+		t.Fatalf("got %d entries, want %d", len(r.Entries), len(tests))
+	}
+	for i, tt := range tests {
+		e := r.Entries[i]
+		if e.ID != tt.id || !reflect.DeepEqual(e.Findings, tt.findings) {
+			t.Errorf("entry %d = %d %v, want %d %v", i, e.ID, e.Findings, tt.id, tt.findings)
+		}
+	}
+	if r.Entries[0].SilentThreshold != 2*24*time.Hour || r.Entries[0].TypeName != "Cisco ASA" {
+		t.Errorf("unexpected entry %+v", r.Entries[0])
+	}
+
+	var groups []string
+	for _, s := range r.Groups {
+		groups = append(groups, summaryName(s))
+	}
+	if want := []string{"(none)", "Firewalls", "Other"}; !reflect.DeepEqual(groups, want) {
+		t.Errorf("groups %v, want %v", groups, want)
+	}
+	if fw := r.Groups[1]; fw.Total != 3 || fw.Unhealthy != 2 || fw.Findings[FindingError] != 1 {
+		t.Errorf("unexpected summary %+v", fw)
+	}
+}
+
+func TestWritePrometheus(t *testing.T) {
+	var buf bytes.Buffer
+	err := testReport().WritePrometheus(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		`qradar_log_source_finding{id="1",name="silent",type="Cisco ASA",collector="ec01",finding="silent"} 1`,
+		`qradar_log_source_silent_threshold_seconds{id="1",name="silent",type="Cisco ASA",collector="ec01"} 172800`,
+		`qradar_log_source_group_findings{id="2",group="Firewalls",finding="error"} 1`,
+		// collectors of the same name are told apart by the ID
+		`qradar_log_source_collector_findings{id="8",collector="ec01",finding="silent"} 1`,
+		`qradar_log_source_collector_findings{id="9",collector="ec01",finding="silent"} 1`,
+	} {
+		if !strings.Contains(buf.String(), want+"\n") {
+			t.Errorf("no sample %s in\n%s", want, buf.String())
+		}
+	}
+}
+
+func TestCheckPages(t *testing.T) {
+	defer func(n int) { logSourcePage = n }(logSourcePage)
+	logSourcePage = 2
+
+	var ranges []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/config/event_sources/log_source_management/log_sources":
+			ranges = append(ranges, r.Header.Get("Range"))
+			switch r.Header.Get("Range") {
+			case "items=0-1":
+				w.Write([]byte(`[{"id":1},{"id":2}]`))
+			case "items=2-3":
+				w.Write([]byte(`[{"id":3}]`))
+			default:
+				w.Write([]byte(`[]`))
+			}
+		default:
+			w.Write([]byte(`[]`))
+		}
+	}))
+	defer srv.Close()
+	client, err := qradar.NewClient(srv.URL+"/", qradar.SetSECKey("test"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := Check(context.Background(), client, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(r.Entries) != 3 {
+		t.Errorf("got %d entries, want 3", len(r.Entries))
+	}
+	if want := []string{"items=0-1", "items=2-3"}; !reflect.DeepEqual(ranges, want) {
+		t.Errorf("requested ranges %v, want %v", ranges, want)
+	}
+}
@@ -0,0 +1,101 @@
+package health
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// MetricsPrefix is the prefix of the names of the metrics.
+const MetricsPrefix = "qradar_log_source"
+
+// WritePrometheus writes the report as metrics in the Prometheus text
+// exposition format, e.g. to be served by a textfile collector:
+//
+//	qradar_log_source_finding{id="42",name="fw01",type="Cisco ASA",collector="ec01",finding="silent"} 1
+//
+// The per log source metrics are followed by the number of log sources by
+// finding of every group and event collector, labelled by their ID and name
+// since the names are not unique.
+func (r *Report) WritePrometheus(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+
+	metric := func(name, typ, help string) {
+		fmt.Fprintf(bw, "# HELP %s_%s %s\n# TYPE %s_%s %s\n", MetricsPrefix, name, help, MetricsPrefix, name, typ)
+	}
+	sample := func(name string, labels []string, v float64) {
+		fmt.Fprintf(bw, "%s_%s{%s} %s\n", MetricsPrefix, name, strings.Join(labels, ","), strconv.FormatFloat(v, 'f', -1, 64))
+	}
+	entryLabels := func(e *Entry) []string {
+		return []string{
+			label("id", strconv.Itoa(e.ID)),
+			label("name", e.Name),
+			label("type", e.TypeName),
+			label("collector", e.CollectorName),
+		}
+	}
+
+	metric("healthy", "gauge", "Whether the log source has no findings.")
+	for i := range r.Entries {
+		e := &r.Entries[i]
+		v := 0.0
+		if e.Healthy() {
+			v = 1
+		}
+		sample("healthy", entryLabels(e), v)
+	}
+
+	metric("finding", "gauge", "Findings of the log source.")
+	for i := range r.Entries {
+		e := &r.Entries[i]
+		for _, f := range e.Findings {
+			sample("finding", append(entryLabels(e), label("finding", string(f))), 1)
+		}
+	}
+
+	metric("last_event_timestamp_seconds", "gauge", "Time of the last event of the log source.")
+	for i := range r.Entries {
+		e := &r.Entries[i]
+		if e.LastEventTime != nil {
+			sample("last_event_timestamp_seconds", entryLabels(e), float64(e.LastEventTime.Unix()))
+		}
+	}
+
+	metric("silent_threshold_seconds", "gauge", "Time without events after which the log source is silent.")
+	for i := range r.Entries {
+		e := &r.Entries[i]
+		sample("silent_threshold_seconds", entryLabels(e), e.SilentThreshold.Seconds())
+	}
+
+	metric("average_eps", "gauge", "Average events per second of the log source.")
+	for i := range r.Entries {
+		e := &r.Entries[i]
+		sample("average_eps", entryLabels(e), float64(e.AverageEPS))
+	}
+
+	for _, section := range []struct {
+		name, label string
+		summaries   []Summary
+	}{
+		{"group_findings", "group", r.Groups},
+		{"collector_findings", "collector", r.Collectors},
+	} {
+		metric(section.name, "gauge", fmt.Sprintf("Number of the log sources of the %s by finding.", section.label))
+		for _, s := range section.summaries {
+			for _, f := range Findings {
+				labels := []string{label("id", strconv.Itoa(s.ID)), label(section.label, summaryName(s)), label("finding", string(f))}
+				sample(section.name, labels, float64(s.Findings[f]))
+			}
+		}
+	}
+
+	return bw.Flush()
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func label(name, value string) string {
+	return name + `="` + labelEscaper.Replace(value) + `"`
+}
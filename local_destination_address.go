@@ -0,0 +1,53 @@
+package qradar
+
+import (
+	"context"
+	"net/http"
+)
+
+// LocalDestinationAddressService handles methods related to Offense Local Destination Addresses of the QRadar API.
+type LocalDestinationAddressService service
+
+const localDestinationAddressAPIPrefix = "api/siem/local_destination_addresses"
+
+// LocalDestinationAddress represents QRadar's Offense Local Destination Address.
+type LocalDestinationAddress struct {
+	ID                 *int    `json:"id,omitempty"`
+	LocalDestinationIP *string `json:"local_destination_ip,omitempty"`
+	Magnitude          *int    `json:"magnitude,omitempty"`
+	Network            *string `json:"network,omitempty"`
+	DomainID           *int    `json:"domain_id,omitempty"`
+	EventFlowCount     *int    `json:"event_flow_count,omitempty"`
+	FirstEventFlowSeen *int    `json:"first_event_flow_seen,omitempty"`
+	LastEventFlowSeen  *int    `json:"last_event_flow_seen,omitempty"`
+	OffenseIDs         []int   `json:"offense_ids,omitempty"`
+	SourceAddressIDs   []int   `json:"source_address_ids,omitempty"`
+}
+
+// Get returns Offense Local Destination Addresses of the current QRadar installation.
+func (c *LocalDestinationAddressService) Get(ctx context.Context, fields, filter string, from, to int) ([]LocalDestinationAddress, error) {
+	req, err := c.client.requestHelp(http.MethodGet, localDestinationAddressAPIPrefix, fields, filter, from, to, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	var result []LocalDestinationAddress
+	_, err = c.client.Do(ctx, req, &result)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetByID returns Offense Local Destination Address of the current QRadar installation by ID.
+func (c *LocalDestinationAddressService) GetByID(ctx context.Context, fields string, id int) (*LocalDestinationAddress, error) {
+	req, err := c.client.requestHelp(http.MethodGet, localDestinationAddressAPIPrefix, fields, "", 0, 0, &id, nil)
+	if err != nil {
+		return nil, err
+	}
+	var result LocalDestinationAddress
+	_, err = c.client.Do(ctx, req, &result)
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
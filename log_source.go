@@ -2,7 +2,9 @@ package qradar
 
 import (
 	"context"
+	"fmt"
 	"net/http"
+	"time"
 )
 
 // LogSourceService handles methods related to Log Sources of the QRadar API.
@@ -10,49 +12,75 @@ type LogSourceService service
 
 const (
 	logSourceAPIPrefix = "api/config/event_sources/log_source_management/log_sources"
+
+	logSourceBulkAdd    = "bulk_add"
+	logSourceBulkUpdate = "bulk_update"
+	logSourceBulkDelete = "bulk_delete"
 )
 
 // LogSource represents QRadar's Log Source Type.
 type LogSource struct {
-	SendingIP           *string `json:"sending_ip,omitempty"`
-	Internal            *bool   `json:"internal,omitempty"`
-	LegacyBulkGroupName *string `json:"legacy_bulk_group_name,omitempty"`
-	ProtocolParameters  []struct {
-		Name  *string `json:"name,omitempty"`
-		ID    *int    `json:"id,omitempty"`
-		Value *string `json:"value,omitempty"`
-	} `json:"protocol_parameters,omitempty"`
-	Description                      *string `json:"description,omitempty"`
-	CoalesceEvents                   *bool   `json:"coalesce_events,omitempty"`
-	Enabled                          *bool   `json:"enabled,omitempty"`
-	GroupIDs                         []int   `json:"group_ids,omitempty"`
-	AverageEps                       *int    `json:"average_eps,omitempty"`
-	Credibility                      *int    `json:"credibility,omitempty"`
-	ID                               *int    `json:"id,omitempty"`
-	StoreEventPayload                *bool   `json:"store_event_payload,omitempty"`
-	TargetEventCollectorID           *int    `json:"target_event_collector_id,omitempty"`
-	ProtocolTypeID                   *int    `json:"protocol_type_id,omitempty"`
-	LanguageID                       *int    `json:"language_id,omitempty"`
-	CreationDate                     *int    `json:"creation_date,omitempty"`
-	LogSourceExtensionID             *int    `json:"log_source_extension_id,omitempty"`
-	WincollectExternalDestinationIDs []int   `json:"wincollect_external_destination_ids,omitempty"`
-	Name                             *string `json:"name,omitempty"`
-	AutoDiscovered                   *bool   `json:"auto_discovered,omitempty"`
-	ModifiedDate                     *int    `json:"modified_date,omitempty"`
-	TypeID                           *int    `json:"type_id,omitempty"`
-	LastEventTime                    *int    `json:"last_event_time,omitempty"`
-	RequiresDeploy                   *bool   `json:"requires_deploy,omitempty"`
-	Gateway                          *bool   `json:"gateway,omitempty"`
-	WincollectInternalDestinationID  *int    `json:"wincollect_internal_destination_id,omitempty"`
-	Status                           struct {
-		LastUpdated *int `json:"last_updated,omitempty"`
-		Messages    []struct {
-			Severity  *string `json:"severity,omitempty"`
-			Text      *string `json:"text,omitempty"`
-			Timestamp *int    `json:"timestamp,omitempty"`
-		} `json:"messages,omitempty"`
-		Status *string `json:"status,omitempty"`
-	} `json:"status,omitempty"`
+	SendingIP                        *string             `json:"sending_ip,omitempty"`
+	Internal                         *bool               `json:"internal,omitempty"`
+	LegacyBulkGroupName              *string             `json:"legacy_bulk_group_name,omitempty"`
+	ProtocolParameters               []ProtocolParameter `json:"protocol_parameters,omitempty"`
+	Description                      *string             `json:"description,omitempty"`
+	CoalesceEvents                   *bool               `json:"coalesce_events,omitempty"`
+	Enabled                          *bool               `json:"enabled,omitempty"`
+	GroupIDs                         []int               `json:"group_ids,omitempty"`
+	AverageEps                       *int                `json:"average_eps,omitempty"`
+	Credibility                      *int                `json:"credibility,omitempty"`
+	ID                               *int                `json:"id,omitempty"`
+	StoreEventPayload                *bool               `json:"store_event_payload,omitempty"`
+	TargetEventCollectorID           *int                `json:"target_event_collector_id,omitempty"`
+	ProtocolTypeID                   *int                `json:"protocol_type_id,omitempty"`
+	LanguageID                       *int                `json:"language_id,omitempty"`
+	CreationDate                     *int                `json:"creation_date,omitempty"`
+	LogSourceExtensionID             *int                `json:"log_source_extension_id,omitempty"`
+	WincollectExternalDestinationIDs []int               `json:"wincollect_external_destination_ids,omitempty"`
+	Name                             *string             `json:"name,omitempty"`
+	AutoDiscovered                   *bool               `json:"auto_discovered,omitempty"`
+	ModifiedDate                     *int                `json:"modified_date,omitempty"`
+	TypeID                           *int                `json:"type_id,omitempty"`
+	LastEventTime                    *int                `json:"last_event_time,omitempty"`
+	RequiresDeploy                   *bool               `json:"requires_deploy,omitempty"`
+	Gateway                          *bool               `json:"gateway,omitempty"`
+	WincollectInternalDestinationID  *int                `json:"wincollect_internal_destination_id,omitempty"`
+	Status                           *LogSourceStatus    `json:"status,omitempty"`
+}
+
+// ProtocolParameter represents a parameter of the protocol of the Log Source.
+type ProtocolParameter struct {
+	Name  *string `json:"name,omitempty"`
+	ID    *int    `json:"id,omitempty"`
+	Value *string `json:"value,omitempty"`
+}
+
+// LogSourceStatus represents the status of the Log Source.
+type LogSourceStatus struct {
+	LastUpdated *int `json:"last_updated,omitempty"`
+	Messages    []struct {
+		Severity  *string `json:"severity,omitempty"`
+		Text      *string `json:"text,omitempty"`
+		Timestamp *int    `json:"timestamp,omitempty"`
+	} `json:"messages,omitempty"`
+	Status *string `json:"status,omitempty"`
+}
+
+// LogSourceBulkTask represents the status of the bulk operation on the Log
+// Sources.
+type LogSourceBulkTask struct {
+	ID        *int    `json:"id,omitempty"`
+	Name      *string `json:"name,omitempty"`
+	Status    *string `json:"status,omitempty"`
+	Message   *string `json:"message,omitempty"`
+	CreatedBy *string `json:"created_by,omitempty"`
+	Created   *int    `json:"created,omitempty"`
+	Started   *int    `json:"started,omitempty"`
+	Modified  *int    `json:"modified,omitempty"`
+	Completed *int    `json:"completed,omitempty"`
+	// IDs are the IDs of the created Log Sources of the bulk add.
+	IDs []int `json:"ids,omitempty"`
 }
 
 // Get returns Log Sources of the current QRadar installation.
@@ -68,3 +96,165 @@ func (c *LogSourceService) Get(ctx context.Context, fields, filter string, from,
 	}
 	return result, nil
 }
+
+// GetByID returns Log Source of the current QRadar installation by ID.
+func (c *LogSourceService) GetByID(ctx context.Context, fields string, id int) (*LogSource, error) {
+	req, err := c.client.requestHelp(http.MethodGet, logSourceAPIPrefix, fields, "", 0, 0, &id, nil)
+	if err != nil {
+		return nil, err
+	}
+	var result LogSource
+	_, err = c.client.Do(ctx, req, &result)
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// Create creates Log Source in the current QRadar installation.
+func (c *LogSourceService) Create(ctx context.Context, fields string, data interface{}) (*LogSource, error) {
+	req, err := c.client.requestHelp(http.MethodPost, logSourceAPIPrefix, fields, "", 0, 0, nil, data)
+	if err != nil {
+		return nil, err
+	}
+	var result LogSource
+	_, err = c.client.Do(ctx, req, &result)
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// UpdateByID updates Log Source of the current QRadar installation by ID.
+// Only the fields set in the data are changed, protocol_parameters are
+// replaced as a whole.
+func (c *LogSourceService) UpdateByID(ctx context.Context, fields string, id int, data interface{}) (*LogSource, error) {
+	req, err := c.client.requestHelp(http.MethodPatch, logSourceAPIPrefix, fields, "", 0, 0, &id, data)
+	if err != nil {
+		return nil, err
+	}
+	var result LogSource
+	_, err = c.client.Do(ctx, req, &result)
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// DeleteByID deletes Log Source of the current QRadar installation by ID.
+func (c *LogSourceService) DeleteByID(ctx context.Context, fields string, id int) (*DeleteTask, error) {
+	req, err := c.client.requestHelp(http.MethodDelete, logSourceAPIPrefix, fields, "", 0, 0, &id, nil)
+	if err != nil {
+		return nil, err
+	}
+	var result DeleteTask
+	_, err = c.client.Do(ctx, req, &result)
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// BulkAdd starts the asynchronous creation of the Log Sources.
+func (c *LogSourceService) BulkAdd(ctx context.Context, fields string, data []LogSource) (*LogSourceBulkTask, error) {
+	return c.bulk(ctx, logSourceBulkAdd, fields, data)
+}
+
+// BulkUpdate starts the asynchronous update of the Log Sources, every Log
+// Source of the data must have the ID set.
+func (c *LogSourceService) BulkUpdate(ctx context.Context, fields string, data []LogSource) (*LogSourceBulkTask, error) {
+	for i := range data {
+		if data[i].ID == nil {
+			return nil, fmt.Errorf("log source %d of the bulk update has no id", i)
+		}
+	}
+	return c.bulk(ctx, logSourceBulkUpdate, fields, data)
+}
+
+// BulkDelete starts the asynchronous deletion of the Log Sources by IDs.
+func (c *LogSourceService) BulkDelete(ctx context.Context, fields string, ids []int) (*LogSourceBulkTask, error) {
+	return c.bulk(ctx, logSourceBulkDelete, fields, ids)
+}
+
+func (c *LogSourceService) bulk(ctx context.Context, op, fields string, data interface{}) (*LogSourceBulkTask, error) {
+	req, err := c.client.requestHelp(http.MethodPost, logSourceAPIPrefix+"/"+op, fields, "", 0, 0, nil, data)
+	if err != nil {
+		return nil, err
+	}
+	var result LogSourceBulkTask
+	_, err = c.client.Do(ctx, req, &result)
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// BulkAddStatus returns the status of the bulk add task by ID.
+func (c *LogSourceService) BulkAddStatus(ctx context.Context, fields string, id int) (*LogSourceBulkTask, error) {
+	return c.bulkStatus(ctx, logSourceBulkAdd, fields, id)
+}
+
+// BulkUpdateStatus returns the status of the bulk update task by ID.
+func (c *LogSourceService) BulkUpdateStatus(ctx context.Context, fields string, id int) (*LogSourceBulkTask, error) {
+	return c.bulkStatus(ctx, logSourceBulkUpdate, fields, id)
+}
+
+// BulkDeleteStatus returns the status of the bulk delete task by ID.
+func (c *LogSourceService) BulkDeleteStatus(ctx context.Context, fields string, id int) (*LogSourceBulkTask, error) {
+	return c.bulkStatus(ctx, logSourceBulkDelete, fields, id)
+}
+
+func (c *LogSourceService) bulkStatus(ctx context.Context, op, fields string, id int) (*LogSourceBulkTask, error) {
+	req, err := c.client.requestHelp(http.MethodGet, logSourceAPIPrefix+"/"+op+"_tasks", fields, "", 0, 0, &id, nil)
+	if err != nil {
+		return nil, err
+	}
+	var result LogSourceBulkTask
+	_, err = c.client.Do(ctx, req, &result)
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// WaitForBulkAdd polls the status of the bulk add task by ID every given
+// seconds until it's finished.
+func (c *LogSourceService) WaitForBulkAdd(ctx context.Context, id, seconds int) (*LogSourceBulkTask, error) {
+	return c.waitForBulk(ctx, logSourceBulkAdd, id, seconds)
+}
+
+// WaitForBulkUpdate polls the status of the bulk update task by ID every
+// given seconds until it's finished.
+func (c *LogSourceService) WaitForBulkUpdate(ctx context.Context, id, seconds int) (*LogSourceBulkTask, error) {
+	return c.waitForBulk(ctx, logSourceBulkUpdate, id, seconds)
+}
+
+// WaitForBulkDelete polls the status of the bulk delete task by ID every
+// given seconds until it's finished.
+func (c *LogSourceService) WaitForBulkDelete(ctx context.Context, id, seconds int) (*LogSourceBulkTask, error) {
+	return c.waitForBulk(ctx, logSourceBulkDelete, id, seconds)
+}
+
+func (c *LogSourceService) waitForBulk(ctx context.Context, op string, id, seconds int) (*LogSourceBulkTask, error) {
+	ticker := time.NewTicker(time.Duration(seconds) * time.Second)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-ticker.C:
+			t, err := c.bulkStatus(ctx, op, "", id)
+			if err != nil {
+				return nil, err
+			}
+
+			if t.Status == nil {
+				return t, fmt.Errorf("%s task %d has no status", op, id)
+			}
+
+			if TaskStatus(*t.Status).Finished() {
+				return t, nil
+			}
+		}
+	}
+}
@@ -3,9 +3,13 @@ package qradar
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
+	"io"
 	"mime/multipart"
 	"net/http"
+	"net/textproto"
+	"strconv"
 	"strings"
 )
 
@@ -43,23 +47,21 @@ func (c *LogSourceExtensionService) Get(ctx context.Context, fields, filter stri
 	return result, nil
 }
 
-// todo: add support of multipart uploading
-
-// Create creates Log Source Extension in the current QRadar installation. Undocumented API.
+// Create uploads Log Source Extension to the current QRadar installation.
+// The data is a LogSourceExtension or a value of the same JSON form, its name,
+// description, use_condition and enabled are sent as the form fields and the
+// XML as the file. Undocumented API.
 func (c *LogSourceExtensionService) Create(ctx context.Context, fields string, data interface{}) (*LogSourceExtension, error) {
-	req, err := c.client.requestHelp(http.MethodPost, logSourceExtensionAPIPrefix, fields, "", 0, 0, nil, data)
+	ext, err := logSourceExtensionData(data)
+	if err != nil {
+		return nil, err
+	}
+	req, err := c.client.customRequest(http.MethodPost, logSourceExtensionAPIPrefix, fields, nil, ext)
 	if err != nil {
 		return nil, err
 	}
-
-	var b bytes.Buffer
-	w := multipart.NewWriter(&b)
-
-	defer w.Close()
 
 	req.Header.Set("Allow-Hidden", "true")
-	// req.Header.Set("Content-Type", w.FormDataContentType()) //
-	req.Header.Set("Content-Type", "multipart/form-data")
 
 	var result LogSourceExtension
 	_, err = c.client.Do(ctx, req, &result)
@@ -86,29 +88,49 @@ func (c *LogSourceExtensionService) GetByID(ctx context.Context, fields string,
 	return &result, nil
 }
 
-// todo: add support of multipart uploading
-/*
-	// UpdateByID updates Log Source Extension of the current QRadar installation by ID. Undocumented API.
-	func (c *LogSourceExtensionService) UpdateByID(ctx context.Context, fields string, id int, data interface{}) (*LogSourceExtension, error) {
-		req, err := c.client.requestHelp(http.MethodPost, logSourceExtensionAPIPrefix, fields, "", 0, 0, &id, data)
-		if err != nil {
-			return nil, err
-		}
+// UpdateByID uploads the new version of Log Source Extension of the current
+// QRadar installation by ID. The data is accepted as by Create, only its set
+// fields are sent and the XML is kept if not set. Undocumented API.
+func (c *LogSourceExtensionService) UpdateByID(ctx context.Context, fields string, id int, data interface{}) (*LogSourceExtension, error) {
+	ext, err := logSourceExtensionData(data)
+	if err != nil {
+		return nil, err
+	}
+	req, err := c.client.customRequest(http.MethodPost, logSourceExtensionAPIPrefix, fields, &id, ext)
+	if err != nil {
+		return nil, err
+	}
 
-		req.Header.Set("Allow-Hidden", "true")
+	req.Header.Set("Allow-Hidden", "true")
 
-		var result LogSourceExtension
-		_, err = c.client.Do(ctx, req, &result)
-		if err != nil {
-			return nil, err
-		}
-		return &result, nil
+	var result LogSourceExtension
+	_, err = c.client.Do(ctx, req, &result)
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// DeleteByID deletes Log Source Extension of the current QRadar installation by ID. Undocumented API.
+func (c *LogSourceExtensionService) DeleteByID(ctx context.Context, fields string, id int) (*DeleteTask, error) {
+	req, err := c.client.requestHelp(http.MethodDelete, logSourceExtensionAPIPrefix, fields, "", 0, 0, &id, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Allow-Hidden", "true")
+
+	var result DeleteTask
+	_, err = c.client.Do(ctx, req, &result)
+	if err != nil {
+		return nil, err
 	}
-*/
+	return &result, nil
+}
 
 // GetByName returns Log Source Extension of the current QRadar installation by Name. Undocumented API.
 func (c *LogSourceExtensionService) GetByName(ctx context.Context, fields string, name string) (*LogSourceExtension, error) {
-	req, err := c.client.requestHelp(http.MethodGet, logSourceExtensionAPIPrefix, fields, fmt.Sprintf("name=\"%s\"", name), 0, 0, nil, nil)
+	req, err := c.client.requestHelp(http.MethodGet, logSourceExtensionAPIPrefix, fields, "name="+filterString(name), 0, 0, nil, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -128,7 +150,36 @@ func (c *LogSourceExtensionService) GetByName(ctx context.Context, fields string
 	return &result[0], nil
 }
 
-func (c *Client) customRequest(method, urlStr, fields, filter string, id *int, body interface{}) (*http.Request, error) {
+// logSourceExtensionData returns the data of Create and UpdateByID as
+// LogSourceExtension, values of other types are converted through JSON.
+func logSourceExtensionData(data interface{}) (*LogSourceExtension, error) {
+	switch d := data.(type) {
+	case nil:
+		return nil, nil
+	case *LogSourceExtension:
+		return d, nil
+	case LogSourceExtension:
+		return &d, nil
+	}
+
+	bs, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+	var ext LogSourceExtension
+	err = json.Unmarshal(bs, &ext)
+	if err != nil {
+		return nil, fmt.Errorf("log source extension data: %w", err)
+	}
+	return &ext, nil
+}
+
+// logSourceExtensionFile is the name of the uploaded XML of Log Source Extension.
+const logSourceExtensionFile = "extension.xml"
+
+// customRequest creates a multipart/form-data API request of Log Source
+// Extension upload.
+func (c *Client) customRequest(method, urlStr, fields string, id *int, data *LogSourceExtension) (*http.Request, error) {
 	if id != nil {
 		urlStr = fmt.Sprintf("%s/%d", urlStr, *id)
 	}
@@ -143,12 +194,51 @@ func (c *Client) customRequest(method, urlStr, fields, filter string, id *int, b
 
 	var b bytes.Buffer
 	w := multipart.NewWriter(&b)
-	//io.Closer
-	w.CreateFormFile("file", b.String())
-	w.Close()
-	//var buf io.ReadWriter
-	if body != nil {
 
+	if data != nil {
+		if data.Name != nil {
+			err = w.WriteField("name", *data.Name)
+			if err != nil {
+				return nil, err
+			}
+		}
+		if data.Description != nil {
+			err = w.WriteField("description", *data.Description)
+			if err != nil {
+				return nil, err
+			}
+		}
+		if data.UseCondition != nil {
+			err = w.WriteField("use_condition", strconv.Itoa(*data.UseCondition))
+			if err != nil {
+				return nil, err
+			}
+		}
+		if data.Enabled != nil {
+			err = w.WriteField("enabled", strconv.FormatBool(*data.Enabled))
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		if data.XML != nil {
+			h := make(textproto.MIMEHeader)
+			h.Set("Content-Disposition", fmt.Sprintf(`form-data; name="file"; filename="%s"`, logSourceExtensionFile))
+			h.Set("Content-Type", "application/xml")
+			part, err := w.CreatePart(h)
+			if err != nil {
+				return nil, err
+			}
+			_, err = io.WriteString(part, *data.XML)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	err = w.Close()
+	if err != nil {
+		return nil, err
 	}
 
 	req, err := http.NewRequest(method, u.String(), &b)
@@ -156,7 +246,8 @@ func (c *Client) customRequest(method, urlStr, fields, filter string, id *int, b
 		return nil, err
 	}
 
-	// req.Header.Set("Accept", "application/json")
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("Content-Type", w.FormDataContentType())
 
 	if c.APIv == "" {
 		req.Header.Set("Version", defaultAPIVersion)
@@ -164,10 +255,6 @@ func (c *Client) customRequest(method, urlStr, fields, filter string, id *int, b
 		req.Header.Set("Version", c.APIv)
 	}
 
-	//if buf != nil {
-	//	req.Header.Set("Content-Type", "application/json")
-	//}
-
 	if c.SECKey != "" {
 		req.Header.Set("SEC", c.SECKey)
 	}
@@ -176,15 +263,11 @@ func (c *Client) customRequest(method, urlStr, fields, filter string, id *int, b
 		req.Header.Set("User-Agent", c.UserAgent)
 	}
 
-	q := req.URL.Query()
 	if fields != "" {
+		q := req.URL.Query()
 		q.Add("fields", fields)
+		req.URL.RawQuery = q.Encode()
 	}
-	if filter != "" {
-		q.Add("filter", filter)
-	}
-	req.URL.RawQuery = q.Encode()
 
 	return req, nil
-
 }
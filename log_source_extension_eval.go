@@ -0,0 +1,439 @@
+package qradar
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ExtensionResult represents the fields extracted from the payload by the
+// Log Source Extension.
+type ExtensionResult struct {
+	Payload string `json:"payload"`
+	// MatchGroup is the order of the match group that extracted the fields,
+	// empty if none matched.
+	MatchGroup string `json:"match_group,omitempty"`
+	// Fields are the normalized fields by name, e.g. EventName or SourceIp.
+	Fields map[string]string `json:"fields"`
+	// EventCategory, Severity and SendIdentity are set by the event match of
+	// the match group.
+	EventCategory string `json:"event_category,omitempty"`
+	Severity      string `json:"severity,omitempty"`
+	SendIdentity  string `json:"send_identity,omitempty"`
+	// Unsupported are the matchers that were skipped by the evaluator.
+	Unsupported []string `json:"unsupported,omitempty"`
+}
+
+// ExtensionEvaluator applies the patterns and matchers of the Log Source
+// Extension to the raw payloads locally.
+//
+// Match groups are tried by their order and the first one with any matched
+// field wins. Within the group the matchers of a field are tried by their
+// order and the first non-empty value wins. Supported matchers are matcher
+// (regular expression), json-matcher, leef-matcher and cef-matcher. Values are
+// extracted as is, e.g. DeviceTime is not parsed with the ext-data date
+// format.
+type ExtensionEvaluator struct {
+	doc      *DeviceExtension
+	patterns map[string]*regexp.Regexp
+	groups   []*MatchGroup
+}
+
+// NewExtensionEvaluator compiles the patterns of the document. Patterns with
+// Java regular expression syntax unsupported by the regexp package fail the
+// compilation.
+func NewExtensionEvaluator(d *DeviceExtension) (*ExtensionEvaluator, error) {
+	e := &ExtensionEvaluator{doc: d, patterns: make(map[string]*regexp.Regexp)}
+	for _, p := range d.Patterns {
+		expr := p.Regex
+		if strings.EqualFold(p.TrimWhitespace, "true") {
+			expr = strings.TrimSpace(expr)
+		}
+		if strings.EqualFold(p.CaseInsensitive, "true") {
+			expr = "(?i)" + expr
+		}
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			return nil, fmt.Errorf("%s: pattern %q: %w", p.Pos, p.ID, err)
+		}
+		e.patterns[p.ID] = re
+	}
+
+	for i := range d.MatchGroups {
+		e.groups = append(e.groups, &d.MatchGroups[i])
+	}
+	sort.SliceStable(e.groups, func(i, j int) bool {
+		return orderValue(e.groups[i].Order) < orderValue(e.groups[j].Order)
+	})
+	return e, nil
+}
+
+func orderValue(s string) int {
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		return 0
+	}
+	return n
+}
+
+// Evaluate extracts the fields of the payload.
+func (e *ExtensionEvaluator) Evaluate(payload string) *ExtensionResult {
+	for _, mg := range e.groups {
+		r := &ExtensionResult{Payload: payload, Fields: make(map[string]string)}
+		e.evaluateGroup(mg, payload, r)
+		if len(r.Fields) > 0 {
+			r.MatchGroup = mg.Order
+			return r
+		}
+	}
+	return &ExtensionResult{Payload: payload, Fields: map[string]string{}}
+}
+
+// EvaluateAll extracts the fields of every payload.
+func (e *ExtensionEvaluator) EvaluateAll(payloads []string) []*ExtensionResult {
+	results := make([]*ExtensionResult, len(payloads))
+	for i, p := range payloads {
+		results[i] = e.Evaluate(p)
+	}
+	return results
+}
+
+// WriteExtensionResults writes the results as indented JSON with the fields
+// sorted by name, suitable for golden files.
+func WriteExtensionResults(w io.Writer, results []*ExtensionResult) error {
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	return enc.Encode(results)
+}
+
+func (e *ExtensionEvaluator) evaluateGroup(mg *MatchGroup, payload string, r *ExtensionResult) {
+	matchers := make([]*ExtensionMatcher, len(mg.Matchers))
+	for i := range mg.Matchers {
+		matchers[i] = &mg.Matchers[i]
+	}
+	sort.SliceStable(matchers, func(i, j int) bool {
+		return orderValue(matchers[i].Order) < orderValue(matchers[j].Order)
+	})
+
+	var parsed structuredPayload
+	for _, m := range matchers {
+		if _, ok := r.Fields[m.Field]; ok {
+			continue
+		}
+		v, ok := e.match(m, payload, &parsed)
+		if !ok {
+			r.Unsupported = append(r.Unsupported, fmt.Sprintf("%s: %s of field %s", m.Pos, m.XMLName.Local, m.Field))
+			continue
+		}
+		if v != "" {
+			r.Fields[m.Field] = v
+		}
+	}
+	if len(r.Fields) == 0 {
+		return
+	}
+
+	for _, s := range mg.Single {
+		if s.EventName != "" {
+			r.Fields["EventName"] = s.EventName
+		}
+		r.EventCategory = s.DeviceEventCategory
+		r.Severity = s.Severity
+		r.SendIdentity = s.SendIdentity
+	}
+	for _, m := range mg.Multiple {
+		re, ok := e.patterns[m.PatternID]
+		if !ok {
+			continue
+		}
+		sm := re.FindStringSubmatch(payload)
+		i := orderValue(m.CaptureGroupIndex)
+		if sm == nil || i >= len(sm) || sm[i] == "" {
+			continue
+		}
+		r.Fields["EventName"] = sm[i]
+		r.EventCategory = m.DeviceEventCategory
+		r.Severity = m.Severity
+		r.SendIdentity = m.SendIdentity
+		break
+	}
+}
+
+// match returns the value of the matcher or false if the matcher is not
+// supported.
+func (e *ExtensionEvaluator) match(m *ExtensionMatcher, payload string, parsed *structuredPayload) (string, bool) {
+	switch m.XMLName.Local {
+	case "matcher":
+		re, ok := e.patterns[m.PatternID]
+		if !ok {
+			return "", true
+		}
+		sm := re.FindStringSubmatch(payload)
+		if sm == nil {
+			return "", true
+		}
+		if strings.EqualFold(m.EnableSubstitutions, "true") {
+			return substitution.ReplaceAllStringFunc(m.CaptureGroup, func(ref string) string {
+				i, _ := strconv.Atoi(ref[1:])
+				if i < len(sm) {
+					return sm[i]
+				}
+				return ""
+			}), true
+		}
+		i := 0
+		if m.CaptureGroup != "" {
+			i = orderValue(m.CaptureGroup)
+		}
+		if i >= len(sm) {
+			return "", true
+		}
+		return sm[i], true
+	case "json-matcher":
+		return parsed.json(payload).expand(m.Expression), true
+	case "leef-matcher":
+		return parsed.leef(payload)[m.Expression], true
+	case "cef-matcher":
+		return parsed.cef(payload)[m.Expression], true
+	}
+	return "", false
+}
+
+// structuredPayload caches the payload parsed by the structured matchers.
+type structuredPayload struct {
+	jsonDoc   *jsonPayload
+	leefPairs map[string]string
+	cefPairs  map[string]string
+}
+
+type jsonPayload struct {
+	v interface{}
+}
+
+func (p *structuredPayload) json(payload string) *jsonPayload {
+	if p.jsonDoc == nil {
+		p.jsonDoc = &jsonPayload{}
+		if i := strings.IndexByte(payload, '{'); i >= 0 {
+			dec := json.NewDecoder(strings.NewReader(payload[i:]))
+			dec.UseNumber()
+			_ = dec.Decode(&p.jsonDoc.v)
+		}
+	}
+	return p.jsonDoc
+}
+
+// jsonTemplate matches the paths of the combined json-matcher expressions,
+// e.g. "{/user/domain}\{/user/name}".
+var jsonTemplate = regexp.MustCompile(`\{(/[^{}]*)\}`)
+
+// expand returns the value of the JSON path, e.g. "/user/name" or
+// "/items[0]/id", or the expression with the paths in braces replaced.
+func (j *jsonPayload) expand(expr string) string {
+	if j.v == nil {
+		return ""
+	}
+	if !strings.Contains(expr, "{/") {
+		return j.lookup(expr)
+	}
+	found := false
+	s := jsonTemplate.ReplaceAllStringFunc(expr, func(m string) string {
+		v := j.lookup(m[1 : len(m)-1])
+		if v != "" {
+			found = true
+		}
+		return v
+	})
+	if !found {
+		return ""
+	}
+	return s
+}
+
+var jsonIndex = regexp.MustCompile(`\[(\d+)\]`)
+
+func (j *jsonPayload) lookup(path string) string {
+	v := j.v
+	for _, seg := range strings.Split(strings.TrimPrefix(path, "/"), "/") {
+		name := seg
+		var indexes []int
+		if i := strings.IndexByte(seg, '['); i >= 0 {
+			name = seg[:i]
+			for _, m := range jsonIndex.FindAllStringSubmatch(seg[i:], -1) {
+				n, _ := strconv.Atoi(m[1])
+				indexes = append(indexes, n)
+			}
+		}
+		if name != "" {
+			obj, ok := v.(map[string]interface{})
+			if !ok {
+				return ""
+			}
+			v, ok = obj[name]
+			if !ok {
+				return ""
+			}
+		}
+		for _, n := range indexes {
+			arr, ok := v.([]interface{})
+			if !ok || n >= len(arr) {
+				return ""
+			}
+			v = arr[n]
+		}
+	}
+
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case json.Number:
+		return v.String()
+	case bool:
+		return strconv.FormatBool(v)
+	default:
+		var buf bytes.Buffer
+		enc := json.NewEncoder(&buf)
+		enc.SetEscapeHTML(false)
+		_ = enc.Encode(v)
+		return strings.TrimSpace(buf.String())
+	}
+}
+
+// leef returns the attributes of the LEEF payload and the header fields as
+// $vendor$, $product$, $version$ and $eventid$.
+func (p *structuredPayload) leef(payload string) map[string]string {
+	if p.leefPairs != nil {
+		return p.leefPairs
+	}
+	p.leefPairs = make(map[string]string)
+
+	i := strings.Index(payload, "LEEF:")
+	if i < 0 {
+		return p.leefPairs
+	}
+	header := splitEscaped(payload[i:], '|', 7)
+	if len(header) < 6 {
+		return p.leefPairs
+	}
+	for k, name := range []string{"$leefversion$", "$vendor$", "$product$", "$version$", "$eventid$"} {
+		v := header[k]
+		if k == 0 {
+			v = strings.TrimPrefix(v, "LEEF:")
+		}
+		p.leefPairs[name] = v
+	}
+
+	delimiter := "\t"
+	ext := strings.Join(header[5:], "|")
+	if strings.HasPrefix(header[0], "LEEF:2") && len(header) == 7 {
+		delimiter = leefDelimiter(header[5])
+		ext = header[6]
+	}
+	for k, v := range splitPairs(ext, delimiter) {
+		p.leefPairs[k] = v
+	}
+	return p.leefPairs
+}
+
+// leefDelimiter returns the attribute delimiter of LEEF 2.0, either a
+// character or its hex code like x09 or 0x09.
+func leefDelimiter(s string) string {
+	if s == "" {
+		return "\t"
+	}
+	lower := strings.ToLower(s)
+	if len(s) > 1 && (lower[0] == 'x' || strings.HasPrefix(lower, "0x")) {
+		h := strings.TrimPrefix(strings.TrimPrefix(lower, "0"), "x")
+		if n, err := strconv.ParseUint(h, 16, 32); err == nil {
+			return string(rune(n))
+		}
+	}
+	return s
+}
+
+// cefExtensionKey matches the keys of the CEF extension.
+var cefExtensionKey = regexp.MustCompile(`(?:^|\s)([A-Za-z0-9_.\[\]-]+)=`)
+
+// cef returns the extension fields of the CEF payload and the header fields
+// as $cefversion$, $vendor$, $product$, $version$, $id$, $name$ and
+// $severity$.
+func (p *structuredPayload) cef(payload string) map[string]string {
+	if p.cefPairs != nil {
+		return p.cefPairs
+	}
+	p.cefPairs = make(map[string]string)
+
+	i := strings.Index(payload, "CEF:")
+	if i < 0 {
+		return p.cefPairs
+	}
+	header := splitEscaped(payload[i:], '|', 8)
+	if len(header) < 7 {
+		return p.cefPairs
+	}
+	for k, name := range []string{"$cefversion$", "$vendor$", "$product$", "$version$", "$id$", "$name$", "$severity$"} {
+		v := header[k]
+		if k == 0 {
+			v = strings.TrimPrefix(v, "CEF:")
+		}
+		p.cefPairs[name] = strings.NewReplacer(`\|`, `|`, `\\`, `\`).Replace(v)
+	}
+	if len(header) < 8 {
+		return p.cefPairs
+	}
+
+	ext := header[7]
+	var keys [][]int
+	for _, m := range cefExtensionKey.FindAllStringSubmatchIndex(ext, -1) {
+		if m[2] > 0 && ext[m[2]-1] == '\\' {
+			continue
+		}
+		keys = append(keys, m)
+	}
+	unescape := strings.NewReplacer(`\=`, `=`, `\\`, `\`, `\n`, "\n", `\r`, "\r")
+	for k, m := range keys {
+		end := len(ext)
+		if k+1 < len(keys) {
+			end = keys[k+1][0]
+		}
+		p.cefPairs[ext[m[2]:m[3]]] = unescape.Replace(strings.TrimSpace(ext[m[1]:end]))
+	}
+	return p.cefPairs
+}
+
+// splitEscaped splits s by the separator not escaped by a backslash into at
+// most n parts.
+func splitEscaped(s string, sep byte, n int) []string {
+	var parts []string
+	start := 0
+	for i := 0; i < len(s) && len(parts) < n-1; i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case sep:
+			parts = append(parts, s[start:i])
+			start = i + 1
+		}
+	}
+	return append(parts, s[start:])
+}
+
+// splitPairs splits the key=value pairs of s separated by the delimiter.
+func splitPairs(s, delimiter string) map[string]string {
+	pairs := make(map[string]string)
+	for _, pair := range strings.Split(s, delimiter) {
+		i := strings.IndexByte(pair, '=')
+		if i <= 0 {
+			continue
+		}
+		pairs[strings.TrimSpace(pair[:i])] = strings.TrimSpace(pair[i+1:])
+	}
+	return pairs
+}
@@ -0,0 +1,86 @@
+package qradar
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "update the golden files")
+
+// TestExtensionEvaluatorGolden evaluates testdata/extensions/<name>.xml
+// against the payloads of <name>.payload, one per line, and compares the
+// results with <name>.golden. Run with -update to rewrite the golden files.
+func TestExtensionEvaluatorGolden(t *testing.T) {
+	docs, err := filepath.Glob(filepath.Join("testdata", "extensions", "*.xml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(docs) == 0 {
+		t.Fatal("no extensions in testdata")
+	}
+
+	for _, doc := range docs {
+		name := strings.TrimSuffix(doc, ".xml")
+		t.Run(filepath.Base(name), func(t *testing.T) {
+			data, err := os.ReadFile(doc)
+			if err != nil {
+				t.Fatal(err)
+			}
+			d, err := ParseDeviceExtension(data)
+			if err != nil {
+				t.Fatal(err)
+			}
+			e, err := NewExtensionEvaluator(d)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			payloads, err := os.ReadFile(name + ".payload")
+			if err != nil {
+				t.Fatal(err)
+			}
+			var buf bytes.Buffer
+			err = WriteExtensionResults(&buf, e.EvaluateAll(strings.Split(strings.TrimSuffix(string(payloads), "\n"), "\n")))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			golden := name + ".golden"
+			if *update {
+				err = os.WriteFile(golden, buf.Bytes(), 0o644)
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+			want, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(buf.Bytes(), want) {
+				t.Errorf("results differ from %s:\n%s", golden, buf.Bytes())
+			}
+		})
+	}
+}
+
+func TestLEEFDelimiter(t *testing.T) {
+	tests := []struct {
+		s    string
+		want string
+	}{
+		{"", "\t"},
+		{"^", "^"},
+		{"x09", "\t"},
+		{"0x7C", "|"},
+		{"xyz", "xyz"},
+	}
+	for _, tt := range tests {
+		if got := leefDelimiter(tt.s); got != tt.want {
+			t.Errorf("leefDelimiter(%q) = %q, want %q", tt.s, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package qradar
+
+import (
+	"context"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestLogSourceExtensionData(t *testing.T) {
+	name := "ext"
+	tests := []struct {
+		name    string
+		data    interface{}
+		want    *LogSourceExtension
+		wantErr bool
+	}{
+		{"nil", nil, nil, false},
+		{"pointer", &LogSourceExtension{Name: &name}, &LogSourceExtension{Name: &name}, false},
+		{"value", LogSourceExtension{Name: &name}, &LogSourceExtension{Name: &name}, false},
+		{"map", map[string]interface{}{"name": "ext", "other": 1}, &LogSourceExtension{Name: &name}, false},
+		{"invalid", []int{1}, nil, true},
+	}
+	for _, tt := range tests {
+		got, err := logSourceExtensionData(tt.data)
+		if (err != nil) != tt.wantErr || !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: got %+v, %v, want %+v, wantErr %v", tt.name, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestLogSourceExtensionCreate(t *testing.T) {
+	type part struct {
+		name, fileName, value string
+	}
+	var parts []part
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Allow-Hidden") != "true" {
+			t.Error("no Allow-Hidden header")
+		}
+		_, params, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+		if err != nil {
+			t.Error(err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		mr := multipart.NewReader(r.Body, params["boundary"])
+		for {
+			p, err := mr.NextPart()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				t.Error(err)
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+			bs, _ := io.ReadAll(p)
+			parts = append(parts, part{p.FormName(), p.FileName(), string(bs)})
+		}
+		w.Write([]byte(`{"id":3,"name":"ext"}`))
+	})
+
+	res, err := c.LogSourceExtension.Create(context.Background(), "id,name", map[string]interface{}{
+		"enabled":       true,
+		"xml":           "<device-extension/>",
+		"use_condition": 1,
+		"name":          "ext",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.ID == nil || *res.ID != 3 {
+		t.Errorf("unexpected result %+v", res)
+	}
+
+	want := []part{
+		{"name", "", "ext"},
+		{"use_condition", "", "1"},
+		{"enabled", "", "true"},
+		{"file", logSourceExtensionFile, "<device-extension/>"},
+	}
+	if !reflect.DeepEqual(parts, want) {
+		t.Errorf("got parts %+v, want %+v", parts, want)
+	}
+}
@@ -0,0 +1,268 @@
+package qradar
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ExtensionFields are the field names accepted by the matchers of the Log
+// Source Extension.
+var ExtensionFields = map[string]bool{
+	"EventName":              true,
+	"EventCategory":          true,
+	"DeviceTime":             true,
+	"Protocol":               true,
+	"SourceIp":               true,
+	"SourcePort":             true,
+	"SourceIpv6":             true,
+	"SourceMAC":              true,
+	"SourceIpPreNAT":         true,
+	"SourceIpPostNAT":        true,
+	"SourcePortPreNAT":       true,
+	"SourcePortPostNAT":      true,
+	"DestinationIp":          true,
+	"DestinationPort":        true,
+	"DestinationIpv6":        true,
+	"DestinationMAC":         true,
+	"DestinationIpPreNAT":    true,
+	"DestinationIpPostNAT":   true,
+	"DestinationPortPreNAT":  true,
+	"DestinationPortPostNAT": true,
+	"UserName":               true,
+	"HostName":               true,
+	"GroupName":              true,
+	"NetBIOSName":            true,
+	"ExtraData":              true,
+	"IdentityIp":             true,
+	"IdentityIpv6":           true,
+	"IdentityMac":            true,
+	"IdentityHostName":       true,
+	"IdentityNetBiosName":    true,
+	"IdentityGroupName":      true,
+	"IdentityExtendedField":  true,
+	"Severity":               true,
+	"Credibility":            true,
+	"Relevance":              true,
+}
+
+// ExtensionSendIdentities are the values of the send-identity attribute.
+var ExtensionSendIdentities = map[string]bool{
+	"OverrideAndAlwaysSend": true,
+	"OverrideAndNeverSend":  true,
+	"UseDSMResults":         true,
+	"SendIfAbsent":          true,
+}
+
+// ExtensionIssue represents a problem of the Log Source Extension document.
+// Warnings don't prevent the upload but are likely to be mistakes.
+type ExtensionIssue struct {
+	Pos     Position
+	Warning bool
+	Message string
+}
+
+// Error satisfies the error interface.
+func (i ExtensionIssue) Error() string {
+	level := "error"
+	if i.Warning {
+		level = "warning"
+	}
+	return fmt.Sprintf("%s: %s: %s", i.Pos, level, i.Message)
+}
+
+// ExtensionIssues represents the problems of the document ordered by position.
+type ExtensionIssues []ExtensionIssue
+
+// HasErrors returns true if any of the issues is not a warning.
+func (issues ExtensionIssues) HasErrors() bool {
+	for _, i := range issues {
+		if !i.Warning {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidateDeviceExtension parses and validates the Log Source Extension
+// document. A malformed document is reported as an issue at the line of the
+// syntax error.
+func ValidateDeviceExtension(data []byte) (*DeviceExtension, ExtensionIssues) {
+	d, err := ParseDeviceExtension(data)
+	if err != nil {
+		issue := ExtensionIssue{Message: err.Error()}
+		var syntax *xml.SyntaxError
+		if errors.As(err, &syntax) {
+			issue.Pos.Line = syntax.Line
+			issue.Message = syntax.Msg
+		}
+		return nil, ExtensionIssues{issue}
+	}
+	return d, d.Validate()
+}
+
+// javaRegex matches the constructs of Java regular expressions that are not
+// supported by the regexp package: lookarounds, atomic groups, backreferences,
+// possessive quantifiers, the Java-only escapes such as \Z, \h, \R or \G, and
+// the Java, POSIX and Unicode block or binary property classes such as
+// \p{javaLowerCase}, \p{Alpha} or \p{InGreek}.
+var javaRegex = regexp.MustCompile(`\(\?<?[=!]|\(\?>|\\[1-9]|\\k<|[+*?}]\+|` +
+	`\\[ZhHRXGec]|\\[pP]\{(?:java|Is|In|Lower|Upper|ASCII|Alpha|Digit|Alnum|Punct|Graph|Print|Blank|Cntrl|XDigit|Space)`)
+
+// substitution matches a capture group reference of the capture-group
+// attribute with enabled substitutions, e.g. "\1 \2".
+var substitution = regexp.MustCompile(`\\(\d+)`)
+
+// Validate checks the regular expressions of the patterns, the references of
+// the matchers to the patterns and their capture groups, the field names and
+// the event mappings of the document.
+//
+// Regular expressions are compiled with the regexp package. Expressions with
+// constructs of Java regular expressions it doesn't support are reported as
+// warnings and their capture groups are not checked.
+func (d *DeviceExtension) Validate() ExtensionIssues {
+	var issues ExtensionIssues
+	report := func(pos Position, warning bool, format string, args ...interface{}) {
+		issues = append(issues, ExtensionIssue{Pos: pos, Warning: warning, Message: fmt.Sprintf(format, args...)})
+	}
+
+	// groups is the number of capture groups of the patterns by ID, -1 if
+	// the pattern couldn't be compiled.
+	groups := make(map[string]int)
+	used := make(map[string]bool)
+
+	for _, p := range d.Patterns {
+		if p.ID == "" {
+			report(p.Pos, false, "pattern without id")
+			continue
+		}
+		if _, ok := groups[p.ID]; ok {
+			report(p.Pos, false, "duplicate pattern id %q", p.ID)
+			continue
+		}
+		re, err := regexp.Compile(p.Regex)
+		switch {
+		case err == nil:
+			groups[p.ID] = re.NumSubexp()
+		case javaRegex.MatchString(p.Regex):
+			groups[p.ID] = -1
+			report(p.Pos, true, "pattern %q uses Java regex syntax that is not checked locally: %s", p.ID, err)
+		default:
+			groups[p.ID] = -1
+			report(p.Pos, false, "pattern %q: %s", p.ID, err)
+		}
+	}
+
+	checkGroup := func(pos Position, patternID, group string, substitutions bool) {
+		n, ok := groups[patternID]
+		if !ok || n < 0 {
+			return
+		}
+		var refs []string
+		if substitutions {
+			for _, m := range substitution.FindAllStringSubmatch(group, -1) {
+				refs = append(refs, m[1])
+			}
+			if len(refs) == 0 {
+				report(pos, false, "capture-group %q has no group references", group)
+			}
+		} else {
+			refs = []string{group}
+		}
+		for _, ref := range refs {
+			i, err := strconv.Atoi(ref)
+			if err != nil {
+				report(pos, false, "capture-group %q is not a number", group)
+				continue
+			}
+			if i < 0 || i > n {
+				report(pos, false, "capture-group %d of pattern %q out of range, it has %d groups", i, patternID, n)
+			}
+		}
+	}
+
+	orders := make(map[string]bool)
+	for _, mg := range d.MatchGroups {
+		if mg.Order != "" {
+			if orders[mg.Order] {
+				report(mg.Pos, true, "duplicate match-group order %s", mg.Order)
+			}
+			orders[mg.Order] = true
+		}
+		if len(mg.Matchers) == 0 {
+			report(mg.Pos, false, "match-group without matchers")
+		}
+
+		for _, m := range mg.Matchers {
+			if !ExtensionFields[m.Field] {
+				report(m.Pos, false, "unknown field %q", m.Field)
+			}
+			if m.Field == "DeviceTime" && m.ExtData == "" {
+				report(m.Pos, true, "DeviceTime matcher without the ext-data date format")
+			}
+
+			if m.XMLName.Local != "matcher" {
+				if m.Expression == "" {
+					report(m.Pos, false, "%s of field %q without expression", m.XMLName.Local, m.Field)
+				}
+				continue
+			}
+			if m.PatternID == "" {
+				report(m.Pos, false, "matcher of field %q without pattern-id", m.Field)
+				continue
+			}
+			used[m.PatternID] = true
+			if _, ok := groups[m.PatternID]; !ok {
+				report(m.Pos, false, "matcher of field %q refers to unknown pattern %q", m.Field, m.PatternID)
+				continue
+			}
+			if m.CaptureGroup != "" {
+				checkGroup(m.Pos, m.PatternID, m.CaptureGroup, strings.EqualFold(m.EnableSubstitutions, "true"))
+			}
+		}
+
+		for _, e := range mg.Single {
+			checkEventMatch(report, e.Pos, e.Severity, e.SendIdentity)
+		}
+		for _, e := range mg.Multiple {
+			checkEventMatch(report, e.Pos, e.Severity, e.SendIdentity)
+			if e.PatternID == "" {
+				report(e.Pos, false, "event-match-multiple without pattern-id")
+				continue
+			}
+			used[e.PatternID] = true
+			if _, ok := groups[e.PatternID]; !ok {
+				report(e.Pos, false, "event-match-multiple refers to unknown pattern %q", e.PatternID)
+				continue
+			}
+			if e.CaptureGroupIndex != "" {
+				checkGroup(e.Pos, e.PatternID, e.CaptureGroupIndex, false)
+			}
+		}
+	}
+
+	for _, p := range d.Patterns {
+		if p.ID != "" && !used[p.ID] {
+			report(p.Pos, true, "pattern %q is not used", p.ID)
+		}
+	}
+
+	sort.SliceStable(issues, func(i, j int) bool { return issues[i].Pos.Offset < issues[j].Pos.Offset })
+	return issues
+}
+
+func checkEventMatch(report func(Position, bool, string, ...interface{}), pos Position, severity, sendIdentity string) {
+	if severity != "" {
+		n, err := strconv.Atoi(severity)
+		if err != nil || n < 0 || n > 10 {
+			report(pos, false, "severity %q is not a number from 0 to 10", severity)
+		}
+	}
+	if sendIdentity != "" && !ExtensionSendIdentities[sendIdentity] {
+		report(pos, false, "unknown send-identity %q", sendIdentity)
+	}
+}
@@ -0,0 +1,373 @@
+package qradar
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+)
+
+// DeviceExtensionNamespace is the namespace of the Log Source Extension
+// document.
+const DeviceExtensionNamespace = "event_parsing/device_extension"
+
+// Position represents a position in the document, lines and columns start
+// from one.
+type Position struct {
+	Offset int
+	Line   int
+	Column int
+}
+
+// String satisfies the fmt.Stringer interface. The column is omitted if
+// it's unknown.
+func (p Position) String() string {
+	if p.Column == 0 {
+		return strconv.Itoa(p.Line)
+	}
+	return fmt.Sprintf("%d:%d", p.Line, p.Column)
+}
+
+// DeviceExtension represents the document of Log Source Extension carried by
+// LogSourceExtension.XML.
+//
+// Positions of the elements are set by ParseDeviceExtension and are not
+// marshaled.
+type DeviceExtension struct {
+	// Namespace defaults to DeviceExtensionNamespace.
+	Namespace   string
+	Attrs       []xml.Attr
+	Patterns    []ExtensionPattern
+	MatchGroups []MatchGroup
+	Extra       []XMLElement
+}
+
+// ExtensionPattern represents a regular expression of the extension
+// referenced by the matchers by ID.
+type ExtensionPattern struct {
+	ID              string     `xml:"id,attr"`
+	CaseInsensitive string     `xml:"case-insensitive,attr,omitempty"`
+	TrimWhitespace  string     `xml:"trim-whitespace,attr,omitempty"`
+	Attrs           []xml.Attr `xml:",any,attr"`
+	Regex           string     `xml:",cdata"`
+	Pos             Position   `xml:"-"`
+}
+
+// MatchGroup represents a group of matchers applied to the payload and the
+// mapping of the matched events.
+type MatchGroup struct {
+	Order       string
+	Description string
+	Attrs       []xml.Attr
+	Matchers    []ExtensionMatcher
+	Single      []EventMatchSingle
+	Multiple    []EventMatchMultiple
+	Extra       []XMLElement
+	Pos         Position
+}
+
+// ExtensionMatcher represents a matcher of the field: matcher for the regular
+// expressions or json-matcher, leef-matcher, cef-matcher and others with the
+// expression by the element name.
+type ExtensionMatcher struct {
+	XMLName             xml.Name
+	Field               string     `xml:"field,attr"`
+	Order               string     `xml:"order,attr,omitempty"`
+	PatternID           string     `xml:"pattern-id,attr,omitempty"`
+	CaptureGroup        string     `xml:"capture-group,attr,omitempty"`
+	EnableSubstitutions string     `xml:"enable-substitutions,attr,omitempty"`
+	ExtData             string     `xml:"ext-data,attr,omitempty"`
+	Expression          string     `xml:"expression,attr,omitempty"`
+	Attrs               []xml.Attr `xml:",any,attr"`
+	Content             string     `xml:",innerxml"`
+	Pos                 Position   `xml:"-"`
+}
+
+// EventMatchSingle represents the mapping of all events of the match group
+// to a single QID.
+type EventMatchSingle struct {
+	EventName           string     `xml:"event-name,attr,omitempty"`
+	DeviceEventCategory string     `xml:"device-event-category,attr,omitempty"`
+	Severity            string     `xml:"severity,attr,omitempty"`
+	SendIdentity        string     `xml:"send-identity,attr,omitempty"`
+	Attrs               []xml.Attr `xml:",any,attr"`
+	Pos                 Position   `xml:"-"`
+}
+
+// EventMatchMultiple represents the mapping of the events of the match group
+// by the event name captured by the pattern.
+type EventMatchMultiple struct {
+	PatternID           string     `xml:"pattern-id,attr,omitempty"`
+	CaptureGroupIndex   string     `xml:"capture-group-index,attr,omitempty"`
+	DeviceEventCategory string     `xml:"device-event-category,attr,omitempty"`
+	Severity            string     `xml:"severity,attr,omitempty"`
+	SendIdentity        string     `xml:"send-identity,attr,omitempty"`
+	Attrs               []xml.Attr `xml:",any,attr"`
+	Pos                 Position   `xml:"-"`
+}
+
+// lineIndex converts the offsets of the document to positions.
+type lineIndex []int
+
+func newLineIndex(data []byte) lineIndex {
+	idx := lineIndex{0}
+	for i, b := range data {
+		if b == '\n' {
+			idx = append(idx, i+1)
+		}
+	}
+	return idx
+}
+
+func (idx lineIndex) position(offset int64) Position {
+	off := int(offset)
+	line := sort.Search(len(idx), func(i int) bool { return idx[i] > off })
+	return Position{Offset: off, Line: line, Column: off - idx[line-1] + 1}
+}
+
+// ParseDeviceExtension parses the Log Source Extension document.
+func ParseDeviceExtension(data []byte) (*DeviceExtension, error) {
+	idx := newLineIndex(data)
+	dec := xml.NewDecoder(bytes.NewReader(data))
+
+	var d *DeviceExtension
+	for {
+		off := dec.InputOffset()
+		tok, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		start, ok := tok.(xml.StartElement)
+		if !ok {
+			continue
+		}
+
+		if d == nil {
+			if start.Name.Local != "device-extension" {
+				return nil, fmt.Errorf("%s: expected device-extension, got %s", idx.position(off), start.Name.Local)
+			}
+			d = &DeviceExtension{Namespace: start.Name.Space, Attrs: withoutNamespaces(start.Attr)}
+			continue
+		}
+
+		pos := idx.position(off)
+		switch start.Name.Local {
+		case "pattern":
+			var p ExtensionPattern
+			err = dec.DecodeElement(&p, &start)
+			p.Pos = pos
+			p.Attrs = withoutNamespaces(p.Attrs)
+			d.Patterns = append(d.Patterns, p)
+		case "match-group":
+			var mg *MatchGroup
+			mg, err = decodeMatchGroup(dec, start, idx)
+			if err == nil {
+				mg.Pos = pos
+				d.MatchGroups = append(d.MatchGroups, *mg)
+			}
+		default:
+			var e XMLElement
+			err = dec.DecodeElement(&e, &start)
+			d.Extra = append(d.Extra, e)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", pos, err)
+		}
+	}
+
+	if d == nil {
+		return nil, fmt.Errorf("no device-extension element")
+	}
+	return d, nil
+}
+
+func decodeMatchGroup(dec *xml.Decoder, start xml.StartElement, idx lineIndex) (*MatchGroup, error) {
+	mg := &MatchGroup{}
+	for _, a := range withoutNamespaces(start.Attr) {
+		switch a.Name.Local {
+		case "order":
+			mg.Order = a.Value
+		case "description":
+			mg.Description = a.Value
+		default:
+			mg.Attrs = append(mg.Attrs, a)
+		}
+	}
+
+	for {
+		off := dec.InputOffset()
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		switch t := tok.(type) {
+		case xml.EndElement:
+			return mg, nil
+		case xml.StartElement:
+			pos := idx.position(off)
+			switch {
+			case t.Name.Local == "event-match-single":
+				var e EventMatchSingle
+				err = dec.DecodeElement(&e, &t)
+				e.Pos = pos
+				e.Attrs = withoutNamespaces(e.Attrs)
+				mg.Single = append(mg.Single, e)
+			case t.Name.Local == "event-match-multiple":
+				var e EventMatchMultiple
+				err = dec.DecodeElement(&e, &t)
+				e.Pos = pos
+				e.Attrs = withoutNamespaces(e.Attrs)
+				mg.Multiple = append(mg.Multiple, e)
+			case t.Name.Local == "matcher" || len(t.Name.Local) > 8 && t.Name.Local[len(t.Name.Local)-8:] == "-matcher":
+				var m ExtensionMatcher
+				err = dec.DecodeElement(&m, &t)
+				m.XMLName = xml.Name{Local: t.Name.Local}
+				m.Pos = pos
+				m.Attrs = withoutNamespaces(m.Attrs)
+				mg.Matchers = append(mg.Matchers, m)
+			default:
+				var e XMLElement
+				err = dec.DecodeElement(&e, &t)
+				mg.Extra = append(mg.Extra, e)
+			}
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", pos, err)
+			}
+		}
+	}
+}
+
+// withoutNamespaces drops the namespace declarations of the attributes, the
+// namespaces are restored on marshaling.
+func withoutNamespaces(attrs []xml.Attr) []xml.Attr {
+	var result []xml.Attr
+	for _, a := range attrs {
+		if a.Name.Local == "xmlns" || a.Name.Space == "xmlns" {
+			continue
+		}
+		result = append(result, a)
+	}
+	return result
+}
+
+// noNamespace resets the default namespace of the child elements, as
+// expected by QRadar.
+var noNamespace = xml.Attr{Name: xml.Name{Local: "xmlns"}, Value: ""}
+
+// Marshal returns the document as indented XML with the XML header.
+func (d *DeviceExtension) Marshal() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteString(xml.Header)
+	enc := xml.NewEncoder(&buf)
+	enc.Indent("", "  ")
+
+	ns := d.Namespace
+	if ns == "" {
+		ns = DeviceExtensionNamespace
+	}
+	root := xml.StartElement{
+		Name: xml.Name{Local: "device-extension"},
+		Attr: append([]xml.Attr{{Name: xml.Name{Local: "xmlns"}, Value: ns}}, d.Attrs...),
+	}
+	err := enc.EncodeToken(root)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range d.Patterns {
+		err = enc.EncodeElement(&d.Patterns[i], xml.StartElement{Name: xml.Name{Local: "pattern"}, Attr: []xml.Attr{noNamespace}})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for i := range d.MatchGroups {
+		err = d.MatchGroups[i].encode(enc)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for i := range d.Extra {
+		err = enc.Encode(&d.Extra[i])
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err = enc.EncodeToken(root.End())
+	if err != nil {
+		return nil, err
+	}
+	err = enc.Flush()
+	if err != nil {
+		return nil, err
+	}
+	buf.WriteByte('\n')
+	return buf.Bytes(), nil
+}
+
+func (mg *MatchGroup) encode(enc *xml.Encoder) error {
+	start := xml.StartElement{Name: xml.Name{Local: "match-group"}, Attr: []xml.Attr{noNamespace}}
+	if mg.Order != "" {
+		start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: "order"}, Value: mg.Order})
+	}
+	if mg.Description != "" {
+		start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: "description"}, Value: mg.Description})
+	}
+	start.Attr = append(start.Attr, mg.Attrs...)
+
+	err := enc.EncodeToken(start)
+	if err != nil {
+		return err
+	}
+	for i := range mg.Matchers {
+		err = enc.Encode(&mg.Matchers[i])
+		if err != nil {
+			return err
+		}
+	}
+	for i := range mg.Single {
+		err = enc.EncodeElement(&mg.Single[i], xml.StartElement{Name: xml.Name{Local: "event-match-single"}})
+		if err != nil {
+			return err
+		}
+	}
+	for i := range mg.Multiple {
+		err = enc.EncodeElement(&mg.Multiple[i], xml.StartElement{Name: xml.Name{Local: "event-match-multiple"}})
+		if err != nil {
+			return err
+		}
+	}
+	for i := range mg.Extra {
+		err = enc.Encode(&mg.Extra[i])
+		if err != nil {
+			return err
+		}
+	}
+	return enc.EncodeToken(start.End())
+}
+
+// Document parses the XML of the Log Source Extension.
+func (e *LogSourceExtension) Document() (*DeviceExtension, error) {
+	if e.XML == nil {
+		return nil, fmt.Errorf("log source extension has no xml")
+	}
+	return ParseDeviceExtension([]byte(*e.XML))
+}
+
+// SetDocument replaces the XML of the Log Source Extension with the document.
+func (e *LogSourceExtension) SetDocument(d *DeviceExtension) error {
+	bs, err := d.Marshal()
+	if err != nil {
+		return err
+	}
+	s := string(bs)
+	e.XML = &s
+	return nil
+}
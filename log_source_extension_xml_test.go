@@ -0,0 +1,183 @@
+package qradar
+
+import (
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+const deviceExtensionXML = `<?xml version="1.0" encoding="UTF-8"?>
+<device-extension xmlns="event_parsing/device_extension">
+  <pattern id="EventName" case-insensitive="true" xmlns=""><![CDATA[action=(\w+)]]></pattern>
+  <pattern id="SourceIp" xmlns=""><![CDATA[src=(\d+\.\d+\.\d+\.\d+)]]></pattern>
+  <match-group order="1" description="Firewall" xmlns="">
+    <matcher field="EventName" order="1" pattern-id="EventName" capture-group="1"/>
+    <matcher field="SourceIp" order="1" pattern-id="SourceIp" capture-group="1"/>
+    <event-match-multiple pattern-id="EventName" capture-group-index="1" device-event-category="Firewall" severity="5" send-identity="OverrideAndNeverSend"/>
+  </match-group>
+</device-extension>
+`
+
+func TestParseDeviceExtension(t *testing.T) {
+	d, err := ParseDeviceExtension([]byte(deviceExtensionXML))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d.Namespace != DeviceExtensionNamespace || len(d.Patterns) != 2 || len(d.MatchGroups) != 1 {
+		t.Fatalf("unexpected document %+v", d)
+	}
+	if p := d.Patterns[0]; p.Regex != `action=(\w+)` || p.CaseInsensitive != "true" || p.Pos.String() != "3:3" {
+		t.Errorf("unexpected pattern %+v at %s", p, p.Pos)
+	}
+	mg := d.MatchGroups[0]
+	if mg.Order != "1" || mg.Description != "Firewall" || len(mg.Matchers) != 2 || len(mg.Multiple) != 1 {
+		t.Errorf("unexpected match group %+v", mg)
+	}
+	if m := mg.Matchers[1]; m.Field != "SourceIp" || m.Pos.Line != 7 {
+		t.Errorf("unexpected matcher %+v", m)
+	}
+
+	bs, err := d.Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	again, err := ParseDeviceExtension(bs)
+	if err != nil {
+		t.Fatalf("%s\n%s", err, bs)
+	}
+	clearPositions(d)
+	clearPositions(again)
+	if !reflect.DeepEqual(d, again) {
+		t.Errorf("round trip changed the document:\n%s", bs)
+	}
+}
+
+func clearPositions(d *DeviceExtension) {
+	for i := range d.Patterns {
+		d.Patterns[i].Pos = Position{}
+	}
+	for i := range d.MatchGroups {
+		mg := &d.MatchGroups[i]
+		mg.Pos = Position{}
+		for j := range mg.Matchers {
+			mg.Matchers[j].Pos = Position{}
+		}
+		for j := range mg.Single {
+			mg.Single[j].Pos = Position{}
+		}
+		for j := range mg.Multiple {
+			mg.Multiple[j].Pos = Position{}
+		}
+	}
+}
+
+func TestValidateDeviceExtension(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want []string
+	}{
+		{
+			name: "valid",
+			body: `<pattern id="p"><![CDATA[a(b)]]></pattern>` +
+				`<match-group><matcher field="EventName" pattern-id="p" capture-group="1"/></match-group>`,
+		},
+		{
+			name: "references",
+			body: `<pattern id="p"><![CDATA[a(b)]]></pattern><pattern id="unused"><![CDATA[x]]></pattern>` +
+				`<match-group><matcher field="Bogus" pattern-id="p" capture-group="2"/>` +
+				`<matcher field="UserName" pattern-id="missing"/></match-group>`,
+			want: []string{
+				`warning: pattern "unused" is not used`,
+				`error: unknown field "Bogus"`,
+				`error: capture-group 2 of pattern "p" out of range, it has 1 groups`,
+				`error: matcher of field "UserName" refers to unknown pattern "missing"`,
+			},
+		},
+		{
+			name: "substitutions",
+			body: `<pattern id="p"><![CDATA[(a)(b)]]></pattern>` +
+				`<match-group><matcher field="UserName" pattern-id="p" capture-group="\1-\3" enable-substitutions="true"/></match-group>`,
+			want: []string{`error: capture-group 3 of pattern "p" out of range, it has 2 groups`},
+		},
+		{
+			name: "java regex",
+			body: `<pattern id="p"><![CDATA[(?<=a)b]]></pattern>` +
+				`<match-group><matcher field="UserName" pattern-id="p" capture-group="4"/></match-group>`,
+			want: []string{`warning: pattern "p" uses Java regex syntax that is not checked locally`},
+		},
+		{
+			name: "java escapes and classes",
+			body: `<pattern id="p"><![CDATA[\h*(\p{javaLowerCase}+)\Z]]></pattern>` +
+				`<match-group><matcher field="UserName" pattern-id="p" capture-group="1"/></match-group>`,
+			want: []string{`warning: pattern "p" uses Java regex syntax that is not checked locally`},
+		},
+		{
+			name: "invalid regex",
+			body: `<pattern id="p"><![CDATA[a(b]]></pattern>` +
+				`<match-group><matcher field="UserName" pattern-id="p"/></match-group>`,
+			want: []string{`error: pattern "p": error parsing regexp: missing closing )`},
+		},
+		{
+			name: "event match",
+			body: `<pattern id="p"><![CDATA[(a)]]></pattern>` +
+				`<match-group><json-matcher field="DeviceTime"/>` +
+				`<event-match-single severity="11" send-identity="Always"/>` +
+				`<event-match-multiple pattern-id="p"/></match-group>`,
+			want: []string{
+				`error: json-matcher of field "DeviceTime" without expression`,
+				`warning: DeviceTime matcher without the ext-data date format`,
+				`error: severity "11" is not a number from 0 to 10`,
+				`error: unknown send-identity "Always"`,
+			},
+		},
+		{
+			name: "malformed",
+			body: "\n<pattern>",
+			want: []string{"2: error: element <pattern> closed by </device-extension>"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc := `<device-extension xmlns="event_parsing/device_extension">` + tt.body + `</device-extension>`
+			_, issues := ValidateDeviceExtension([]byte(doc))
+			if len(issues) != len(tt.want) {
+				t.Fatalf("got issues %v, want %d", issues, len(tt.want))
+			}
+			got := make([]string, len(issues))
+			for i, issue := range issues {
+				got[i] = issue.Error()
+			}
+			for _, want := range tt.want {
+				found := false
+				for _, g := range got {
+					found = found || strings.Contains(g, want)
+				}
+				if !found {
+					t.Errorf("no issue %q in %q", want, got)
+				}
+			}
+		})
+	}
+}
+
+func TestJavaRegex(t *testing.T) {
+	for _, s := range []string{
+		`(?<=a)b`, `(?>a)`, `(a)\1`, `a*+`, `(?<n>a)\k<n>`,
+		`a\Z`, `\h+`, `\H`, `\R`, `\X`, `\Gab`, `\e`, `\cA`,
+		`\p{javaLowerCase}`, `\p{IsAlphabetic}`, `\p{InGreek}`, `\p{Lower}`, `\P{Alpha}`,
+	} {
+		if _, err := regexp.Compile(s); err == nil {
+			t.Errorf("%s compiles, want an error", s)
+		}
+		if !javaRegex.MatchString(s) {
+			t.Errorf("%s is not recognized as Java regex", s)
+		}
+	}
+	for _, s := range []string{`a(b`, `[z-a]`, `\p{Bogus}`, `\y`} {
+		if javaRegex.MatchString(s) {
+			t.Errorf("%s is recognized as Java regex", s)
+		}
+	}
+}
@@ -65,3 +65,31 @@ func (c *LogSourceGroupService) Create(ctx context.Context, fields string, data
 	}
 	return &result, nil
 }
+
+// UpdateByID updates Log Source Group of the current QRadar installation by ID.
+func (c *LogSourceGroupService) UpdateByID(ctx context.Context, fields string, id int, data interface{}) (*LogSourceGroup, error) {
+	req, err := c.client.requestHelp(http.MethodPost, logSourceGroupAPIPrefix, fields, "", 0, 0, &id, data)
+	if err != nil {
+		return nil, err
+	}
+	var result LogSourceGroup
+	_, err = c.client.Do(ctx, req, &result)
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// DeleteByID deletes Log Source Group of the current QRadar installation by ID.
+func (c *LogSourceGroupService) DeleteByID(ctx context.Context, fields string, id int) (*DeleteTask, error) {
+	req, err := c.client.requestHelp(http.MethodDelete, logSourceGroupAPIPrefix, fields, "", 0, 0, &id, nil)
+	if err != nil {
+		return nil, err
+	}
+	var result DeleteTask
+	_, err = c.client.Do(ctx, req, &result)
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
@@ -0,0 +1,181 @@
+package qradar
+
+import (
+	"context"
+	"fmt"
+)
+
+// logSourceGroupFilterChunk is a number of log source IDs per request of the
+// membership changes, so the filter stays within the URL length limits.
+const logSourceGroupFilterChunk = 100
+
+// logSourceGroupPoll is a number of seconds between the polls of the bulk
+// update of the membership changes.
+var logSourceGroupPoll = 2
+
+// LogSourceGroupNode represents a Log Source Group within the hierarchy.
+type LogSourceGroupNode = GroupNode[LogSourceGroup]
+
+// LogSourceGroupTree represents the hierarchy of the Log Source Groups.
+type LogSourceGroupTree = GroupTree[LogSourceGroup]
+
+// NewLogSourceGroupTree builds the hierarchy of the groups from their
+// parent_id and child_group_ids. Groups with an unknown parent become roots.
+// Children are ordered by name.
+func NewLogSourceGroupTree(groups []LogSourceGroup) *LogSourceGroupTree {
+	return newGroupTree(groups)
+}
+
+// Tree returns the hierarchy of the Log Source Groups.
+func (c *LogSourceGroupService) Tree(ctx context.Context) (*LogSourceGroupTree, error) {
+	groups, err := c.Get(ctx, "", "", 0, 0)
+	if err != nil {
+		return nil, err
+	}
+	return NewLogSourceGroupTree(groups), nil
+}
+
+// GetByPath returns Log Source Group of the current QRadar installation by
+// path, e.g. "Site A/Firewalls", or nil if it doesn't exist.
+func (c *LogSourceGroupService) GetByPath(ctx context.Context, path string) (*LogSourceGroup, error) {
+	t, err := c.Tree(ctx)
+	if err != nil {
+		return nil, err
+	}
+	n := t.Lookup(path)
+	if n == nil {
+		return nil, nil
+	}
+	return &n.Group, nil
+}
+
+// EnsurePath returns Log Source Group by path creating the missing groups
+// along the path.
+func (c *LogSourceGroupService) EnsurePath(ctx context.Context, path string) (*LogSourceGroup, error) {
+	names := splitGroupPath(path)
+	if len(names) == 0 {
+		return nil, fmt.Errorf("empty log source group path")
+	}
+	t, err := c.Tree(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var parent *LogSourceGroup
+	nodes := t.Roots
+	for i, name := range names {
+		found := childByName(nodes, name)
+		if found != nil {
+			parent = &found.Group
+			nodes = found.Children
+			continue
+		}
+
+		data := &LogSourceGroup{Name: &names[i]}
+		if parent != nil {
+			data.ParentID = parent.ID
+		}
+		parent, err = c.Create(ctx, "", data)
+		if err != nil {
+			return nil, err
+		}
+		nodes = nil
+	}
+	return parent, nil
+}
+
+// AddLogSources adds the log sources by IDs to the group by ID. Only the log
+// sources that are not in the group yet are updated.
+func (c *LogSourceGroupService) AddLogSources(ctx context.Context, id int, logSourceIDs []int) error {
+	return c.updateMembership(ctx, logSourceIDs, func(groupIDs []int) ([]int, bool) {
+		for _, g := range groupIDs {
+			if g == id {
+				return groupIDs, false
+			}
+		}
+		return append(groupIDs, id), true
+	})
+}
+
+// RemoveLogSources removes the log sources by IDs from the group by ID. Only
+// the log sources that are in the group are updated.
+func (c *LogSourceGroupService) RemoveLogSources(ctx context.Context, id int, logSourceIDs []int) error {
+	return c.updateMembership(ctx, logSourceIDs, func(groupIDs []int) ([]int, bool) {
+		result := make([]int, 0, len(groupIDs))
+		for _, g := range groupIDs {
+			if g != id {
+				result = append(result, g)
+			}
+		}
+		return result, len(result) != len(groupIDs)
+	})
+}
+
+// logSourceMembership is a Log Source of the bulk update of the membership,
+// unlike LogSource it keeps the empty group_ids of the last group removal.
+type logSourceMembership struct {
+	ID       int   `json:"id"`
+	GroupIDs []int `json:"group_ids"`
+}
+
+// updateMembership updates the group_ids of the log sources by IDs with the
+// result of change if it reports a change. The changed log sources of every
+// chunk are updated with a single bulk update.
+func (c *LogSourceGroupService) updateMembership(ctx context.Context, logSourceIDs []int, change func(groupIDs []int) ([]int, bool)) error {
+	for start := 0; start < len(logSourceIDs); start += logSourceGroupFilterChunk {
+		end := start + logSourceGroupFilterChunk
+		if end > len(logSourceIDs) {
+			end = len(logSourceIDs)
+		}
+		chunk := logSourceIDs[start:end]
+		sources, err := c.client.LogSource.Get(ctx, "id,group_ids", idsFilter(chunk), 0, 0)
+		if err != nil {
+			return err
+		}
+		found := make(map[int]bool, len(sources))
+		for _, s := range sources {
+			if s.ID != nil {
+				found[*s.ID] = true
+			}
+		}
+		for _, id := range chunk {
+			if !found[id] {
+				return fmt.Errorf("log source %d not found", id)
+			}
+		}
+
+		var updates []logSourceMembership
+		for _, s := range sources {
+			if s.ID == nil {
+				continue
+			}
+			groupIDs, changed := change(append([]int(nil), s.GroupIDs...))
+			if !changed {
+				continue
+			}
+			if groupIDs == nil {
+				groupIDs = []int{}
+			}
+			updates = append(updates, logSourceMembership{*s.ID, groupIDs})
+		}
+		if len(updates) == 0 {
+			continue
+		}
+
+		task, err := c.client.LogSource.bulk(ctx, logSourceBulkUpdate, "", updates)
+		if err != nil {
+			return err
+		}
+		if task.ID == nil {
+			return fmt.Errorf("bulk update of the log sources has no task id")
+		}
+		task, err = c.client.LogSource.WaitForBulkUpdate(ctx, *task.ID, logSourceGroupPoll)
+		if err != nil {
+			return err
+		}
+		if TaskStatus(*task.Status) != TaskStatusCompleted {
+			return fmt.Errorf("bulk update task %d of the log sources: %s %s", *task.ID, *task.Status, stringValue(task.Message))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,105 @@
+package qradar
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestLogSourceGroupTreeLookup(t *testing.T) {
+	tree := NewLogSourceGroupTree([]LogSourceGroup{
+		{ID: intPtr(1), Name: strPtr("Site A"), ChildGroupIDs: []int{2}},
+		{ID: intPtr(2), Name: strPtr("Firewalls")},
+		{ID: intPtr(3), Name: strPtr("Firewalls"), ParentID: intPtr(4)},
+		{ID: intPtr(4), Name: strPtr("Site B")},
+	})
+
+	tests := []struct {
+		path string
+		want int
+	}{
+		{"Site A/Firewalls", 2},
+		{" Site B / Firewalls ", 3},
+		{"Site B", 4},
+		{"Firewalls", 0},
+		{"Site A/Routers", 0},
+		{"", 0},
+	}
+	for _, tt := range tests {
+		n := tree.Lookup(tt.path)
+		got := 0
+		if n != nil {
+			got = *n.Group.ID
+		}
+		if got != tt.want {
+			t.Errorf("Lookup(%q) = %d, want %d", tt.path, got, tt.want)
+		}
+	}
+	if p := tree.Node(3).Path(); p != "Site B/Firewalls" {
+		t.Errorf("Path() = %q", p)
+	}
+}
+
+func TestLogSourceGroupMembership(t *testing.T) {
+	defer func(n int) { logSourceGroupPoll = n }(logSourceGroupPoll)
+	logSourceGroupPoll = 1
+
+	tests := []struct {
+		name   string
+		remove bool
+		status string
+		want   string
+		err    string
+	}{
+		{"add", false, "COMPLETED", `[{"id":1,"group_ids":[7,5]}]`, ""},
+		{"remove", true, "COMPLETED", `[{"id":2,"group_ids":[]}]`, ""},
+		{"failed", false, "EXCEPTION", `[{"id":1,"group_ids":[7,5]}]`, "EXCEPTION"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var body string
+			c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+				switch r.URL.Path {
+				case "/api/config/event_sources/log_source_management/log_sources":
+					w.Write([]byte(`[{"id":1,"group_ids":[7]},{"id":2,"group_ids":[5]}]`))
+				case "/api/config/event_sources/log_source_management/log_sources/bulk_update":
+					b, _ := io.ReadAll(r.Body)
+					body = strings.TrimSpace(string(b))
+					w.Write([]byte(`{"id":9,"status":"QUEUED"}`))
+				case "/api/config/event_sources/log_source_management/log_sources/bulk_update_tasks/9":
+					w.Write([]byte(`{"id":9,"status":"` + tt.status + `"}`))
+				default:
+					http.NotFound(w, r)
+				}
+			})
+
+			var err error
+			if tt.remove {
+				err = c.LogSourceGroup.RemoveLogSources(context.Background(), 5, []int{1, 2})
+			} else {
+				err = c.LogSourceGroup.AddLogSources(context.Background(), 5, []int{1, 2})
+			}
+			if (err != nil) != (tt.err != "") || err != nil && !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("error %v, want %q", err, tt.err)
+			}
+			if body != tt.want {
+				t.Errorf("bulk update %s, want %s", body, tt.want)
+			}
+		})
+	}
+}
+
+func TestLogSourceGroupMembershipNotFound(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			t.Errorf("unexpected %s %s", r.Method, r.URL.Path)
+		}
+		w.Write([]byte(`[{"id":1,"group_ids":[]}]`))
+	})
+	err := c.LogSourceGroup.AddLogSources(context.Background(), 5, []int{1, 3})
+	if err == nil || err.Error() != "log source 3 not found" {
+		t.Errorf("AddLogSources() = %v", err)
+	}
+}
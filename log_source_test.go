@@ -0,0 +1,118 @@
+package qradar
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestLogSourceStatus(t *testing.T) {
+	var ls LogSource
+	err := json.Unmarshal([]byte(`{"id":1,"status":{"status":"ERROR","messages":[{"text":"connection refused"}]}}`), &ls)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ls.Status == nil || ls.Status.Status == nil || *ls.Status.Status != "ERROR" ||
+		len(ls.Status.Messages) != 1 || *ls.Status.Messages[0].Text != "connection refused" {
+		t.Errorf("unexpected status %+v", ls.Status)
+	}
+}
+
+func TestLogSourceBulk(t *testing.T) {
+	tests := []struct {
+		name     string
+		call     func(c *Client) (*LogSourceBulkTask, error)
+		wantPath string
+		wantBody string
+		wantErr  bool
+	}{
+		{
+			name: "add",
+			call: func(c *Client) (*LogSourceBulkTask, error) {
+				name := "fw01"
+				return c.LogSource.BulkAdd(context.Background(), "", []LogSource{{Name: &name}})
+			},
+			wantPath: "/api/config/event_sources/log_source_management/log_sources/bulk_add",
+			wantBody: `[{"name":"fw01"}]`,
+		},
+		{
+			name: "update",
+			call: func(c *Client) (*LogSourceBulkTask, error) {
+				id := 7
+				return c.LogSource.BulkUpdate(context.Background(), "", []LogSource{{ID: &id, GroupIDs: []int{1}}})
+			},
+			wantPath: "/api/config/event_sources/log_source_management/log_sources/bulk_update",
+			wantBody: `[{"group_ids":[1],"id":7}]`,
+		},
+		{
+			name: "update without id",
+			call: func(c *Client) (*LogSourceBulkTask, error) {
+				return c.LogSource.BulkUpdate(context.Background(), "", []LogSource{{GroupIDs: []int{1}}})
+			},
+			wantErr: true,
+		},
+		{
+			name: "delete",
+			call: func(c *Client) (*LogSourceBulkTask, error) {
+				return c.LogSource.BulkDelete(context.Background(), "", []int{7, 8})
+			},
+			wantPath: "/api/config/event_sources/log_source_management/log_sources/bulk_delete",
+			wantBody: `[7,8]`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var path, body string
+			c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+				bs, _ := io.ReadAll(r.Body)
+				path, body = r.URL.Path, strings.TrimSpace(string(bs))
+				w.Write([]byte(`{"id":42,"status":"QUEUED"}`))
+			})
+
+			task, err := tt.call(c)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("got error %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if path != tt.wantPath || body != tt.wantBody {
+				t.Errorf("got %s %s, want %s %s", path, body, tt.wantPath, tt.wantBody)
+			}
+			if task.ID == nil || *task.ID != 42 {
+				t.Errorf("unexpected task %+v", task)
+			}
+		})
+	}
+}
+
+func TestLogSourceWaitForBulkUpdate(t *testing.T) {
+	var mu sync.Mutex
+	statuses := []string{"PROCESSING", "COMPLETED"}
+	var paths []string
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		paths = append(paths, r.URL.Path)
+		status := statuses[0]
+		if len(statuses) > 1 {
+			statuses = statuses[1:]
+		}
+		w.Write([]byte(`{"id":42,"status":"` + status + `"}`))
+	})
+
+	task, err := c.LogSource.WaitForBulkUpdate(context.Background(), 42, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if task.Status == nil || *task.Status != "COMPLETED" {
+		t.Errorf("got task %+v, want COMPLETED", task)
+	}
+	if len(paths) != 2 || paths[0] != "/api/config/event_sources/log_source_management/log_sources/bulk_update_tasks/42" {
+		t.Errorf("unexpected polls %v", paths)
+	}
+}
@@ -103,7 +103,7 @@ func (c *LogSourceTypeService) DeleteByID(ctx context.Context, fields string, id
 
 // GetByName returns Log Source Type of the current QRadar installation by Name.
 func (c *LogSourceTypeService) GetByName(ctx context.Context, fields string, name string) (*LogSourceType, error) {
-	req, err := c.client.requestHelp(http.MethodGet, logSourceTypeAPIPrefix, fields, fmt.Sprintf("name=\"%s\"", name), 0, 0, nil, nil)
+	req, err := c.client.requestHelp(http.MethodGet, logSourceTypeAPIPrefix, fields, "name="+filterString(name), 0, 0, nil, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,48 @@
+package qradar
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+)
+
+// OffenseNote represents a note of the Offense.
+type OffenseNote struct {
+	ID         *int    `json:"id,omitempty"`
+	CreateTime *int    `json:"create_time,omitempty"`
+	Username   *string `json:"username,omitempty"`
+	NoteText   *string `json:"note_text,omitempty"`
+}
+
+// Notes returns Notes of the Offense by ID.
+func (c *OffenseService) Notes(ctx context.Context, fields, filter string, id, from, to int) ([]OffenseNote, error) {
+	req, err := c.client.requestHelp(http.MethodGet, fmt.Sprintf("%s/%d/notes", offensesAPIPrefix, id), fields, filter, from, to, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	var result []OffenseNote
+	_, err = c.client.Do(ctx, req, &result)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// CreateNote creates a Note on the Offense by ID.
+func (c *OffenseService) CreateNote(ctx context.Context, fields string, id int, text string) (*OffenseNote, error) {
+	req, err := c.client.requestHelp(http.MethodPost, fmt.Sprintf("%s/%d/notes", offensesAPIPrefix, id), fields, "", 0, 0, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	q := req.URL.Query()
+	q.Add("note_text", text)
+	req.URL.RawQuery = q.Encode()
+
+	var result OffenseNote
+	_, err = c.client.Do(ctx, req, &result)
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
@@ -0,0 +1,314 @@
+package qradar
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	htmltemplate "html/template"
+	"io"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// DefaultOffenseReportEvents is a default number of the sample events in the
+// Offense report.
+var DefaultOffenseReportEvents = 20
+
+// OffenseReport represents a self-contained bundle of the Offense details
+// suitable for the incident handover.
+type OffenseReport struct {
+	GeneratedAt               time.Time                 `json:"generated_at"`
+	Offense                   Offense                   `json:"offense"`
+	Notes                     []OffenseNote             `json:"notes,omitempty"`
+	SourceAddresses           []SourceAddress           `json:"source_addresses,omitempty"`
+	LocalDestinationAddresses []LocalDestinationAddress `json:"local_destination_addresses,omitempty"`
+	Rules                     []Rule                    `json:"rules,omitempty"`
+	EventColumns              []string                  `json:"event_columns,omitempty"`
+	Events                    []Event                   `json:"events,omitempty"`
+}
+
+// OffenseReportOptions represents options of the Offense report.
+type OffenseReportOptions struct {
+	// Events are options of the sample events search, Limit defaults to
+	// DefaultOffenseReportEvents.
+	Events *OffenseSearchOptions
+	// SkipEvents disables the sample events search.
+	SkipEvents bool
+}
+
+// Report gathers the Offense, its notes, addresses, contributing rules and
+// sample events by ID into the OffenseReport.
+func (c *OffenseService) Report(ctx context.Context, id int, opts *OffenseReportOptions) (*OffenseReport, error) {
+	if opts == nil {
+		opts = &OffenseReportOptions{}
+	}
+
+	o, err := c.GetByID(ctx, "", id)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &OffenseReport{
+		GeneratedAt: time.Now().UTC(),
+		Offense:     *o,
+	}
+
+	r.Notes, err = c.Notes(ctx, "", "", id, 0, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(o.SourceAddressIds) > 0 {
+		r.SourceAddresses, err = c.client.SourceAddress.Get(ctx, "", idsFilter(o.SourceAddressIds), 0, 0)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if len(o.LocalDestinationAddressIds) > 0 {
+		r.LocalDestinationAddresses, err = c.client.LocalDestinationAddress.Get(ctx, "", idsFilter(o.LocalDestinationAddressIds), 0, 0)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for _, rr := range o.Rules {
+		if rr.ID == nil {
+			continue
+		}
+		rule, err := c.client.Rule.GetByID(ctx, "", *rr.ID)
+		if err != nil {
+			return nil, err
+		}
+		r.Rules = append(r.Rules, *rule)
+	}
+
+	if opts.SkipEvents {
+		return r, nil
+	}
+
+	eo := OffenseSearchOptions{Limit: DefaultOffenseReportEvents}
+	if opts.Events != nil {
+		eo = *opts.Events
+	}
+
+	scroller, _, err := c.Events(ctx, id, &eo)
+	if err != nil {
+		return nil, err
+	}
+	for scroller.Next(ctx) {
+		r.Events = append(r.Events, scroller.Result())
+	}
+	err = scroller.Err()
+	if err != nil {
+		return nil, err
+	}
+
+	r.EventColumns = eo.Columns
+	if len(r.EventColumns) == 0 {
+		r.EventColumns = eventColumns(r.Events)
+	}
+
+	return r, nil
+}
+
+// WriteJSON writes the report as an indented JSON document.
+func (r *OffenseReport) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r)
+}
+
+// WriteMarkdown writes the report as a Markdown document.
+func (r *OffenseReport) WriteMarkdown(w io.Writer) error {
+	return offenseReportMarkdown.Execute(w, r)
+}
+
+// WriteHTML writes the report as a standalone HTML document.
+func (r *OffenseReport) WriteHTML(w io.Writer) error {
+	return offenseReportHTML.Execute(w, r)
+}
+
+// idsFilter builds a filter that matches the records by the list of IDs.
+func idsFilter(ids []int) string {
+	s := make([]string, len(ids))
+	for i := range ids {
+		s[i] = fmt.Sprintf("%d", ids[i])
+	}
+	return fmt.Sprintf("id in (%s)", strings.Join(s, ","))
+}
+
+// eventColumns returns sorted column names found in the events.
+func eventColumns(events []Event) []string {
+	seen := make(map[string]struct{})
+	var columns []string
+	for _, e := range events {
+		for k := range e {
+			if _, ok := seen[k]; ok {
+				continue
+			}
+			seen[k] = struct{}{}
+			columns = append(columns, k)
+		}
+	}
+	sort.Strings(columns)
+	return columns
+}
+
+var offenseReportFuncs = map[string]interface{}{
+	"str": func(s *string) string {
+		if s == nil {
+			return ""
+		}
+		return *s
+	},
+	"num": func(n *int) string {
+		if n == nil {
+			return ""
+		}
+		return fmt.Sprintf("%d", *n)
+	},
+	"bool": func(b *bool) string {
+		if b == nil {
+			return ""
+		}
+		return fmt.Sprintf("%t", *b)
+	},
+	"ts": func(n *int) string {
+		if n == nil || *n == 0 {
+			return ""
+		}
+		return time.Unix(0, int64(*n)*int64(time.Millisecond)).UTC().Format(time.RFC3339)
+	},
+	"field": func(e Event, column string) string {
+		v, ok := e[column]
+		if !ok || v == nil {
+			return ""
+		}
+		return fmt.Sprintf("%v", v)
+	},
+	"cell": func(s string) string {
+		s = strings.ReplaceAll(s, "|", `\|`)
+		s = strings.ReplaceAll(s, "\r", "")
+		return strings.ReplaceAll(s, "\n", "<br>")
+	},
+	"join": strings.Join,
+	"ints": func(ids []int) string {
+		s := make([]string, len(ids))
+		for i := range ids {
+			s[i] = fmt.Sprintf("%d", ids[i])
+		}
+		return strings.Join(s, ", ")
+	},
+}
+
+var offenseReportMarkdown = template.Must(template.New("markdown").Funcs(offenseReportFuncs).Parse(
+	`{{with .Offense}}# Offense {{num .ID}}: {{cell (str .Description)}}
+
+| Field | Value |
+| --- | --- |
+| Status | {{str .Status}} |
+| Offense source | {{cell (str .OffenseSource)}} |
+| Magnitude | {{num .Magnitude}} |
+| Severity | {{num .Severity}} |
+| Credibility | {{num .Credibility}} |
+| Relevance | {{num .Relevance}} |
+| Start time | {{ts .StartTime}} |
+| Last updated time | {{ts .LastUpdatedTime}} |
+| Assigned to | {{str .AssignedTo}} |
+| Event count | {{num .EventCount}} |
+| Flow count | {{num .FlowCount}} |
+| Categories | {{cell (join .Categories ", ")}} |
+| Domain ID | {{num .DomainID}} |
+{{end}}
+_Generated at {{.GeneratedAt.Format "2006-01-02T15:04:05Z07:00"}}_
+{{if .Notes}}
+## Notes
+{{range .Notes}}
+- **{{str .Username}}** ({{ts .CreateTime}}): {{cell (str .NoteText)}}
+{{- end}}
+{{end}}{{if .SourceAddresses}}
+## Source addresses
+
+| IP | Network | Magnitude | Events/flows | First seen | Last seen |
+| --- | --- | --- | --- | --- | --- |
+{{range .SourceAddresses}}| {{str .SourceIP}} | {{cell (str .Network)}} | {{num .Magnitude}} | {{num .EventFlowCount}} | {{ts .FirstEventFlowSeen}} | {{ts .LastEventFlowSeen}} |
+{{end}}{{end}}{{if .LocalDestinationAddresses}}
+## Local destination addresses
+
+| IP | Network | Magnitude | Events/flows | First seen | Last seen |
+| --- | --- | --- | --- | --- | --- |
+{{range .LocalDestinationAddresses}}| {{str .LocalDestinationIP}} | {{cell (str .Network)}} | {{num .Magnitude}} | {{num .EventFlowCount}} | {{ts .FirstEventFlowSeen}} | {{ts .LastEventFlowSeen}} |
+{{end}}{{end}}{{if .Rules}}
+## Contributing rules
+
+| ID | Name | Type | Enabled | Owner |
+| --- | --- | --- | --- | --- |
+{{range .Rules}}| {{num .ID}} | {{cell (str .Name)}} | {{str .Type}} | {{bool .Enabled}} | {{str .Owner}} |
+{{end}}{{end}}{{if .Events}}
+## Sample events
+
+|{{range .EventColumns}} {{cell .}} |{{end}}
+|{{range .EventColumns}} --- |{{end}}
+{{range $e := .Events}}|{{range $.EventColumns}} {{cell (field $e .)}} |{{end}}
+{{end}}{{end}}`))
+
+var offenseReportHTML = htmltemplate.Must(htmltemplate.New("html").Funcs(offenseReportFuncs).Parse(
+	`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Offense {{num .Offense.ID}}</title>
+<style>
+body { font-family: sans-serif; margin: 2em; }
+table { border-collapse: collapse; margin-bottom: 1em; }
+th, td { border: 1px solid #ccc; padding: 4px 8px; text-align: left; vertical-align: top; }
+</style>
+</head>
+<body>
+{{with .Offense}}<h1>Offense {{num .ID}}: {{str .Description}}</h1>
+<table>
+<tr><th>Status</th><td>{{str .Status}}</td></tr>
+<tr><th>Offense source</th><td>{{str .OffenseSource}}</td></tr>
+<tr><th>Magnitude</th><td>{{num .Magnitude}}</td></tr>
+<tr><th>Severity</th><td>{{num .Severity}}</td></tr>
+<tr><th>Credibility</th><td>{{num .Credibility}}</td></tr>
+<tr><th>Relevance</th><td>{{num .Relevance}}</td></tr>
+<tr><th>Start time</th><td>{{ts .StartTime}}</td></tr>
+<tr><th>Last updated time</th><td>{{ts .LastUpdatedTime}}</td></tr>
+<tr><th>Assigned to</th><td>{{str .AssignedTo}}</td></tr>
+<tr><th>Event count</th><td>{{num .EventCount}}</td></tr>
+<tr><th>Flow count</th><td>{{num .FlowCount}}</td></tr>
+<tr><th>Categories</th><td>{{join .Categories ", "}}</td></tr>
+<tr><th>Domain ID</th><td>{{num .DomainID}}</td></tr>
+</table>
+{{end}}<p><em>Generated at {{.GeneratedAt.Format "2006-01-02T15:04:05Z07:00"}}</em></p>
+{{if .Notes}}<h2>Notes</h2>
+<ul>
+{{range .Notes}}<li><strong>{{str .Username}}</strong> ({{ts .CreateTime}}): {{str .NoteText}}</li>
+{{end}}</ul>
+{{end}}{{if .SourceAddresses}}<h2>Source addresses</h2>
+<table>
+<tr><th>IP</th><th>Network</th><th>Magnitude</th><th>Events/flows</th><th>First seen</th><th>Last seen</th></tr>
+{{range .SourceAddresses}}<tr><td>{{str .SourceIP}}</td><td>{{str .Network}}</td><td>{{num .Magnitude}}</td><td>{{num .EventFlowCount}}</td><td>{{ts .FirstEventFlowSeen}}</td><td>{{ts .LastEventFlowSeen}}</td></tr>
+{{end}}</table>
+{{end}}{{if .LocalDestinationAddresses}}<h2>Local destination addresses</h2>
+<table>
+<tr><th>IP</th><th>Network</th><th>Magnitude</th><th>Events/flows</th><th>First seen</th><th>Last seen</th></tr>
+{{range .LocalDestinationAddresses}}<tr><td>{{str .LocalDestinationIP}}</td><td>{{str .Network}}</td><td>{{num .Magnitude}}</td><td>{{num .EventFlowCount}}</td><td>{{ts .FirstEventFlowSeen}}</td><td>{{ts .LastEventFlowSeen}}</td></tr>
+{{end}}</table>
+{{end}}{{if .Rules}}<h2>Contributing rules</h2>
+<table>
+<tr><th>ID</th><th>Name</th><th>Type</th><th>Enabled</th><th>Owner</th></tr>
+{{range .Rules}}<tr><td>{{num .ID}}</td><td>{{str .Name}}</td><td>{{str .Type}}</td><td>{{bool .Enabled}}</td><td>{{str .Owner}}</td></tr>
+{{end}}</table>
+{{end}}{{if .Events}}<h2>Sample events</h2>
+<table>
+<tr>{{range .EventColumns}}<th>{{.}}</th>{{end}}</tr>
+{{range $e := .Events}}<tr>{{range $.EventColumns}}<td>{{field $e .}}</td>{{end}}</tr>
+{{end}}</table>
+{{end}}</body>
+</html>
+`))
@@ -0,0 +1,71 @@
+package qradar
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testOffenseReport() *OffenseReport {
+	return &OffenseReport{
+		GeneratedAt: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		Offense: Offense{
+			ID:          intPtr(42),
+			Description: strPtr("Scan | <script>alert(1)</script>"),
+			Status:      strPtr("OPEN"),
+			StartTime:   intPtr(1700000000000),
+			Categories:  []string{"Recon", "Scan"},
+		},
+		Notes:        []OffenseNote{{Username: strPtr("admin"), NoteText: strPtr("line 1\nline 2")}},
+		Rules:        []Rule{{ID: intPtr(100), Name: strPtr("Port scan")}},
+		EventColumns: []string{"sourceip", "qid"},
+		Events:       []Event{{"sourceip": "10.0.0.1", "qid": float64(1002)}, {"sourceip": "10.0.0.2"}},
+	}
+}
+
+func TestOffenseReportWriteMarkdown(t *testing.T) {
+	var buf bytes.Buffer
+	err := testOffenseReport().WriteMarkdown(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		`# Offense 42: Scan \| <script>alert(1)</script>`,
+		"| Start time | 2023-11-14T22:13:20Z |",
+		"| Categories | Recon, Scan |",
+		"_Generated at 2024-01-02T03:04:05Z_",
+		"- **admin** (): line 1<br>line 2",
+		"| 100 | Port scan |  |  |  |",
+		"| sourceip | qid |\n| --- | --- |\n| 10.0.0.1 | 1002 |\n| 10.0.0.2 |  |\n",
+	} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("no %q in\n%s", want, buf.String())
+		}
+	}
+	if strings.Contains(buf.String(), "## Source addresses") {
+		t.Error("empty source addresses section is rendered")
+	}
+}
+
+func TestOffenseReportWriteHTML(t *testing.T) {
+	var buf bytes.Buffer
+	err := testOffenseReport().WriteHTML(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"<title>Offense 42</title>",
+		"<h1>Offense 42: Scan | &lt;script&gt;alert(1)&lt;/script&gt;</h1>",
+		"<tr><th>Categories</th><td>Recon, Scan</td></tr>",
+		"<tr><th>sourceip</th><th>qid</th></tr>",
+		"<tr><td>10.0.0.1</td><td>1002</td></tr>",
+	} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("no %q in\n%s", want, buf.String())
+		}
+	}
+	if strings.Contains(buf.String(), "<script>") {
+		t.Error("description is not escaped")
+	}
+}
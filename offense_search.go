@@ -0,0 +1,68 @@
+package qradar
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// offenseStopMargin extends the search window past the offense last update
+// time, so the events that have updated the offense are not cut off.
+const offenseStopMargin = 60 * 1000
+
+// OffenseSearchOptions represents options of the search for the events and
+// flows contributed to the Offense.
+type OffenseSearchOptions struct {
+	// Columns to select, all columns are selected if empty.
+	Columns []string
+	// Limit of the records to return, no limit if zero.
+	Limit int
+}
+
+// Events returns a scroller over the events contributed to the Offense by ID.
+func (c *OffenseService) Events(ctx context.Context, id int, opts *OffenseSearchOptions) (*SearchResultsScroller, *SearchMetadata, error) {
+	return c.search(ctx, "events", id, opts)
+}
+
+// Flows returns a scroller over the flows contributed to the Offense by ID.
+func (c *OffenseService) Flows(ctx context.Context, id int, opts *OffenseSearchOptions) (*SearchResultsScroller, *SearchMetadata, error) {
+	return c.search(ctx, "flows", id, opts)
+}
+
+func (c *OffenseService) search(ctx context.Context, table string, id int, opts *OffenseSearchOptions) (*SearchResultsScroller, *SearchMetadata, error) {
+	o, err := c.GetByID(ctx, "id,start_time,last_updated_time", id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	query, err := offenseQuery(table, o, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return c.client.Ariel.ScrollByQuery(ctx, query)
+}
+
+// offenseQuery builds the AQL query selecting records of the table that
+// contributed to the Offense within its lifetime.
+func offenseQuery(table string, o *Offense, opts *OffenseSearchOptions) (string, error) {
+	if o.ID == nil || o.StartTime == nil || o.LastUpdatedTime == nil {
+		return "", fmt.Errorf("offense has no id, start_time or last_updated_time")
+	}
+
+	if opts == nil {
+		opts = &OffenseSearchOptions{}
+	}
+
+	columns := "*"
+	if len(opts.Columns) > 0 {
+		columns = strings.Join(opts.Columns, ", ")
+	}
+
+	query := fmt.Sprintf("SELECT %s FROM %s WHERE INOFFENSE(%d)", columns, table, *o.ID)
+	if opts.Limit > 0 {
+		query = fmt.Sprintf("%s LIMIT %d", query, opts.Limit)
+	}
+
+	return fmt.Sprintf("%s START %d STOP %d", query, *o.StartTime, *o.LastUpdatedTime+offenseStopMargin), nil
+}
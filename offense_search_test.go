@@ -0,0 +1,70 @@
+package qradar
+
+import (
+	"context"
+	"net/http"
+	"testing"
+)
+
+func TestOffenseQuery(t *testing.T) {
+	offense := &Offense{ID: intPtr(42), StartTime: intPtr(1000), LastUpdatedTime: intPtr(5000)}
+
+	tests := []struct {
+		name    string
+		table   string
+		offense *Offense
+		opts    *OffenseSearchOptions
+		want    string
+		wantErr bool
+	}{
+		{"events", "events", offense, nil, "SELECT * FROM events WHERE INOFFENSE(42) START 1000 STOP 65000", false},
+		{"flows with columns", "flows", offense, &OffenseSearchOptions{Columns: []string{"sourceip", "destinationip"}}, "SELECT sourceip, destinationip FROM flows WHERE INOFFENSE(42) START 1000 STOP 65000", false},
+		{"limit", "events", offense, &OffenseSearchOptions{Limit: 20}, "SELECT * FROM events WHERE INOFFENSE(42) LIMIT 20 START 1000 STOP 65000", false},
+		{"no start time", "events", &Offense{ID: intPtr(42), LastUpdatedTime: intPtr(5000)}, nil, "", true},
+	}
+	for _, tt := range tests {
+		got, err := offenseQuery(tt.table, tt.offense, tt.opts)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: offenseQuery() error %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+		if got != tt.want {
+			t.Errorf("%s: offenseQuery() = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestSearchResultsScrollerErr(t *testing.T) {
+	defer func(n int) { SearchResultsWindow = n }(SearchResultsWindow)
+	SearchResultsWindow = 1
+
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/ariel/searches/s1":
+			w.Write([]byte(`{"status":"COMPLETED","record_count":4}`))
+		case "/api/ariel/searches/s1/results":
+			if r.Header.Get("Range") != "items=0-1" {
+				http.Error(w, `{"message":"unavailable"}`, http.StatusServiceUnavailable)
+				return
+			}
+			w.Write([]byte(`{"events":[{"n":1},{"n":2}]}`))
+		default:
+			http.NotFound(w, r)
+		}
+	})
+
+	s, err := c.Ariel.NewSearchResultsScroller(context.Background(), "s1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	n := 0
+	for s.Next(context.Background()) {
+		s.Result()
+		n++
+	}
+	if n != 2 {
+		t.Errorf("scrolled %d events, want 2", n)
+	}
+	if s.Err() == nil {
+		t.Error("Err() of the failed page = nil")
+	}
+}
@@ -0,0 +1,332 @@
+package qradar
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ProtocolTypeService handles methods related to Protocol Types of the QRadar API.
+type ProtocolTypeService service
+
+const (
+	protocolTypeAPIPrefix = "api/config/event_sources/log_source_management/protocol_types"
+)
+
+// Types of the protocol parameters.
+const (
+	ProtocolParameterString   = "STRING"
+	ProtocolParameterText     = "TEXT"
+	ProtocolParameterPassword = "PASSWORD"
+	ProtocolParameterInteger  = "INTEGER"
+	ProtocolParameterLong     = "LONG"
+	ProtocolParameterBoolean  = "BOOLEAN"
+)
+
+// ProtocolType represents QRadar's Protocol Type of the Log Sources.
+type ProtocolType struct {
+	ID               *int                          `json:"id,omitempty"`
+	Name             *string                       `json:"name,omitempty"`
+	Version          *string                       `json:"version,omitempty"`
+	LatestVersion    *string                       `json:"latest_version,omitempty"`
+	GatewaySupported *bool                         `json:"gateway_supported,omitempty"`
+	Inbound          *bool                         `json:"inbound,omitempty"`
+	Parameters       []ProtocolParameterDefinition `json:"parameters,omitempty"`
+}
+
+// ProtocolParameterDefinition represents the definition of a parameter of
+// the Protocol Type.
+type ProtocolParameterDefinition struct {
+	ID            *int                   `json:"id,omitempty"`
+	Name          *string                `json:"name,omitempty"`
+	Label         *string                `json:"label,omitempty"`
+	Description   *string                `json:"description,omitempty"`
+	Type          *string                `json:"type,omitempty"`
+	Required      *bool                  `json:"required,omitempty"`
+	DefaultValue  *string                `json:"default_value,omitempty"`
+	AllowedValues []ProtocolAllowedValue `json:"allowed_values,omitempty"`
+	MinLength     *int                   `json:"min_length,omitempty"`
+	MaxLength     *int                   `json:"max_length,omitempty"`
+	MinValue      *int64                 `json:"min_value,omitempty"`
+	MaxValue      *int64                 `json:"max_value,omitempty"`
+}
+
+// ProtocolAllowedValue represents an allowed value of the protocol parameter.
+type ProtocolAllowedValue struct {
+	Name  *string `json:"name,omitempty"`
+	Value *string `json:"value,omitempty"`
+}
+
+// Get returns Protocol Types of the current QRadar installation.
+func (c *ProtocolTypeService) Get(ctx context.Context, fields, filter string, from, to int) ([]ProtocolType, error) {
+	req, err := c.client.requestHelp(http.MethodGet, protocolTypeAPIPrefix, fields, filter, from, to, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	var result []ProtocolType
+	_, err = c.client.Do(ctx, req, &result)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetByID returns Protocol Type of the current QRadar installation by ID.
+func (c *ProtocolTypeService) GetByID(ctx context.Context, fields string, id int) (*ProtocolType, error) {
+	req, err := c.client.requestHelp(http.MethodGet, protocolTypeAPIPrefix, fields, "", 0, 0, &id, nil)
+	if err != nil {
+		return nil, err
+	}
+	var result ProtocolType
+	_, err = c.client.Do(ctx, req, &result)
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// GetByName returns Protocol Type of the current QRadar installation by Name.
+func (c *ProtocolTypeService) GetByName(ctx context.Context, fields string, name string) (*ProtocolType, error) {
+	req, err := c.client.requestHelp(http.MethodGet, protocolTypeAPIPrefix, fields, "name="+filterString(name), 0, 0, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	var result []ProtocolType
+	_, err = c.client.Do(ctx, req, &result)
+	if err != nil {
+		return nil, err
+	}
+	if len(result) == 0 {
+		return nil, nil
+	} else if len(result) > 1 {
+		return nil, fmt.Errorf("found more protocol types than expected - %d", len(result))
+	}
+	return &result[0], nil
+}
+
+// ProtocolParameterError represents an invalid parameter of the protocol.
+type ProtocolParameterError struct {
+	Name    string
+	Message string
+}
+
+// Error satisfies the error interface.
+func (e ProtocolParameterError) Error() string {
+	return fmt.Sprintf("parameter %q: %s", e.Name, e.Message)
+}
+
+// ProtocolParameterErrors represents all the invalid parameters of the
+// protocol.
+type ProtocolParameterErrors []ProtocolParameterError
+
+// Error satisfies the error interface.
+func (errs ProtocolParameterErrors) Error() string {
+	msgs := make([]string, len(errs))
+	for i, e := range errs {
+		msgs[i] = e.Error()
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// Parameter returns the definition of the parameter by name.
+func (pt *ProtocolType) Parameter(name string) (*ProtocolParameterDefinition, bool) {
+	for i := range pt.Parameters {
+		p := &pt.Parameters[i]
+		if p.Name != nil && *p.Name == name {
+			return p, true
+		}
+	}
+	return nil, false
+}
+
+// BuildParameters returns the protocol parameters of a new Log Source from
+// the values by parameter name. Parameters without a value get the default
+// one, the values are validated by the definitions.
+func (pt *ProtocolType) BuildParameters(values map[string]string) ([]ProtocolParameter, error) {
+	var errs ProtocolParameterErrors
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if _, ok := pt.Parameter(name); !ok {
+			errs = append(errs, ProtocolParameterError{Name: name, Message: "unknown parameter"})
+		}
+	}
+
+	var result []ProtocolParameter
+	for i := range pt.Parameters {
+		def := &pt.Parameters[i]
+		if def.Name == nil {
+			continue
+		}
+		v, ok := values[*def.Name]
+		if !ok && def.DefaultValue != nil {
+			v, ok = *def.DefaultValue, true
+		}
+		if !ok {
+			if def.Required != nil && *def.Required {
+				errs = append(errs, ProtocolParameterError{Name: *def.Name, Message: "required parameter is missing"})
+			}
+			continue
+		}
+		err := def.validate(v)
+		if err != nil {
+			errs = append(errs, *err)
+			continue
+		}
+		result = append(result, ProtocolParameter{Name: def.Name, ID: def.ID, Value: &v})
+	}
+
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	return result, nil
+}
+
+// ValidateParameters checks the protocol parameters of a Log Source, the
+// parameters are matched to the definitions by name or ID. Parameters given
+// more than once and missing required parameters without a default value are
+// reported as well.
+func (pt *ProtocolType) ValidateParameters(params []ProtocolParameter) error {
+	var errs ProtocolParameterErrors
+	seen := make(map[string]bool)
+	for _, p := range params {
+		def := pt.definition(p)
+		if def == nil {
+			errs = append(errs, ProtocolParameterError{Name: parameterName(p), Message: "unknown parameter"})
+			continue
+		}
+		if seen[*def.Name] {
+			errs = append(errs, ProtocolParameterError{Name: *def.Name, Message: "duplicate parameter"})
+			continue
+		}
+		seen[*def.Name] = true
+		if def.ID != nil && p.ID != nil && *def.ID != *p.ID {
+			errs = append(errs, ProtocolParameterError{Name: *def.Name, Message: fmt.Sprintf("id %d doesn't match the definition id %d", *p.ID, *def.ID)})
+			continue
+		}
+		if p.Value == nil {
+			errs = append(errs, ProtocolParameterError{Name: *def.Name, Message: "no value"})
+			continue
+		}
+		if err := def.validate(*p.Value); err != nil {
+			errs = append(errs, *err)
+		}
+	}
+
+	for _, def := range pt.Parameters {
+		if def.Name == nil || seen[*def.Name] {
+			continue
+		}
+		if def.Required != nil && *def.Required && def.DefaultValue == nil {
+			errs = append(errs, ProtocolParameterError{Name: *def.Name, Message: "required parameter is missing"})
+		}
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+func (pt *ProtocolType) definition(p ProtocolParameter) *ProtocolParameterDefinition {
+	if p.Name != nil {
+		if def, ok := pt.Parameter(*p.Name); ok {
+			return def
+		}
+	}
+	if p.ID != nil {
+		for i := range pt.Parameters {
+			def := &pt.Parameters[i]
+			if def.ID != nil && *def.ID == *p.ID && def.Name != nil {
+				return def
+			}
+		}
+	}
+	return nil
+}
+
+func parameterName(p ProtocolParameter) string {
+	if p.Name != nil {
+		return *p.Name
+	}
+	if p.ID != nil {
+		return strconv.Itoa(*p.ID)
+	}
+	return ""
+}
+
+// Validate checks the value of the parameter by its type, allowed values and
+// limits. The error is a ProtocolParameterError.
+func (def *ProtocolParameterDefinition) Validate(v string) error {
+	if err := def.validate(v); err != nil {
+		return *err
+	}
+	return nil
+}
+
+func (def *ProtocolParameterDefinition) validate(v string) *ProtocolParameterError {
+	var name string
+	if def.Name != nil {
+		name = *def.Name
+	}
+	fail := func(format string, args ...interface{}) *ProtocolParameterError {
+		return &ProtocolParameterError{Name: name, Message: fmt.Sprintf(format, args...)}
+	}
+
+	if v == "" {
+		if def.Required != nil && *def.Required {
+			return fail("required parameter is empty")
+		}
+		return nil
+	}
+
+	if len(def.AllowedValues) > 0 {
+		allowed := make([]string, 0, len(def.AllowedValues))
+		for _, a := range def.AllowedValues {
+			if a.Value == nil {
+				continue
+			}
+			if *a.Value == v {
+				return nil
+			}
+			allowed = append(allowed, *a.Value)
+		}
+		return fail("value %q is not one of %s", v, strings.Join(allowed, ", "))
+	}
+
+	var typ string
+	if def.Type != nil {
+		typ = strings.ToUpper(*def.Type)
+	}
+	switch typ {
+	case ProtocolParameterInteger, ProtocolParameterLong:
+		n, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return fail("value %q is not a number", v)
+		}
+		if def.MinValue != nil && n < *def.MinValue {
+			return fail("value %d is less than %d", n, *def.MinValue)
+		}
+		if def.MaxValue != nil && n > *def.MaxValue {
+			return fail("value %d is greater than %d", n, *def.MaxValue)
+		}
+	case ProtocolParameterBoolean:
+		if v != "true" && v != "false" {
+			return fail("value %q is not a boolean", v)
+		}
+	default:
+		if def.MinLength != nil && len(v) < *def.MinLength {
+			return fail("value is shorter than %d", *def.MinLength)
+		}
+		if def.MaxLength != nil && len(v) > *def.MaxLength {
+			return fail("value is longer than %d", *def.MaxLength)
+		}
+	}
+	return nil
+}
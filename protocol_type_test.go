@@ -0,0 +1,181 @@
+package qradar
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+const syslogProtocolType = `{
+	"id": 0,
+	"name": "Syslog",
+	"parameters": [
+		{"id": 1, "name": "identifier", "type": "STRING", "required": true, "max_length": 255},
+		{"id": 2, "name": "port", "type": "INTEGER", "default_value": "514", "min_value": 1, "max_value": 65535},
+		{"id": 3, "name": "incomingPayloadEncoding", "type": "STRING", "required": true, "default_value": "UTF-8",
+			"allowed_values": [{"name": "UTF-8", "value": "UTF-8"}, {"name": "ASCII", "value": "US-ASCII"}]},
+		{"name": "tls", "type": "BOOLEAN", "required": true}
+	]
+}`
+
+func testProtocolType(t *testing.T) *ProtocolType {
+	t.Helper()
+	var pt ProtocolType
+	err := json.Unmarshal([]byte(syslogProtocolType), &pt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &pt
+}
+
+func protocolParameter(id *int, name, value string) ProtocolParameter {
+	p := ProtocolParameter{ID: id, Value: &value}
+	if name != "" {
+		p.Name = &name
+	}
+	return p
+}
+
+// parameterErrors returns the parameter errors of err as "name: message".
+func parameterErrors(t *testing.T, err error) []string {
+	t.Helper()
+	if err == nil {
+		return nil
+	}
+	var errs ProtocolParameterErrors
+	if !errors.As(err, &errs) {
+		t.Fatalf("unexpected error %T %v", err, err)
+	}
+	var result []string
+	for _, e := range errs {
+		result = append(result, e.Name+": "+e.Message)
+	}
+	sort.Strings(result)
+	return result
+}
+
+func TestProtocolParameterDefinitionValidate(t *testing.T) {
+	pt := testProtocolType(t)
+	tests := []struct {
+		name  string
+		value string
+		want  string
+	}{
+		{"identifier", "fw01", ""},
+		{"identifier", "", "required parameter is empty"},
+		{"port", "514", ""},
+		{"port", "0", "value 0 is less than 1"},
+		{"port", "http", `value "http" is not a number`},
+		{"incomingPayloadEncoding", "US-ASCII", ""},
+		{"incomingPayloadEncoding", "latin1", `value "latin1" is not one of UTF-8, US-ASCII`},
+		{"tls", "yes", `value "yes" is not a boolean`},
+	}
+	for _, tt := range tests {
+		def, ok := pt.Parameter(tt.name)
+		if !ok {
+			t.Fatalf("no parameter %q", tt.name)
+		}
+		err := def.Validate(tt.value)
+		got := ""
+		if err != nil {
+			var pe ProtocolParameterError
+			if !errors.As(err, &pe) {
+				t.Fatalf("unexpected error %T", err)
+			}
+			got = pe.Message
+		}
+		if got != tt.want {
+			t.Errorf("Validate(%s, %q) = %q, want %q", tt.name, tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestProtocolTypeBuildParameters(t *testing.T) {
+	pt := testProtocolType(t)
+
+	params, err := pt.BuildParameters(map[string]string{"identifier": "fw01", "tls": "true"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := map[string]string{}
+	for _, p := range params {
+		got[*p.Name] = *p.Value
+	}
+	want := map[string]string{"identifier": "fw01", "port": "514", "incomingPayloadEncoding": "UTF-8", "tls": "true"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	_, err = pt.BuildParameters(map[string]string{"port": "70000", "bogus": "1"})
+	wantErrs := []string{
+		"bogus: unknown parameter",
+		"identifier: required parameter is missing",
+		"port: value 70000 is greater than 65535",
+		"tls: required parameter is missing",
+	}
+	if got := parameterErrors(t, err); !reflect.DeepEqual(got, wantErrs) {
+		t.Errorf("got errors %q, want %q", got, wantErrs)
+	}
+}
+
+func TestProtocolTypeValidateParameters(t *testing.T) {
+	pt := testProtocolType(t)
+	one, two := 1, 2
+
+	tests := []struct {
+		name   string
+		params []ProtocolParameter
+		want   []string
+	}{
+		{
+			name: "valid",
+			params: []ProtocolParameter{
+				protocolParameter(&one, "identifier", "fw01"),
+				protocolParameter(nil, "tls", "false"),
+			},
+		},
+		{
+			name: "by id",
+			params: []ProtocolParameter{
+				protocolParameter(&one, "", "fw01"),
+				protocolParameter(nil, "tls", "false"),
+			},
+		},
+		{
+			name: "required without id",
+			params: []ProtocolParameter{
+				protocolParameter(&one, "identifier", "fw01"),
+			},
+			want: []string{"tls: required parameter is missing"},
+		},
+		{
+			name: "duplicate",
+			params: []ProtocolParameter{
+				protocolParameter(&one, "identifier", "fw01"),
+				protocolParameter(&one, "", "fw02"),
+				protocolParameter(nil, "tls", "false"),
+			},
+			want: []string{"identifier: duplicate parameter"},
+		},
+		{
+			name: "mismatch",
+			params: []ProtocolParameter{
+				protocolParameter(&two, "identifier", "fw01"),
+				protocolParameter(nil, "tls", "false"),
+				protocolParameter(nil, "unknown", "x"),
+			},
+			want: []string{
+				"identifier: id 2 doesn't match the definition id 1",
+				"unknown: unknown parameter",
+			},
+		},
+	}
+	for _, tt := range tests {
+		err := pt.ValidateParameters(tt.params)
+		if got := parameterErrors(t, err); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: got errors %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
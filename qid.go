@@ -104,7 +104,7 @@ func (c *QIDService) UpdateByID(ctx context.Context, fields string, id int, data
 // GetByName returns QID of the current QRadar installation by name.
 // If there are more than one QID that the same, this will returm the one with the least QID number
 func (c *QIDService) GetByName(ctx context.Context, fields string, name string) (*QID, error) {
-	req, err := c.client.requestHelp(http.MethodGet, qidAPIPrefix, fields, fmt.Sprintf("name=\"%s\"", name), 0, 0, nil, nil)
+	req, err := c.client.requestHelp(http.MethodGet, qidAPIPrefix, fields, "name="+filterString(name), 0, 0, nil, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -8,7 +8,6 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
-	"io/ioutil"
 	"net/http"
 	"net/url"
 	"strings"
@@ -74,6 +73,9 @@ type Client struct {
 	RuleGroup             *RuleGroupService
 	NetworkHierarchy      *NetworkHierarchyService
 
+	SourceAddress           *SourceAddressService
+	LocalDestinationAddress *LocalDestinationAddressService
+
 	PropertyExpression            *PropertyExpressionService
 	PropertyJSONExpression        *PropertyJSONExpressionService
 	PropertyLEEFExpression        *PropertyLEEFExpressionService
@@ -86,11 +88,14 @@ type Client struct {
 	LogSourceType      *LogSourceTypeService
 	LogSourceGroup     *LogSourceGroupService
 	LogSource          *LogSourceService
+	ProtocolType       *ProtocolTypeService
 
 	ReferenceMapOfSets *ReferenceMapOfSetsService
 	ReferenceMap       *ReferenceMapService
 	ReferenceSet       *ReferenceSetService
 	ReferenceTable     *ReferenceTableService
+
+	ReferenceDataCollections *ReferenceDataCollectionsService
 }
 
 type service struct {
@@ -135,6 +140,7 @@ func NewClient(baseurl string, opts ...func(*Client) error) (*Client, error) {
 	c.LogSourceType = (*LogSourceTypeService)(&c.common)
 	c.LogSourceGroup = (*LogSourceGroupService)(&c.common)
 	c.LogSource = (*LogSourceService)(&c.common)
+	c.ProtocolType = (*ProtocolTypeService)(&c.common)
 	c.LowLevelCategory = (*LowLevelCategoryService)(&c.common)
 	c.HighLevelCategory = (*HighLevelCategoryService)(&c.common)
 	c.Tenant = (*TenantService)(&c.common)
@@ -142,7 +148,10 @@ func NewClient(baseurl string, opts ...func(*Client) error) (*Client, error) {
 	c.ReferenceMap = (*ReferenceMapService)(&c.common)
 	c.ReferenceSet = (*ReferenceSetService)(&c.common)
 	c.ReferenceTable = (*ReferenceTableService)(&c.common)
+	c.ReferenceDataCollections = (*ReferenceDataCollectionsService)(&c.common)
 	c.NetworkHierarchy = (*NetworkHierarchyService)(&c.common)
+	c.SourceAddress = (*SourceAddressService)(&c.common)
+	c.LocalDestinationAddress = (*LocalDestinationAddressService)(&c.common)
 
 	for _, f := range opts {
 		err := f(c)
@@ -186,7 +195,9 @@ func (c *Client) requestHelp(method, urlStr, fields, filter string, from, to int
 	if err != nil {
 		return nil, err
 	}
-	if from == 0 && to != 0 {
+	// from and to are inclusive indexes of the items, zero to means all
+	// items, so only a valid window is sent
+	if to != 0 && to >= from {
 		req.Header.Add("Range", fmt.Sprintf("items=%d-%d", from, to))
 	}
 	q := req.URL.Query()
@@ -201,6 +212,12 @@ func (c *Client) requestHelp(method, urlStr, fields, filter string, from, to int
 	return req, nil
 }
 
+// filterString quotes the string value of the filter, e.g. the name of
+// name="value", escaping the quotes and backslashes of the value.
+func filterString(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
+
 // NewRequest constructs and new request to send.
 func (c *Client) NewRequest(method, urlStr string, body interface{}) (*http.Request, error) {
 	if !strings.HasSuffix(c.BaseURL.Path, "/") {
@@ -213,21 +230,16 @@ func (c *Client) NewRequest(method, urlStr string, body interface{}) (*http.Requ
 
 	var buf io.ReadWriter
 	if body != nil {
-		buf = new(bytes.Buffer)
-		enc := json.NewEncoder(buf)
-		enc.SetEscapeHTML(false)
-		err := enc.Encode(body)
-		if err != nil {
-			return nil, err
-		}
-
-		// QRadar reference_data API errors if json has a trailing new line
-		if strings.HasPrefix(urlStr, "api/reference_data") && strings.Contains(urlStr, "/bulk_load/") {
-			bs, err := ioutil.ReadAll(buf)
+		if raw, ok := body.(json.RawMessage); ok {
+			buf = bytes.NewBuffer(raw)
+		} else {
+			buf = new(bytes.Buffer)
+			enc := json.NewEncoder(buf)
+			enc.SetEscapeHTML(false)
+			err := enc.Encode(body)
 			if err != nil {
 				return nil, err
 			}
-			buf = bytes.NewBuffer(bs[:len(bs)-1])
 		}
 	}
 
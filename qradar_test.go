@@ -0,0 +1,49 @@
+package qradar
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestRequestHelpRange(t *testing.T) {
+	c, err := NewClient("https://qradar.example/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		from, to int
+		want     string
+	}{
+		{0, 0, ""},
+		{0, 49, "items=0-49"},
+		{50, 99, "items=50-99"},
+		{5, 5, "items=5-5"},
+		{5, 0, ""},
+		{10, 5, ""},
+	}
+	for _, tt := range tests {
+		req, err := c.requestHelp(http.MethodGet, "api/test", "", "", tt.from, tt.to, nil, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := req.Header.Get("Range"); got != tt.want {
+			t.Errorf("Range of %d-%d = %q, want %q", tt.from, tt.to, got, tt.want)
+		}
+	}
+}
+
+func TestFilterString(t *testing.T) {
+	tests := []struct {
+		s    string
+		want string
+	}{
+		{"plain", `"plain"`},
+		{`Acme "VPN"`, `"Acme \"VPN\""`},
+		{`C:\logs`, `"C:\\logs"`},
+	}
+	for _, tt := range tests {
+		if got := filterString(tt.s); got != tt.want {
+			t.Errorf("filterString(%q) = %s, want %s", tt.s, got, tt.want)
+		}
+	}
+}
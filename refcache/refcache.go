@@ -0,0 +1,357 @@
+// Package refcache provides a local read-only view of QRadar's Reference Set
+// or Reference Map with O(1) membership lookups.
+//
+// The Cache polls the collection metadata and reloads the data when the
+// number of elements changes or the refresh interval elapses. Elements are
+// expired locally according to the TimeToLive and TimeoutType of the
+// collection, so lookups between reloads don't return stale values.
+package refcache
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	qradar "github.com/ilyaglow/go-qradar"
+)
+
+// Defaults of the Cache options.
+var (
+	DefaultPollInterval    = 30 * time.Second
+	DefaultRefreshInterval = 15 * time.Minute
+)
+
+// Timeout types of the Reference collections.
+const (
+	TimeoutFirstSeen = "FIRST_SEEN"
+	TimeoutLastSeen  = "LAST_SEEN"
+)
+
+// Options represents options of the Cache.
+type Options struct {
+	// PollInterval is an interval of the metadata checks, the data is
+	// reloaded if the number of elements has changed.
+	PollInterval time.Duration
+	// RefreshInterval is an interval of the unconditional reloads.
+	RefreshInterval time.Duration
+	// OnError is called with the errors of the background refresh.
+	OnError func(error)
+}
+
+type element struct {
+	value     string
+	firstSeen time.Time
+	lastSeen  time.Time
+}
+
+// Cache represents a local view of the Reference Set or Reference Map.
+type Cache struct {
+	client *qradar.Client
+	kind   qradar.ReferenceKind
+	name   string
+	opts   Options
+
+	mu          sync.RWMutex
+	elementType string
+	ttl         time.Duration
+	timeoutType string
+	count       int
+	loadedAt    time.Time
+	elements    map[string]element
+	networks    []network
+}
+
+// network represents a CIDR value and its key in the elements.
+type network struct {
+	ipnet *net.IPNet
+	key   string
+}
+
+// New returns a new Cache of the Reference Set or Reference Map by name.
+func New(client *qradar.Client, kind qradar.ReferenceKind, name string, opts *Options) (*Cache, error) {
+	if kind != qradar.ReferenceKindSet && kind != qradar.ReferenceKindMap {
+		return nil, fmt.Errorf("reference %s is not supported by the cache", kind)
+	}
+
+	c := &Cache{
+		client: client,
+		kind:   kind,
+		name:   name,
+		opts: Options{
+			PollInterval:    DefaultPollInterval,
+			RefreshInterval: DefaultRefreshInterval,
+		},
+		elements: make(map[string]element),
+	}
+	if opts != nil {
+		if opts.PollInterval > 0 {
+			c.opts.PollInterval = opts.PollInterval
+		}
+		if opts.RefreshInterval > 0 {
+			c.opts.RefreshInterval = opts.RefreshInterval
+		}
+		c.opts.OnError = opts.OnError
+	}
+
+	return c, nil
+}
+
+// Start loads the data and refreshes it in the background until the context
+// is done.
+func (c *Cache) Start(ctx context.Context) error {
+	err := c.Load(ctx)
+	if err != nil {
+		return err
+	}
+
+	go func() {
+		ticker := time.NewTicker(c.opts.PollInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				err := c.Refresh(ctx)
+				if err != nil && c.opts.OnError != nil {
+					c.opts.OnError(err)
+				}
+			}
+		}
+	}()
+
+	return nil
+}
+
+// Refresh reloads the data if the number of elements of the collection has
+// changed or the refresh interval has elapsed since the last load.
+func (c *Cache) Refresh(ctx context.Context) error {
+	meta, err := c.metadata(ctx)
+	if err != nil {
+		return err
+	}
+
+	c.mu.RLock()
+	stale := meta.count != c.count || time.Since(c.loadedAt) >= c.opts.RefreshInterval
+	c.mu.RUnlock()
+
+	if !stale {
+		return nil
+	}
+
+	return c.Load(ctx)
+}
+
+type metadata struct {
+	elementType string
+	ttl         string
+	timeoutType string
+	count       int
+}
+
+func (c *Cache) metadata(ctx context.Context) (*metadata, error) {
+	const fields = "element_type,time_to_live,timeout_type,number_of_elements"
+
+	var m metadata
+	switch c.kind {
+	case qradar.ReferenceKindSet:
+		s, err := c.client.ReferenceSet.GetWithData(ctx, fields, "", c.name, 0, 0)
+		if err != nil {
+			return nil, err
+		}
+		m = metadata{stringValue(s.ElementType), stringValue(s.TimeToLive), stringValue(s.TimeoutType), intValue(s.NumberOfElements)}
+	case qradar.ReferenceKindMap:
+		s, err := c.client.ReferenceMap.GetWithData(ctx, fields, "", c.name, 0, 0)
+		if err != nil {
+			return nil, err
+		}
+		m = metadata{stringValue(s.ElementType), stringValue(s.TimeToLive), stringValue(s.TimeoutType), intValue(s.NumberOfElements)}
+	}
+
+	return &m, nil
+}
+
+// Load unconditionally reloads the data of the collection.
+func (c *Cache) Load(ctx context.Context) error {
+	meta, err := c.metadata(ctx)
+	if err != nil {
+		return err
+	}
+
+	ttl, err := ParseInterval(meta.ttl)
+	if err != nil {
+		return err
+	}
+
+	sc, err := c.client.NewReferenceScroller(c.kind, c.name)
+	if err != nil {
+		return err
+	}
+
+	elements := make(map[string]element, meta.count)
+	var networks []network
+	for sc.Next(ctx) {
+		e := sc.Result()
+		// values of the set are compared the way QRadar does, keys of the
+		// map are compared as is
+		key := e.Key
+		if c.kind == qradar.ReferenceKindSet {
+			key = qradar.NormalizeElement(meta.elementType, e.Value)
+			if _, n, err := net.ParseCIDR(e.Value); err == nil {
+				networks = append(networks, network{n, key})
+			}
+		}
+		elements[key] = element{
+			value:     e.Value,
+			firstSeen: e.FirstSeen,
+			lastSeen:  e.LastSeen,
+		}
+	}
+	if sc.Err() != nil {
+		return sc.Err()
+	}
+
+	c.mu.Lock()
+	c.elementType = meta.elementType
+	c.ttl = ttl
+	c.timeoutType = meta.timeoutType
+	c.count = meta.count
+	c.loadedAt = time.Now()
+	c.elements = elements
+	c.networks = networks
+	c.mu.Unlock()
+
+	return nil
+}
+
+// Contains returns true if the value is in the Reference Set or the key is in
+// the Reference Map and it's not expired.
+func (c *Cache) Contains(v string) bool {
+	_, ok := c.Get(v)
+	return ok
+}
+
+// Get returns the value of the key of the Reference Map, or the value itself
+// for the Reference Set, if it's not expired.
+func (c *Cache) Get(key string) (string, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if c.kind == qradar.ReferenceKindSet {
+		key = qradar.NormalizeElement(c.elementType, key)
+	}
+	e, ok := c.elements[key]
+	if !ok || c.expired(e, time.Now()) {
+		return "", false
+	}
+	return e.value, true
+}
+
+// ContainsIP returns true if the IP address is in the Reference Set either
+// as a value or within one of the CIDR values.
+func (c *Cache) ContainsIP(ip net.IP) bool {
+	if ip == nil {
+		return false
+	}
+	if c.Contains(ip.String()) {
+		return true
+	}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	now := time.Now()
+	for _, n := range c.networks {
+		if n.ipnet.Contains(ip) && !c.expired(c.elements[n.key], now) {
+			return true
+		}
+	}
+	return false
+}
+
+// Len returns the number of the loaded elements including the expired ones.
+func (c *Cache) Len() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return len(c.elements)
+}
+
+func (c *Cache) expired(e element, now time.Time) bool {
+	if c.ttl <= 0 {
+		return false
+	}
+	var since time.Time
+	switch c.timeoutType {
+	case TimeoutFirstSeen:
+		since = e.firstSeen
+	case TimeoutLastSeen:
+		since = e.lastSeen
+	default:
+		return false
+	}
+	if since.IsZero() {
+		return false
+	}
+	return now.After(since.Add(c.ttl))
+}
+
+var intervalPart = regexp.MustCompile(`(\d+(?:\.\d+)?)\s*(years?|mons?|months?|days?|hours?|mins?|minutes?|secs?|seconds?)`)
+
+// ParseInterval parses the TimeToLive of the Reference collection, e.g.
+// "1 mons 2 days 3 hours 0 mins 0.00 secs". Returns zero for an empty value.
+// Months are counted as 30 days and years as 365 days.
+func ParseInterval(s string) (time.Duration, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0, nil
+	}
+
+	matches := intervalPart.FindAllStringSubmatch(s, -1)
+	if len(matches) == 0 {
+		return 0, fmt.Errorf("invalid interval %q", s)
+	}
+
+	var d time.Duration
+	for _, m := range matches {
+		n, err := strconv.ParseFloat(m[1], 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid interval %q: %w", s, err)
+		}
+		var unit time.Duration
+		switch {
+		case strings.HasPrefix(m[2], "year"):
+			unit = 365 * 24 * time.Hour
+		case strings.HasPrefix(m[2], "mon"):
+			unit = 30 * 24 * time.Hour
+		case strings.HasPrefix(m[2], "day"):
+			unit = 24 * time.Hour
+		case strings.HasPrefix(m[2], "hour"):
+			unit = time.Hour
+		case strings.HasPrefix(m[2], "min"):
+			unit = time.Minute
+		default:
+			unit = time.Second
+		}
+		d += time.Duration(n * float64(unit))
+	}
+	return d, nil
+}
+
+func stringValue(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+func intValue(n *int) int {
+	if n == nil {
+		return 0
+	}
+	return *n
+}
@@ -0,0 +1,140 @@
+package refcache
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	qradar "github.com/ilyaglow/go-qradar"
+)
+
+func newClient(t *testing.T, responses map[string]string) *qradar.Client {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		resp, ok := responses[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(resp))
+	}))
+	t.Cleanup(srv.Close)
+	client, err := qradar.NewClient(srv.URL+"/", qradar.SetSECKey("test"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return client
+}
+
+func TestCacheGet(t *testing.T) {
+	responses := map[string]string{
+		"/api/reference_data/sets/ips":    `{"element_type":"IP","number_of_elements":2,"data":[{"value":"2001:DB8::1"},{"value":"10.0.0.0/8"}]}`,
+		"/api/reference_data/sets/names":  `{"element_type":"ALNIC","number_of_elements":1,"data":[{"value":"Admin"}]}`,
+		"/api/reference_data/maps/owners": `{"element_type":"ALNIC","number_of_elements":1,"data":{"Host":{"value":"Alice"}}}`,
+	}
+
+	tests := []struct {
+		kind   qradar.ReferenceKind
+		name   string
+		key    string
+		want   string
+		wantOK bool
+	}{
+		{qradar.ReferenceKindSet, "ips", "2001:db8:0::1", "2001:DB8::1", true},
+		{qradar.ReferenceKindSet, "ips", "10.0.0.1", "", false},
+		{qradar.ReferenceKindSet, "names", "ADMIN", "Admin", true},
+		{qradar.ReferenceKindMap, "owners", "Host", "Alice", true},
+		// keys of the map aren't normalized with the element type of
+		// the values
+		{qradar.ReferenceKindMap, "owners", "host", "", false},
+	}
+	for _, tt := range tests {
+		c, err := New(newClient(t, responses), tt.kind, tt.name, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = c.Load(context.Background())
+		if err != nil {
+			t.Fatalf("Load(%s %q): %v", tt.kind, tt.name, err)
+		}
+		got, ok := c.Get(tt.key)
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("%s %q: Get(%q) = %q, %v, want %q, %v", tt.kind, tt.name, tt.key, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
+
+func TestCacheContainsIP(t *testing.T) {
+	responses := map[string]string{
+		"/api/reference_data/sets/ips": `{"element_type":"IP","number_of_elements":2,"data":[{"value":"192.0.2.1"},{"value":"10.0.0.0/8"}]}`,
+	}
+	c, err := New(newClient(t, responses), qradar.ReferenceKindSet, "ips", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = c.Load(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		ip   string
+		want bool
+	}{
+		{"192.0.2.1", true},
+		{"10.1.2.3", true},
+		{"192.0.2.2", false},
+	}
+	for _, tt := range tests {
+		got := c.ContainsIP(net.ParseIP(tt.ip))
+		if got != tt.want {
+			t.Errorf("ContainsIP(%s) = %v, want %v", tt.ip, got, tt.want)
+		}
+	}
+}
+
+func TestParseInterval(t *testing.T) {
+	tests := []struct {
+		s       string
+		want    time.Duration
+		wantErr bool
+	}{
+		{"", 0, false},
+		{"1 mons 2 days 3 hours 0 mins 0.00 secs", 32*24*time.Hour + 3*time.Hour, false},
+		{"1 years", 365 * 24 * time.Hour, false},
+		{"90 minutes 1.5 secs", 90*time.Minute + 1500*time.Millisecond, false},
+		{"forever", 0, true},
+	}
+	for _, tt := range tests {
+		got, err := ParseInterval(tt.s)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("ParseInterval(%q) = %v, %v, want %v, wantErr %v", tt.s, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestCacheExpired(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		timeoutType string
+		ttl         time.Duration
+		e           element
+		want        bool
+	}{
+		{TimeoutFirstSeen, time.Hour, element{firstSeen: now.Add(-2 * time.Hour), lastSeen: now}, true},
+		{TimeoutLastSeen, time.Hour, element{firstSeen: now.Add(-2 * time.Hour), lastSeen: now}, false},
+		{TimeoutLastSeen, 0, element{lastSeen: now.Add(-2 * time.Hour)}, false},
+		{TimeoutFirstSeen, time.Hour, element{}, false},
+		{"UNKNOWN", time.Hour, element{firstSeen: now.Add(-2 * time.Hour)}, false},
+	}
+	for _, tt := range tests {
+		c := &Cache{ttl: tt.ttl, timeoutType: tt.timeoutType}
+		got := c.expired(tt.e, now)
+		if got != tt.want {
+			t.Errorf("expired(%s, %v, %+v) = %v, want %v", tt.timeoutType, tt.ttl, tt.e, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,256 @@
+package qradar
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Element types of the Reference data.
+const (
+	// ElementTypeIP IP address
+	ElementTypeIP = "IP"
+
+	// ElementTypePort port number
+	ElementTypePort = "PORT"
+
+	// ElementTypeNum numeric
+	ElementTypeNum = "NUM"
+
+	// ElementTypeALN alphanumeric
+	ElementTypeALN = "ALN"
+
+	// ElementTypeALNIC alphanumeric ignore case
+	ElementTypeALNIC = "ALNIC"
+
+	// ElementTypeDate date as milliseconds since epoch
+	ElementTypeDate = "DATE"
+)
+
+// BulkLoadChunkSize is a default number of elements sent in a single bulk
+// load request.
+var BulkLoadChunkSize = 10000
+
+// SetBulk represents bulk load payload of the Reference Set.
+type SetBulk []string
+
+// MapBulk represents bulk load payload of the Reference Map: key to value.
+type MapBulk map[string]string
+
+// MapOfSetsBulk represents bulk load payload of the Reference Map of Sets:
+// key to the set of values.
+type MapOfSetsBulk map[string][]string
+
+// TableBulk represents bulk load payload of the Reference Table: outer key to
+// inner key to value.
+type TableBulk map[string]map[string]string
+
+// ValidateElement checks the value matches the element type of the Reference data.
+// IP elements accept CIDR ranges as QRadar does.
+func ValidateElement(elementType, value string) error {
+	switch elementType {
+	case ElementTypeIP:
+		if net.ParseIP(value) != nil {
+			break
+		}
+		if _, _, err := net.ParseCIDR(value); err != nil {
+			return fmt.Errorf("%q is not a valid IP address or CIDR", value)
+		}
+	case ElementTypePort:
+		p, err := strconv.Atoi(value)
+		if err != nil || p < 0 || p > 65535 {
+			return fmt.Errorf("%q is not a valid port", value)
+		}
+	case ElementTypeNum:
+		if _, err := strconv.ParseFloat(value, 64); err != nil {
+			return fmt.Errorf("%q is not a valid number", value)
+		}
+	case ElementTypeDate:
+		if _, err := strconv.ParseInt(value, 10, 64); err != nil {
+			return fmt.Errorf("%q is not a valid date in milliseconds since epoch", value)
+		}
+	case ElementTypeALN, ElementTypeALNIC:
+		if strings.TrimSpace(value) == "" {
+			return fmt.Errorf("empty value")
+		}
+	default:
+		return fmt.Errorf("unknown element type %q", elementType)
+	}
+	return nil
+}
+
+// NormalizeElement returns the value in the form QRadar compares it for the
+// element type: IP addresses are canonicalized and ALNIC values lower-cased.
+// Other values are returned as is.
+func NormalizeElement(elementType, value string) string {
+	switch elementType {
+	case ElementTypeIP:
+		if ip := net.ParseIP(value); ip != nil {
+			return ip.String()
+		}
+	case ElementTypeALNIC:
+		return strings.ToLower(value)
+	}
+	return value
+}
+
+// Validate checks all values match the element type.
+func (b SetBulk) Validate(elementType string) error {
+	for _, v := range b {
+		if err := ValidateElement(elementType, v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Chunks splits the payload into chunks of the size.
+func (b SetBulk) Chunks(size int) []SetBulk {
+	if size <= 0 || len(b) <= size {
+		return []SetBulk{b}
+	}
+	var chunks []SetBulk
+	for i := 0; i < len(b); i += size {
+		end := i + size
+		if end > len(b) {
+			end = len(b)
+		}
+		chunks = append(chunks, b[i:end])
+	}
+	return chunks
+}
+
+// Validate checks all values match the element type.
+func (b MapBulk) Validate(elementType string) error {
+	for k, v := range b {
+		if err := ValidateElement(elementType, v); err != nil {
+			return fmt.Errorf("key %q: %w", k, err)
+		}
+	}
+	return nil
+}
+
+// Chunks splits the payload into chunks of the size.
+func (b MapBulk) Chunks(size int) []MapBulk {
+	if size <= 0 || len(b) <= size {
+		return []MapBulk{b}
+	}
+	var chunks []MapBulk
+	chunk := make(MapBulk)
+	for _, k := range sortedKeys(b) {
+		chunk[k] = b[k]
+		if len(chunk) == size {
+			chunks = append(chunks, chunk)
+			chunk = make(MapBulk)
+		}
+	}
+	if len(chunk) > 0 {
+		chunks = append(chunks, chunk)
+	}
+	return chunks
+}
+
+// Validate checks all values match the element type.
+func (b MapOfSetsBulk) Validate(elementType string) error {
+	for k, vs := range b {
+		for _, v := range vs {
+			if err := ValidateElement(elementType, v); err != nil {
+				return fmt.Errorf("key %q: %w", k, err)
+			}
+		}
+	}
+	return nil
+}
+
+// Chunks splits the payload into chunks of the size counting values of all
+// keys, so values of a large key can be spread over several chunks.
+func (b MapOfSetsBulk) Chunks(size int) []MapOfSetsBulk {
+	var chunks []MapOfSetsBulk
+	chunk := make(MapOfSetsBulk)
+	n := 0
+	for _, k := range sortedKeys(b) {
+		for _, v := range b[k] {
+			chunk[k] = append(chunk[k], v)
+			n++
+			if size > 0 && n == size {
+				chunks = append(chunks, chunk)
+				chunk = make(MapOfSetsBulk)
+				n = 0
+			}
+		}
+	}
+	if n > 0 || len(chunks) == 0 {
+		chunks = append(chunks, chunk)
+	}
+	return chunks
+}
+
+// Validate checks all values match the types of their inner keys. Inner keys
+// missing in keyNameTypes are validated against the defaultType.
+func (b TableBulk) Validate(defaultType string, keyNameTypes map[string]string) error {
+	for outer, inner := range b {
+		for ik, v := range inner {
+			t, found := keyNameTypes[ik]
+			if !found {
+				t = defaultType
+			}
+			if err := ValidateElement(t, v); err != nil {
+				return fmt.Errorf("key %q/%q: %w", outer, ik, err)
+			}
+		}
+	}
+	return nil
+}
+
+// Chunks splits the payload into chunks of the size counting inner keys of
+// all outer keys.
+func (b TableBulk) Chunks(size int) []TableBulk {
+	var chunks []TableBulk
+	chunk := make(TableBulk)
+	n := 0
+	for _, outer := range sortedKeys(b) {
+		inner := b[outer]
+		for _, ik := range sortedKeys(inner) {
+			if chunk[outer] == nil {
+				chunk[outer] = make(map[string]string)
+			}
+			chunk[outer][ik] = inner[ik]
+			n++
+			if size > 0 && n == size {
+				chunks = append(chunks, chunk)
+				chunk = make(TableBulk)
+				n = 0
+			}
+		}
+	}
+	if n > 0 || len(chunks) == 0 {
+		chunks = append(chunks, chunk)
+	}
+	return chunks
+}
+
+// marshalBulk encodes the bulk load payload without a trailing new line
+// since QRadar reference_data API errors on it.
+func marshalBulk(data interface{}) (json.RawMessage, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	err := enc.Encode(data)
+	if err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
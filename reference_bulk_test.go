@@ -0,0 +1,134 @@
+package qradar
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestValidateElement(t *testing.T) {
+	tests := []struct {
+		elementType string
+		value       string
+		wantErr     bool
+	}{
+		{ElementTypeIP, "10.0.0.1", false},
+		{ElementTypeIP, "2001:db8::1", false},
+		{ElementTypeIP, "10.0.0.0/8", false},
+		{ElementTypeIP, "2001:db8::/32", false},
+		{ElementTypeIP, "10.0.0.0/33", true},
+		{ElementTypeIP, "example.com", true},
+		{ElementTypePort, "443", false},
+		{ElementTypePort, "65536", true},
+		{ElementTypePort, "-1", true},
+		{ElementTypeNum, "3.14", false},
+		{ElementTypeNum, "pi", true},
+		{ElementTypeDate, "1700000000000", false},
+		{ElementTypeDate, "2023-01-01", true},
+		{ElementTypeALN, "value", false},
+		{ElementTypeALNIC, " ", true},
+		{"UNKNOWN", "value", true},
+	}
+	for _, tt := range tests {
+		err := ValidateElement(tt.elementType, tt.value)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ValidateElement(%q, %q) = %v, wantErr %v", tt.elementType, tt.value, err, tt.wantErr)
+		}
+	}
+}
+
+func TestNormalizeElement(t *testing.T) {
+	tests := []struct {
+		elementType string
+		value       string
+		want        string
+	}{
+		{ElementTypeIP, "2001:DB8:0:0::1", "2001:db8::1"},
+		{ElementTypeIP, "10.0.0.0/8", "10.0.0.0/8"},
+		{ElementTypeALNIC, "MiXeD", "mixed"},
+		{ElementTypeALN, "MiXeD", "MiXeD"},
+		{ElementTypeNum, "01", "01"},
+	}
+	for _, tt := range tests {
+		got := NormalizeElement(tt.elementType, tt.value)
+		if got != tt.want {
+			t.Errorf("NormalizeElement(%q, %q) = %q, want %q", tt.elementType, tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestSetBulkChunks(t *testing.T) {
+	tests := []struct {
+		b    SetBulk
+		size int
+		want []SetBulk
+	}{
+		{SetBulk{"a", "b", "c"}, 0, []SetBulk{{"a", "b", "c"}}},
+		{SetBulk{"a", "b", "c"}, 3, []SetBulk{{"a", "b", "c"}}},
+		{SetBulk{"a", "b", "c"}, 2, []SetBulk{{"a", "b"}, {"c"}}},
+	}
+	for _, tt := range tests {
+		if got := tt.b.Chunks(tt.size); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Chunks(%d) = %v, want %v", tt.size, got, tt.want)
+		}
+	}
+}
+
+func TestMapBulkChunks(t *testing.T) {
+	b := MapBulk{"c": "3", "a": "1", "b": "2"}
+	want := []MapBulk{{"a": "1", "b": "2"}, {"c": "3"}}
+	if got := b.Chunks(2); !reflect.DeepEqual(got, want) {
+		t.Errorf("Chunks(2) = %v, want %v", got, want)
+	}
+}
+
+func TestMapOfSetsBulkChunks(t *testing.T) {
+	tests := []struct {
+		b    MapOfSetsBulk
+		size int
+		want []MapOfSetsBulk
+	}{
+		{MapOfSetsBulk{}, 2, []MapOfSetsBulk{{}}},
+		{MapOfSetsBulk{"a": {"1", "2", "3"}, "b": {"4"}}, 2, []MapOfSetsBulk{{"a": {"1", "2"}}, {"a": {"3"}, "b": {"4"}}}},
+		{MapOfSetsBulk{"a": {"1", "2"}}, 0, []MapOfSetsBulk{{"a": {"1", "2"}}}},
+	}
+	for _, tt := range tests {
+		if got := tt.b.Chunks(tt.size); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Chunks(%d) = %v, want %v", tt.size, got, tt.want)
+		}
+	}
+}
+
+func TestTableBulk(t *testing.T) {
+	b := TableBulk{
+		"o1": {"ip": "10.0.0.0/24", "port": "22"},
+		"o2": {"ip": "10.0.0.2"},
+	}
+	types := map[string]string{"ip": ElementTypeIP, "port": ElementTypePort}
+	if err := b.Validate(ElementTypeALN, types); err != nil {
+		t.Errorf("Validate() = %v", err)
+	}
+	if err := (TableBulk{"o": {"port": "http"}}).Validate(ElementTypeALN, types); err == nil {
+		t.Error("Validate() of invalid port = nil")
+	}
+	if err := (TableBulk{"o": {"other": "x"}}).Validate(ElementTypeNum, types); err == nil {
+		t.Error("Validate() of the default type = nil")
+	}
+
+	want := []TableBulk{
+		{"o1": {"ip": "10.0.0.0/24", "port": "22"}},
+		{"o2": {"ip": "10.0.0.2"}},
+	}
+	if got := b.Chunks(2); !reflect.DeepEqual(got, want) {
+		t.Errorf("Chunks(2) = %v, want %v", got, want)
+	}
+}
+
+func TestMarshalBulk(t *testing.T) {
+	got, err := marshalBulk(MapBulk{"a": "<b>"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `{"a":"<b>"}`; string(got) != want {
+		t.Errorf("marshalBulk() = %s, want %s", got, want)
+	}
+}
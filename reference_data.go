@@ -0,0 +1,119 @@
+package qradar
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// ReferenceKind represents a kind of the Reference data collection.
+type ReferenceKind string
+
+const (
+	// ReferenceKindSet Reference Set
+	ReferenceKindSet ReferenceKind = "set"
+
+	// ReferenceKindMap Reference Map
+	ReferenceKindMap ReferenceKind = "map"
+
+	// ReferenceKindMapOfSets Reference Map of Sets
+	ReferenceKindMapOfSets ReferenceKind = "map_of_sets"
+
+	// ReferenceKindTable Reference Table
+	ReferenceKindTable ReferenceKind = "table"
+)
+
+// TaskStatus represents status of the asynchronous task: delete, etc.
+type TaskStatus string
+
+const (
+	// TaskStatusQueued queued
+	TaskStatusQueued TaskStatus = "QUEUED"
+
+	// TaskStatusProcessing processing
+	TaskStatusProcessing TaskStatus = "PROCESSING"
+
+	// TaskStatusCompleted completed
+	TaskStatusCompleted TaskStatus = "COMPLETED"
+
+	// TaskStatusCanceled canceled
+	TaskStatusCanceled TaskStatus = "CANCELED"
+
+	// TaskStatusException errored
+	TaskStatusException TaskStatus = "EXCEPTION"
+
+	// TaskStatusConflict conflicted
+	TaskStatusConflict TaskStatus = "CONFLICT"
+
+	// TaskStatusInterrupted interrupted
+	TaskStatusInterrupted TaskStatus = "INTERRUPTED"
+)
+
+// Finished returns true if the task is not going to change its status.
+func (s TaskStatus) Finished() bool {
+	switch s {
+	case TaskStatusQueued, TaskStatusProcessing, "":
+		return false
+	default:
+		return true
+	}
+}
+
+func (c *Client) deleteReferenceData(ctx context.Context, prefix, fields, name string, purgeOnly bool) (*DeleteTask, error) {
+	req, err := c.requestHelp(http.MethodDelete, prefix+"/"+url.PathEscape(name), fields, "", 0, 0, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if purgeOnly {
+		q := req.URL.Query()
+		q.Add("purge_only", "true")
+		req.URL.RawQuery = q.Encode()
+	}
+
+	var result DeleteTask
+	_, err = c.Do(ctx, req, &result)
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+func (c *Client) referenceDataDeleteTask(ctx context.Context, prefix, fields string, id int) (*DeleteTask, error) {
+	req, err := c.requestHelp(http.MethodGet, prefix+"/delete_tasks", fields, "", 0, 0, &id, nil)
+	if err != nil {
+		return nil, err
+	}
+	var result DeleteTask
+	_, err = c.Do(ctx, req, &result)
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+func (c *Client) waitForReferenceDataDeleteTask(ctx context.Context, prefix string, id, seconds int) (*DeleteTask, error) {
+	ticker := time.NewTicker(time.Duration(seconds) * time.Second)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-ticker.C:
+			t, err := c.referenceDataDeleteTask(ctx, prefix, "", id)
+			if err != nil {
+				return nil, err
+			}
+
+			if t.Status == nil {
+				return t, fmt.Errorf("delete task %d has no status", id)
+			}
+
+			if TaskStatus(*t.Status).Finished() {
+				return t, nil
+			}
+		}
+	}
+}
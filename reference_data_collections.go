@@ -0,0 +1,329 @@
+package qradar
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// ReferenceDataCollectionsService handles methods related to Reference Data
+// Collections of the QRadar API. Unlike the name based Reference data API,
+// collections and their entries are addressed by IDs and the entries carry
+// their own notes and expiry time.
+//
+// The endpoints need QRadar 7.5.0 or later. Requests are sent with the
+// ReferenceDataCollectionsAPIVersion unless the client is set to a later
+// version.
+type ReferenceDataCollectionsService service
+
+// ReferenceDataCollectionsAPIVersion is the minimal API version of the
+// Reference Data Collections endpoints.
+const ReferenceDataCollectionsAPIVersion = "17.0"
+
+const (
+	referenceDataCollectionsAPIPrefix = "api/reference_data_collections"
+
+	referenceCollectionSetsPath              = referenceDataCollectionsAPIPrefix + "/sets"
+	referenceCollectionMapsPath              = referenceDataCollectionsAPIPrefix + "/maps"
+	referenceCollectionSetEntriesPath        = referenceDataCollectionsAPIPrefix + "/set_entries"
+	referenceCollectionMapEntriesPath        = referenceDataCollectionsAPIPrefix + "/map_entries"
+	referenceCollectionSetDeleteTasksPath    = referenceDataCollectionsAPIPrefix + "/set_delete_tasks"
+	referenceCollectionMapDeleteTasksPath    = referenceDataCollectionsAPIPrefix + "/map_delete_tasks"
+	referenceCollectionSetBulkUpdateTaskPath = referenceDataCollectionsAPIPrefix + "/set_bulk_update_tasks"
+	referenceCollectionMapBulkUpdateTaskPath = referenceDataCollectionsAPIPrefix + "/map_bulk_update_tasks"
+)
+
+// ReferenceCollection represents QRadar's Reference Data Collection: a set or a map.
+type ReferenceCollection struct {
+	ID               *int    `json:"id,omitempty"`
+	Name             *string `json:"name,omitempty"`
+	Description      *string `json:"description,omitempty"`
+	EntryType        *string `json:"entry_type,omitempty"`
+	ExpiryType       *string `json:"expiry_type,omitempty"`
+	ExpiredLogOption *string `json:"expired_log_option,omitempty"`
+	TimeToLive       *string `json:"time_to_live,omitempty"`
+	Namespace        *string `json:"namespace,omitempty"`
+	TenantID         *int    `json:"tenant_id,omitempty"`
+	CreationTime     *int    `json:"creation_time,omitempty"`
+	KeyLabel         *string `json:"key_label,omitempty"`
+	ValueLabel       *string `json:"value_label,omitempty"`
+}
+
+// ReferenceCollectionEntry represents an entry of the Reference Data
+// Collection. Key is set only for the map entries.
+type ReferenceCollectionEntry struct {
+	ID           *int    `json:"id,omitempty"`
+	CollectionID *int    `json:"collection_id,omitempty"`
+	Key          *string `json:"key,omitempty"`
+	Value        *string `json:"value,omitempty"`
+	Source       *string `json:"source,omitempty"`
+	DomainID     *int    `json:"domain_id,omitempty"`
+	FirstSeen    *int    `json:"first_seen,omitempty"`
+	LastSeen     *int    `json:"last_seen,omitempty"`
+	ExpiryTime   *int    `json:"expiry_time,omitempty"`
+	Notes        *string `json:"notes,omitempty"`
+
+	// DeleteEntry marks the entry to be deleted by the bulk update.
+	DeleteEntry *bool `json:"delete_entry,omitempty"`
+}
+
+// ReferenceCollectionTask represents an asynchronous delete or bulk update
+// task of the Reference Data Collections.
+type ReferenceCollectionTask struct {
+	ID           *int    `json:"id,omitempty"`
+	CollectionID *int    `json:"collection_id,omitempty"`
+	Status       *string `json:"status,omitempty"`
+	Message      *string `json:"message,omitempty"`
+	CreatedBy    *string `json:"created_by,omitempty"`
+	Created      *int    `json:"created,omitempty"`
+	Started      *int    `json:"started,omitempty"`
+	Modified     *int    `json:"modified,omitempty"`
+	Completed    *int    `json:"completed,omitempty"`
+}
+
+// GetSets returns Reference Data Collection sets of the current QRadar installation.
+func (c *ReferenceDataCollectionsService) GetSets(ctx context.Context, fields, filter string, from, to int) ([]ReferenceCollection, error) {
+	return c.getCollections(ctx, referenceCollectionSetsPath, fields, filter, from, to)
+}
+
+// GetSetByID returns Reference Data Collection set by ID.
+func (c *ReferenceDataCollectionsService) GetSetByID(ctx context.Context, fields string, id int) (*ReferenceCollection, error) {
+	return c.collection(ctx, http.MethodGet, referenceCollectionSetsPath, fields, &id, nil)
+}
+
+// CreateSet creates Reference Data Collection set in QRadar installation.
+func (c *ReferenceDataCollectionsService) CreateSet(ctx context.Context, fields string, data *ReferenceCollection) (*ReferenceCollection, error) {
+	return c.collection(ctx, http.MethodPost, referenceCollectionSetsPath, fields, nil, data)
+}
+
+// UpdateSetByID updates Reference Data Collection set by ID.
+func (c *ReferenceDataCollectionsService) UpdateSetByID(ctx context.Context, fields string, id int, data *ReferenceCollection) (*ReferenceCollection, error) {
+	return c.collection(ctx, http.MethodPost, referenceCollectionSetsPath, fields, &id, data)
+}
+
+// DeleteSetByID creates a task in QRadar installation that deletes
+// Reference Data Collection set by ID.
+func (c *ReferenceDataCollectionsService) DeleteSetByID(ctx context.Context, fields string, id int) (*ReferenceCollectionTask, error) {
+	return c.task(ctx, http.MethodDelete, referenceCollectionSetsPath, fields, &id, nil)
+}
+
+// GetMaps returns Reference Data Collection maps of the current QRadar installation.
+func (c *ReferenceDataCollectionsService) GetMaps(ctx context.Context, fields, filter string, from, to int) ([]ReferenceCollection, error) {
+	return c.getCollections(ctx, referenceCollectionMapsPath, fields, filter, from, to)
+}
+
+// GetMapByID returns Reference Data Collection map by ID.
+func (c *ReferenceDataCollectionsService) GetMapByID(ctx context.Context, fields string, id int) (*ReferenceCollection, error) {
+	return c.collection(ctx, http.MethodGet, referenceCollectionMapsPath, fields, &id, nil)
+}
+
+// CreateMap creates Reference Data Collection map in QRadar installation.
+func (c *ReferenceDataCollectionsService) CreateMap(ctx context.Context, fields string, data *ReferenceCollection) (*ReferenceCollection, error) {
+	return c.collection(ctx, http.MethodPost, referenceCollectionMapsPath, fields, nil, data)
+}
+
+// UpdateMapByID updates Reference Data Collection map by ID.
+func (c *ReferenceDataCollectionsService) UpdateMapByID(ctx context.Context, fields string, id int, data *ReferenceCollection) (*ReferenceCollection, error) {
+	return c.collection(ctx, http.MethodPost, referenceCollectionMapsPath, fields, &id, data)
+}
+
+// DeleteMapByID creates a task in QRadar installation that deletes
+// Reference Data Collection map by ID.
+func (c *ReferenceDataCollectionsService) DeleteMapByID(ctx context.Context, fields string, id int) (*ReferenceCollectionTask, error) {
+	return c.task(ctx, http.MethodDelete, referenceCollectionMapsPath, fields, &id, nil)
+}
+
+// GetSetEntries returns entries of the Reference Data Collection sets,
+// use filter like collection_id=42 to select the set.
+func (c *ReferenceDataCollectionsService) GetSetEntries(ctx context.Context, fields, filter string, from, to int) ([]ReferenceCollectionEntry, error) {
+	return c.getEntries(ctx, referenceCollectionSetEntriesPath, fields, filter, from, to)
+}
+
+// GetSetEntryByID returns an entry of the Reference Data Collection set by ID.
+func (c *ReferenceDataCollectionsService) GetSetEntryByID(ctx context.Context, fields string, id int) (*ReferenceCollectionEntry, error) {
+	return c.entry(ctx, http.MethodGet, referenceCollectionSetEntriesPath, fields, &id, nil)
+}
+
+// CreateSetEntry creates an entry of the Reference Data Collection set.
+func (c *ReferenceDataCollectionsService) CreateSetEntry(ctx context.Context, fields string, data *ReferenceCollectionEntry) (*ReferenceCollectionEntry, error) {
+	return c.entry(ctx, http.MethodPost, referenceCollectionSetEntriesPath, fields, nil, data)
+}
+
+// UpdateSetEntryByID updates an entry of the Reference Data Collection set by ID.
+func (c *ReferenceDataCollectionsService) UpdateSetEntryByID(ctx context.Context, fields string, id int, data *ReferenceCollectionEntry) (*ReferenceCollectionEntry, error) {
+	return c.entry(ctx, http.MethodPost, referenceCollectionSetEntriesPath, fields, &id, data)
+}
+
+// DeleteSetEntryByID deletes an entry of the Reference Data Collection set by ID.
+func (c *ReferenceDataCollectionsService) DeleteSetEntryByID(ctx context.Context, fields string, id int) (*ReferenceCollectionEntry, error) {
+	return c.entry(ctx, http.MethodDelete, referenceCollectionSetEntriesPath, fields, &id, nil)
+}
+
+// PatchSetEntries creates a task in QRadar installation that creates,
+// updates and deletes (with DeleteEntry set) many set entries at once.
+func (c *ReferenceDataCollectionsService) PatchSetEntries(ctx context.Context, fields string, entries []ReferenceCollectionEntry) (*ReferenceCollectionTask, error) {
+	return c.task(ctx, http.MethodPatch, referenceCollectionSetEntriesPath, fields, nil, entries)
+}
+
+// GetMapEntries returns entries of the Reference Data Collection maps,
+// use filter like collection_id=42 to select the map.
+func (c *ReferenceDataCollectionsService) GetMapEntries(ctx context.Context, fields, filter string, from, to int) ([]ReferenceCollectionEntry, error) {
+	return c.getEntries(ctx, referenceCollectionMapEntriesPath, fields, filter, from, to)
+}
+
+// GetMapEntryByID returns an entry of the Reference Data Collection map by ID.
+func (c *ReferenceDataCollectionsService) GetMapEntryByID(ctx context.Context, fields string, id int) (*ReferenceCollectionEntry, error) {
+	return c.entry(ctx, http.MethodGet, referenceCollectionMapEntriesPath, fields, &id, nil)
+}
+
+// CreateMapEntry creates an entry of the Reference Data Collection map.
+func (c *ReferenceDataCollectionsService) CreateMapEntry(ctx context.Context, fields string, data *ReferenceCollectionEntry) (*ReferenceCollectionEntry, error) {
+	return c.entry(ctx, http.MethodPost, referenceCollectionMapEntriesPath, fields, nil, data)
+}
+
+// UpdateMapEntryByID updates an entry of the Reference Data Collection map by ID.
+func (c *ReferenceDataCollectionsService) UpdateMapEntryByID(ctx context.Context, fields string, id int, data *ReferenceCollectionEntry) (*ReferenceCollectionEntry, error) {
+	return c.entry(ctx, http.MethodPost, referenceCollectionMapEntriesPath, fields, &id, data)
+}
+
+// DeleteMapEntryByID deletes an entry of the Reference Data Collection map by ID.
+func (c *ReferenceDataCollectionsService) DeleteMapEntryByID(ctx context.Context, fields string, id int) (*ReferenceCollectionEntry, error) {
+	return c.entry(ctx, http.MethodDelete, referenceCollectionMapEntriesPath, fields, &id, nil)
+}
+
+// PatchMapEntries creates a task in QRadar installation that creates,
+// updates and deletes (with DeleteEntry set) many map entries at once.
+func (c *ReferenceDataCollectionsService) PatchMapEntries(ctx context.Context, fields string, entries []ReferenceCollectionEntry) (*ReferenceCollectionTask, error) {
+	return c.task(ctx, http.MethodPatch, referenceCollectionMapEntriesPath, fields, nil, entries)
+}
+
+// SetDeleteTask returns the status of the set delete task by ID.
+func (c *ReferenceDataCollectionsService) SetDeleteTask(ctx context.Context, fields string, id int) (*ReferenceCollectionTask, error) {
+	return c.task(ctx, http.MethodGet, referenceCollectionSetDeleteTasksPath, fields, &id, nil)
+}
+
+// MapDeleteTask returns the status of the map delete task by ID.
+func (c *ReferenceDataCollectionsService) MapDeleteTask(ctx context.Context, fields string, id int) (*ReferenceCollectionTask, error) {
+	return c.task(ctx, http.MethodGet, referenceCollectionMapDeleteTasksPath, fields, &id, nil)
+}
+
+// SetBulkUpdateTask returns the status of the set entries bulk update task by ID.
+func (c *ReferenceDataCollectionsService) SetBulkUpdateTask(ctx context.Context, fields string, id int) (*ReferenceCollectionTask, error) {
+	return c.task(ctx, http.MethodGet, referenceCollectionSetBulkUpdateTaskPath, fields, &id, nil)
+}
+
+// MapBulkUpdateTask returns the status of the map entries bulk update task by ID.
+func (c *ReferenceDataCollectionsService) MapBulkUpdateTask(ctx context.Context, fields string, id int) (*ReferenceCollectionTask, error) {
+	return c.task(ctx, http.MethodGet, referenceCollectionMapBulkUpdateTaskPath, fields, &id, nil)
+}
+
+// WaitForTask polls the task every number of seconds with the status
+// function, e.g. SetBulkUpdateTask, until it's finished.
+func (c *ReferenceDataCollectionsService) WaitForTask(
+	ctx context.Context,
+	status func(ctx context.Context, fields string, id int) (*ReferenceCollectionTask, error),
+	id, seconds int,
+) (*ReferenceCollectionTask, error) {
+	ticker := time.NewTicker(time.Duration(seconds) * time.Second)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-ticker.C:
+			t, err := status(ctx, "", id)
+			if err != nil {
+				return nil, err
+			}
+
+			if t.Status == nil {
+				return t, fmt.Errorf("task %d has no status", id)
+			}
+
+			if TaskStatus(*t.Status).Finished() {
+				return t, nil
+			}
+		}
+	}
+}
+
+func (c *ReferenceDataCollectionsService) getCollections(ctx context.Context, urlStr, fields, filter string, from, to int) ([]ReferenceCollection, error) {
+	req, err := c.request(http.MethodGet, urlStr, fields, filter, from, to, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	var result []ReferenceCollection
+	_, err = c.client.Do(ctx, req, &result)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (c *ReferenceDataCollectionsService) collection(ctx context.Context, method, urlStr, fields string, id *int, data interface{}) (*ReferenceCollection, error) {
+	req, err := c.request(method, urlStr, fields, "", 0, 0, id, data)
+	if err != nil {
+		return nil, err
+	}
+	var result ReferenceCollection
+	_, err = c.client.Do(ctx, req, &result)
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+func (c *ReferenceDataCollectionsService) getEntries(ctx context.Context, urlStr, fields, filter string, from, to int) ([]ReferenceCollectionEntry, error) {
+	req, err := c.request(http.MethodGet, urlStr, fields, filter, from, to, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	var result []ReferenceCollectionEntry
+	_, err = c.client.Do(ctx, req, &result)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (c *ReferenceDataCollectionsService) entry(ctx context.Context, method, urlStr, fields string, id *int, data interface{}) (*ReferenceCollectionEntry, error) {
+	req, err := c.request(method, urlStr, fields, "", 0, 0, id, data)
+	if err != nil {
+		return nil, err
+	}
+	var result ReferenceCollectionEntry
+	_, err = c.client.Do(ctx, req, &result)
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+func (c *ReferenceDataCollectionsService) task(ctx context.Context, method, urlStr, fields string, id *int, data interface{}) (*ReferenceCollectionTask, error) {
+	req, err := c.request(method, urlStr, fields, "", 0, 0, id, data)
+	if err != nil {
+		return nil, err
+	}
+	var result ReferenceCollectionTask
+	_, err = c.client.Do(ctx, req, &result)
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// request creates an API request with the version that supports the
+// Reference Data Collections endpoints.
+func (c *ReferenceDataCollectionsService) request(method, urlStr, fields, filter string, from, to int, id *int, data interface{}) (*http.Request, error) {
+	req, err := c.client.requestHelp(method, urlStr, fields, filter, from, to, id, data)
+	if err != nil {
+		return nil, err
+	}
+	v, err := strconv.ParseFloat(req.Header.Get("Version"), 64)
+	if min, _ := strconv.ParseFloat(ReferenceDataCollectionsAPIVersion, 64); err != nil || v < min {
+		req.Header.Set("Version", ReferenceDataCollectionsAPIVersion)
+	}
+	return req, nil
+}
@@ -0,0 +1,77 @@
+package qradar
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"testing"
+)
+
+func TestReferenceDataCollectionsVersion(t *testing.T) {
+	tests := []struct {
+		apiVersion string
+		want       string
+	}{
+		{"", ReferenceDataCollectionsAPIVersion},
+		{"12.0", ReferenceDataCollectionsAPIVersion},
+		{"19.0", "19.0"},
+	}
+	for _, tt := range tests {
+		var got string
+		c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+			got = r.Header.Get("Version")
+			w.Write([]byte(`[]`))
+		})
+		c.APIv = tt.apiVersion
+		_, err := c.ReferenceDataCollections.GetSets(context.Background(), "", "", 0, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != tt.want {
+			t.Errorf("Version with client API %q = %q, want %q", tt.apiVersion, got, tt.want)
+		}
+	}
+}
+
+func TestReferenceDataCollectionsPatchAndWait(t *testing.T) {
+	polls := 0
+	var patch string
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodPatch && r.URL.Path == "/"+referenceCollectionSetEntriesPath:
+			b, _ := io.ReadAll(r.Body)
+			patch = string(b)
+			w.Write([]byte(`{"id":7,"status":"QUEUED"}`))
+		case r.Method == http.MethodGet && r.URL.Path == "/"+referenceCollectionSetBulkUpdateTaskPath+"/7":
+			polls++
+			if polls < 2 {
+				w.Write([]byte(`{"id":7,"status":"PROCESSING"}`))
+				return
+			}
+			w.Write([]byte(`{"id":7,"status":"COMPLETED"}`))
+		default:
+			t.Errorf("unexpected %s %s", r.Method, r.URL.Path)
+		}
+	})
+
+	ctx := context.Background()
+	id, value, del := 3, "10.0.0.1", true
+	task, err := c.ReferenceDataCollections.PatchSetEntries(ctx, "", []ReferenceCollectionEntry{
+		{CollectionID: &id, Value: &value},
+		{ID: &id, DeleteEntry: &del},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `[{"collection_id":3,"value":"10.0.0.1"},{"id":3,"delete_entry":true}]` + "\n"; patch != want {
+		t.Errorf("patch body = %s, want %s", patch, want)
+	}
+
+	task, err = c.ReferenceDataCollections.WaitForTask(ctx, c.ReferenceDataCollections.SetBulkUpdateTask, *task.ID, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *task.Status != string(TaskStatusCompleted) || polls != 2 {
+		t.Errorf("status = %s after %d polls", *task.Status, polls)
+	}
+}
@@ -0,0 +1,91 @@
+package qradar
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"sync"
+	"testing"
+)
+
+func TestDeleteKeyScrollsAndDeletesValues(t *testing.T) {
+	tests := []struct {
+		name    string
+		path    string
+		body    string
+		delete  func(c *Client) error
+		deleted []string
+		wantErr bool
+	}{
+		{
+			name: "map of sets",
+			path: "/api/reference_data/map_of_sets/ms",
+			body: `{"number_of_elements":3,"data":{"a":[{"value":"1"},{"value":"2"}],"b":[{"value":"3"}]}}`,
+			delete: func(c *Client) error {
+				_, err := c.ReferenceMapOfSets.DeleteKey(context.Background(), "", "ms", "a")
+				return err
+			},
+			deleted: []string{"/api/reference_data/map_of_sets/ms/a?value=1", "/api/reference_data/map_of_sets/ms/a?value=2"},
+		},
+		{
+			name: "map of sets unknown key",
+			path: "/api/reference_data/map_of_sets/ms",
+			body: `{"number_of_elements":1,"data":{"b":[{"value":"3"}]}}`,
+			delete: func(c *Client) error {
+				_, err := c.ReferenceMapOfSets.DeleteKey(context.Background(), "", "ms", "a")
+				return err
+			},
+			wantErr: true,
+		},
+		{
+			name: "table",
+			path: "/api/reference_data/tables/t",
+			body: `{"number_of_elements":3,"data":{"o":{"x":{"value":"1"},"y":{"value":"2"}},"p":{"x":{"value":"3"}}}}`,
+			delete: func(c *Client) error {
+				_, err := c.ReferenceTable.DeleteOuterKey(context.Background(), "", "t", "o")
+				return err
+			},
+			deleted: []string{"/api/reference_data/tables/t/o/x?value=1", "/api/reference_data/tables/t/o/y?value=2"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var (
+				mu      sync.Mutex
+				deleted []string
+			)
+			c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+				switch r.Method {
+				case http.MethodGet:
+					if r.URL.Path != tt.path {
+						t.Errorf("unexpected GET %s", r.URL.Path)
+					}
+					if r.Header.Get("Range") == "" {
+						t.Errorf("GET %s without Range header", r.URL.Path)
+					}
+					w.Write([]byte(tt.body))
+				case http.MethodDelete:
+					mu.Lock()
+					deleted = append(deleted, r.URL.Path+"?"+r.URL.RawQuery)
+					mu.Unlock()
+					w.Write([]byte(`{}`))
+				}
+			})
+
+			err := tt.delete(c)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			sort.Strings(deleted)
+			if len(deleted) != len(tt.deleted) {
+				t.Fatalf("deleted %v, want %v", deleted, tt.deleted)
+			}
+			for i := range deleted {
+				if deleted[i] != tt.deleted[i] {
+					t.Errorf("deleted %v, want %v", deleted, tt.deleted)
+				}
+			}
+		})
+	}
+}
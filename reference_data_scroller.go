@@ -0,0 +1,221 @@
+package qradar
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// ReferenceDataWindow is a default window for scrolling data of the Reference
+// collections.
+var ReferenceDataWindow = 1000
+
+// ReferenceEntry represents a single element of the Reference collection.
+// Key is set for maps and maps of sets, OuterKey and Key are set for tables.
+type ReferenceEntry struct {
+	OuterKey  string    `json:"outer_key,omitempty"`
+	Key       string    `json:"key,omitempty"`
+	Value     string    `json:"value"`
+	Source    string    `json:"source,omitempty"`
+	FirstSeen time.Time `json:"first_seen"`
+	LastSeen  time.Time `json:"last_seen"`
+}
+
+// FirstSeenTime returns the time the element was first seen.
+func (d ReferenceData) FirstSeenTime() time.Time {
+	return millisToTime(d.FirstSeen)
+}
+
+// LastSeenTime returns the time the element was last seen.
+func (d ReferenceData) LastSeenTime() time.Time {
+	return millisToTime(d.LastSeen)
+}
+
+func (d ReferenceData) entry(outerKey, key string) ReferenceEntry {
+	e := ReferenceEntry{
+		OuterKey:  outerKey,
+		Key:       key,
+		FirstSeen: d.FirstSeenTime(),
+		LastSeen:  d.LastSeenTime(),
+	}
+	if d.Value != nil {
+		e.Value = *d.Value
+	}
+	if d.Source != nil {
+		e.Source = *d.Source
+	}
+	return e
+}
+
+func millisToTime(ms *int) time.Time {
+	if ms == nil || *ms == 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, int64(*ms)*int64(time.Millisecond))
+}
+
+// ReferenceDataScroller represents a scroller over the elements of the
+// Reference collection that keeps a single window of data in memory.
+type ReferenceDataScroller struct {
+	fetch   func(ctx context.Context, from, to int) ([]ReferenceEntry, int, error)
+	window  int
+	from    int
+	count   int
+	read    int
+	entries []ReferenceEntry
+	idx     int
+	done    bool
+	err     error
+}
+
+// Next returns true if an element is still available to be consumed by the
+// Result() method.
+func (s *ReferenceDataScroller) Next(ctx context.Context) bool {
+	if s.idx < len(s.entries) {
+		return true
+	}
+	if s.done {
+		return false
+	}
+
+	entries, count, err := s.fetch(ctx, s.from, s.from+s.window-1)
+	if err != nil {
+		s.err = err
+		s.done = true
+		return false
+	}
+
+	s.from += s.window
+	s.count = count
+	s.read += len(entries)
+	s.entries = entries
+	s.idx = 0
+
+	// a window holds at least as many entries as keys, so a short window is
+	// the last one, the number of elements also guards against the API
+	// ignoring the Range header
+	if len(entries) < s.window || (s.count > 0 && s.read >= s.count) {
+		s.done = true
+	}
+
+	return len(entries) > 0
+}
+
+// Result returns the element iterated by the Next.
+func (s *ReferenceDataScroller) Result() ReferenceEntry {
+	e := s.entries[s.idx]
+	s.idx++
+	return e
+}
+
+// Length returns the overall number of elements of the collection.
+func (s *ReferenceDataScroller) Length() int {
+	return s.count
+}
+
+// Err returns the error that stopped the scrolling.
+func (s *ReferenceDataScroller) Err() error {
+	return s.err
+}
+
+func newReferenceDataScroller(fetch func(ctx context.Context, from, to int) ([]ReferenceEntry, int, error)) *ReferenceDataScroller {
+	window := ReferenceDataWindow
+	if window <= 0 {
+		window = 1
+	}
+	return &ReferenceDataScroller{
+		fetch:  fetch,
+		window: window,
+	}
+}
+
+// NewScroller returns a scroller over the values of the Reference Set.
+func (c *ReferenceSetService) NewScroller(name string) *ReferenceDataScroller {
+	return newReferenceDataScroller(func(ctx context.Context, from, to int) ([]ReferenceEntry, int, error) {
+		r, err := c.GetWithData(ctx, "number_of_elements,data", "", name, from, to)
+		if err != nil {
+			return nil, 0, err
+		}
+		entries := make([]ReferenceEntry, 0, len(r.Data))
+		for _, d := range r.Data {
+			entries = append(entries, d.entry("", ""))
+		}
+		return entries, intValue(r.NumberOfElements), nil
+	})
+}
+
+// NewScroller returns a scroller over the keys of the Reference Map.
+func (c *ReferenceMapService) NewScroller(name string) *ReferenceDataScroller {
+	return newReferenceDataScroller(func(ctx context.Context, from, to int) ([]ReferenceEntry, int, error) {
+		r, err := c.GetWithData(ctx, "number_of_elements,data", "", name, from, to)
+		if err != nil {
+			return nil, 0, err
+		}
+		entries := make([]ReferenceEntry, 0, len(r.Data))
+		for _, k := range sortedKeys(r.Data) {
+			entries = append(entries, r.Data[k].entry("", k))
+		}
+		return entries, intValue(r.NumberOfElements), nil
+	})
+}
+
+// NewScroller returns a scroller over the values of the Reference Map of
+// Sets, an entry per value of every key.
+func (c *ReferenceMapOfSetsService) NewScroller(name string) *ReferenceDataScroller {
+	return newReferenceDataScroller(func(ctx context.Context, from, to int) ([]ReferenceEntry, int, error) {
+		r, err := c.GetWithData(ctx, "number_of_elements,data", "", name, from, to)
+		if err != nil {
+			return nil, 0, err
+		}
+		var entries []ReferenceEntry
+		for _, k := range sortedKeys(r.Data) {
+			for _, d := range r.Data[k] {
+				entries = append(entries, d.entry("", k))
+			}
+		}
+		return entries, intValue(r.NumberOfElements), nil
+	})
+}
+
+// NewScroller returns a scroller over the Reference Table, an entry per
+// inner key of every outer key.
+func (c *ReferenceTableService) NewScroller(name string) *ReferenceDataScroller {
+	return newReferenceDataScroller(func(ctx context.Context, from, to int) ([]ReferenceEntry, int, error) {
+		r, err := c.GetWithData(ctx, "number_of_elements,data", "", name, from, to)
+		if err != nil {
+			return nil, 0, err
+		}
+		var entries []ReferenceEntry
+		for _, outer := range sortedKeys(r.Data) {
+			inner := r.Data[outer]
+			for _, ik := range sortedKeys(inner) {
+				entries = append(entries, inner[ik].entry(outer, ik))
+			}
+		}
+		return entries, intValue(r.NumberOfElements), nil
+	})
+}
+
+// NewReferenceScroller returns a scroller over the Reference collection of
+// the kind.
+func (c *Client) NewReferenceScroller(kind ReferenceKind, name string) (*ReferenceDataScroller, error) {
+	switch kind {
+	case ReferenceKindSet:
+		return c.ReferenceSet.NewScroller(name), nil
+	case ReferenceKindMap:
+		return c.ReferenceMap.NewScroller(name), nil
+	case ReferenceKindMapOfSets:
+		return c.ReferenceMapOfSets.NewScroller(name), nil
+	case ReferenceKindTable:
+		return c.ReferenceTable.NewScroller(name), nil
+	default:
+		return nil, fmt.Errorf("unknown reference collection kind %q", kind)
+	}
+}
+
+func intValue(n *int) int {
+	if n == nil {
+		return 0
+	}
+	return *n
+}
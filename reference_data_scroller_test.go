@@ -0,0 +1,105 @@
+package qradar
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestReferenceDataScroller(t *testing.T) {
+	values := func(n int) []ReferenceEntry {
+		entries := make([]ReferenceEntry, n)
+		for i := range entries {
+			entries[i].Value = fmt.Sprint(i)
+		}
+		return entries
+	}
+
+	tests := []struct {
+		name    string
+		total   int
+		window  int
+		ranged  bool
+		windows []string
+		want    int
+	}{
+		{"empty", 0, 10, true, []string{"0-9"}, 0},
+		{"short window", 5, 10, true, []string{"0-9"}, 5},
+		{"exact windows", 20, 10, true, []string{"0-9", "10-19"}, 20},
+		{"several windows", 25, 10, true, []string{"0-9", "10-19", "20-29"}, 25},
+		// the count stops the scrolling if the API ignores the Range header
+		{"range ignored", 25, 10, false, []string{"0-9"}, 25},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			all := values(tt.total)
+			var windows []string
+			s := newReferenceDataScroller(func(ctx context.Context, from, to int) ([]ReferenceEntry, int, error) {
+				windows = append(windows, fmt.Sprintf("%d-%d", from, to))
+				if !tt.ranged {
+					return all, len(all), nil
+				}
+				if from >= len(all) {
+					return nil, len(all), nil
+				}
+				if to >= len(all) {
+					to = len(all) - 1
+				}
+				return all[from : to+1], len(all), nil
+			})
+			s.window = tt.window
+
+			n := 0
+			for s.Next(context.Background()) {
+				if e := s.Result(); e.Value != fmt.Sprint(n) {
+					t.Fatalf("entry %d = %q", n, e.Value)
+				}
+				n++
+			}
+			if s.Err() != nil {
+				t.Fatal(s.Err())
+			}
+			if n != tt.want {
+				t.Errorf("scrolled %d entries, want %d", n, tt.want)
+			}
+			if !reflect.DeepEqual(windows, tt.windows) {
+				t.Errorf("windows = %v, want %v", windows, tt.windows)
+			}
+			if s.Length() != tt.total {
+				t.Errorf("Length() = %d, want %d", s.Length(), tt.total)
+			}
+		})
+	}
+}
+
+func TestReferenceDataScrollerError(t *testing.T) {
+	errFetch := errors.New("fetch failed")
+	s := newReferenceDataScroller(func(ctx context.Context, from, to int) ([]ReferenceEntry, int, error) {
+		return nil, 0, errFetch
+	})
+	if s.Next(context.Background()) {
+		t.Error("Next() = true after an error")
+	}
+	if !errors.Is(s.Err(), errFetch) {
+		t.Errorf("Err() = %v, want %v", s.Err(), errFetch)
+	}
+}
+
+func TestReferenceTableScrollerEntries(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"number_of_elements":3,"data":{"b":{"y":{"value":"3"}},"a":{"y":{"value":"2"},"x":{"value":"1","first_seen":1000}}}}`))
+	})
+	s := c.ReferenceTable.NewScroller("t")
+	var got []string
+	for s.Next(context.Background()) {
+		e := s.Result()
+		got = append(got, e.OuterKey+"/"+e.Key+"="+e.Value)
+	}
+	want := []string{"a/x=1", "a/y=2", "b/y=3"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("entries = %v, want %v", got, want)
+	}
+}
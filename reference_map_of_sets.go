@@ -2,7 +2,9 @@ package qradar
 
 import (
 	"context"
+	"fmt"
 	"net/http"
+	"net/url"
 )
 
 // ReferenceMapOfSetsService handles methods related to Reference Maps of Sets of the QRadar API.
@@ -90,7 +92,11 @@ func (c *ReferenceMapOfSetsService) GetWithData(ctx context.Context, fields, fil
 
 // BulkLoad uploads many values in QRadar's Reference Map o Sets
 func (c *ReferenceMapOfSetsService) BulkLoad(ctx context.Context, fields, name string, data interface{}) (*ReferenceMapOfSets, error) {
-	req, err := c.client.requestHelp(http.MethodPost, referenceMapOfSetsServiceAPIPrefix+"/bulk_load/"+name, fields, "", 0, 0, nil, data)
+	body, err := marshalBulk(data)
+	if err != nil {
+		return nil, err
+	}
+	req, err := c.client.requestHelp(http.MethodPost, referenceMapOfSetsServiceAPIPrefix+"/bulk_load/"+name, fields, "", 0, 0, nil, body)
 	if err != nil {
 		return nil, err
 	}
@@ -101,3 +107,125 @@ func (c *ReferenceMapOfSetsService) BulkLoad(ctx context.Context, fields, name s
 	}
 	return &result, nil
 }
+
+// PutValue adds the value to the set under the key in QRadar's Reference Map of Sets.
+func (c *ReferenceMapOfSetsService) PutValue(ctx context.Context, fields, name, key, value, source string) (*ReferenceMapOfSets, error) {
+	req, err := c.client.requestHelp(http.MethodPost, referenceMapOfSetsServiceAPIPrefix+"/"+url.PathEscape(name), fields, "", 0, 0, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	q := req.URL.Query()
+	q.Add("key", key)
+	q.Add("value", value)
+	if source != "" {
+		q.Add("source", source)
+	}
+	req.URL.RawQuery = q.Encode()
+
+	var result ReferenceMapOfSets
+	_, err = c.client.Do(ctx, req, &result)
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// DeleteValue removes the value from the set under the key in QRadar's Reference Map of Sets.
+func (c *ReferenceMapOfSetsService) DeleteValue(ctx context.Context, fields, name, key, value string) (*ReferenceMapOfSets, error) {
+	req, err := c.client.requestHelp(http.MethodDelete, referenceMapOfSetsServiceAPIPrefix+"/"+url.PathEscape(name)+"/"+url.PathEscape(key), fields, "", 0, 0, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	q := req.URL.Query()
+	q.Add("value", value)
+	req.URL.RawQuery = q.Encode()
+
+	var result ReferenceMapOfSets
+	_, err = c.client.Do(ctx, req, &result)
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// DeleteKey removes the key with all its values from QRadar's Reference Map of Sets.
+// QRadar removes values one by one, so the values of the key are collected
+// first by scrolling the map one window at a time.
+func (c *ReferenceMapOfSetsService) DeleteKey(ctx context.Context, fields, name, key string) (*ReferenceMapOfSets, error) {
+	var values []string
+	found := false
+	sc := c.NewScroller(name)
+	for sc.Next(ctx) {
+		e := sc.Result()
+		if e.Key == key {
+			found = true
+			values = append(values, e.Value)
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, fmt.Errorf("no key %q in reference map of sets %q", key, name)
+	}
+
+	var result *ReferenceMapOfSets
+	for _, v := range values {
+		var err error
+		result, err = c.DeleteValue(ctx, fields, name, key, v)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+// Purge creates a Delete Task in QRadar installation that removes all keys
+// of the Reference Map of Sets and keeps the map itself.
+func (c *ReferenceMapOfSetsService) Purge(ctx context.Context, fields, name string) (*DeleteTask, error) {
+	return c.client.deleteReferenceData(ctx, referenceMapOfSetsServiceAPIPrefix, fields, name, true)
+}
+
+// Delete creates a Delete Task in QRadar installation that removes the
+// Reference Map of Sets.
+func (c *ReferenceMapOfSetsService) Delete(ctx context.Context, fields, name string) (*DeleteTask, error) {
+	return c.client.deleteReferenceData(ctx, referenceMapOfSetsServiceAPIPrefix, fields, name, false)
+}
+
+// DeleteTaskStatus returns the status of the Reference Map of Sets Delete Task by ID.
+func (c *ReferenceMapOfSetsService) DeleteTaskStatus(ctx context.Context, fields string, id int) (*DeleteTask, error) {
+	return c.client.referenceDataDeleteTask(ctx, referenceMapOfSetsServiceAPIPrefix, fields, id)
+}
+
+// WaitForDeleteTask polls the Reference Map of Sets Delete Task by ID every
+// number of seconds until it's finished.
+func (c *ReferenceMapOfSetsService) WaitForDeleteTask(ctx context.Context, id, seconds int) (*DeleteTask, error) {
+	return c.client.waitForReferenceDataDeleteTask(ctx, referenceMapOfSetsServiceAPIPrefix, id, seconds)
+}
+
+// BulkLoadTyped validates the values against the element type of QRadar's
+// Reference Map of Sets and uploads them in chunks of BulkLoadChunkSize elements.
+// Returns the Reference Map of Sets after the last chunk is loaded.
+func (c *ReferenceMapOfSetsService) BulkLoadTyped(ctx context.Context, fields, name string, data MapOfSetsBulk) (*ReferenceMapOfSets, error) {
+	current, err := c.GetWithData(ctx, "element_type", "", name, 0, 0)
+	if err != nil {
+		return nil, err
+	}
+	if current.ElementType == nil {
+		return nil, fmt.Errorf("reference map of sets %q has no element type", name)
+	}
+	if err := data.Validate(*current.ElementType); err != nil {
+		return nil, err
+	}
+
+	var result *ReferenceMapOfSets
+	for _, chunk := range data.Chunks(BulkLoadChunkSize) {
+		result, err = c.BulkLoad(ctx, fields, name, chunk)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
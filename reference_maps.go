@@ -2,7 +2,9 @@ package qradar
 
 import (
 	"context"
+	"fmt"
 	"net/http"
+	"net/url"
 )
 
 // ReferenceMapService handles methods related to Reference Map of the QRadar API.
@@ -90,10 +92,57 @@ func (c *ReferenceMapService) GetWithData(ctx context.Context, fields, filter, n
 
 // BulkLoad uploads many values in QRadar's Reference Map
 func (c *ReferenceMapService) BulkLoad(ctx context.Context, fields, name string, data interface{}) (*ReferenceMap, error) {
-	req, err := c.client.requestHelp(http.MethodPost, referenceMapServiceAPIPrefix+"/bulk_load/"+name, fields, "", 0, 0, nil, data)
+	body, err := marshalBulk(data)
 	if err != nil {
 		return nil, err
 	}
+	req, err := c.client.requestHelp(http.MethodPost, referenceMapServiceAPIPrefix+"/bulk_load/"+name, fields, "", 0, 0, nil, body)
+	if err != nil {
+		return nil, err
+	}
+	var result ReferenceMap
+	_, err = c.client.Do(ctx, req, &result)
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// PutValue adds or updates the value of the key in QRadar's Reference Map.
+func (c *ReferenceMapService) PutValue(ctx context.Context, fields, name, key, value, source string) (*ReferenceMap, error) {
+	req, err := c.client.requestHelp(http.MethodPost, referenceMapServiceAPIPrefix+"/"+url.PathEscape(name), fields, "", 0, 0, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	q := req.URL.Query()
+	q.Add("key", key)
+	q.Add("value", value)
+	if source != "" {
+		q.Add("source", source)
+	}
+	req.URL.RawQuery = q.Encode()
+
+	var result ReferenceMap
+	_, err = c.client.Do(ctx, req, &result)
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// DeleteKey removes the key from QRadar's Reference Map.
+// QRadar requires the current value of the key to be provided.
+func (c *ReferenceMapService) DeleteKey(ctx context.Context, fields, name, key, value string) (*ReferenceMap, error) {
+	req, err := c.client.requestHelp(http.MethodDelete, referenceMapServiceAPIPrefix+"/"+url.PathEscape(name)+"/"+url.PathEscape(key), fields, "", 0, 0, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	q := req.URL.Query()
+	q.Add("value", value)
+	req.URL.RawQuery = q.Encode()
+
 	var result ReferenceMap
 	_, err = c.client.Do(ctx, req, &result)
 	if err != nil {
@@ -101,3 +150,51 @@ func (c *ReferenceMapService) BulkLoad(ctx context.Context, fields, name string,
 	}
 	return &result, nil
 }
+
+// Purge creates a Delete Task in QRadar installation that removes all keys
+// of the Reference Map and keeps the map itself.
+func (c *ReferenceMapService) Purge(ctx context.Context, fields, name string) (*DeleteTask, error) {
+	return c.client.deleteReferenceData(ctx, referenceMapServiceAPIPrefix, fields, name, true)
+}
+
+// Delete creates a Delete Task in QRadar installation that removes the
+// Reference Map.
+func (c *ReferenceMapService) Delete(ctx context.Context, fields, name string) (*DeleteTask, error) {
+	return c.client.deleteReferenceData(ctx, referenceMapServiceAPIPrefix, fields, name, false)
+}
+
+// DeleteTaskStatus returns the status of the Reference Map Delete Task by ID.
+func (c *ReferenceMapService) DeleteTaskStatus(ctx context.Context, fields string, id int) (*DeleteTask, error) {
+	return c.client.referenceDataDeleteTask(ctx, referenceMapServiceAPIPrefix, fields, id)
+}
+
+// WaitForDeleteTask polls the Reference Map Delete Task by ID every number
+// of seconds until it's finished.
+func (c *ReferenceMapService) WaitForDeleteTask(ctx context.Context, id, seconds int) (*DeleteTask, error) {
+	return c.client.waitForReferenceDataDeleteTask(ctx, referenceMapServiceAPIPrefix, id, seconds)
+}
+
+// BulkLoadTyped validates the values against the element type of QRadar's
+// Reference Map and uploads them in chunks of BulkLoadChunkSize elements.
+// Returns the Reference Map after the last chunk is loaded.
+func (c *ReferenceMapService) BulkLoadTyped(ctx context.Context, fields, name string, data MapBulk) (*ReferenceMap, error) {
+	current, err := c.GetWithData(ctx, "element_type", "", name, 0, 0)
+	if err != nil {
+		return nil, err
+	}
+	if current.ElementType == nil {
+		return nil, fmt.Errorf("reference map %q has no element type", name)
+	}
+	if err := data.Validate(*current.ElementType); err != nil {
+		return nil, err
+	}
+
+	var result *ReferenceMap
+	for _, chunk := range data.Chunks(BulkLoadChunkSize) {
+		result, err = c.BulkLoad(ctx, fields, name, chunk)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
@@ -2,7 +2,9 @@ package qradar
 
 import (
 	"context"
+	"fmt"
 	"net/http"
+	"net/url"
 )
 
 // ReferenceSetService handles methods related to Reference sets of the QRadar API.
@@ -96,7 +98,11 @@ func (c *ReferenceSetService) GetWithData(ctx context.Context, fields, filter, n
 
 // BulkLoad uploads many values in QRadar's Reference Set
 func (c *ReferenceSetService) BulkLoad(ctx context.Context, fields, name string, data interface{}) (*ReferenceSet, error) {
-	req, err := c.client.requestHelp(http.MethodPost, referenceSetsServiceAPIPrefix+"/bulk_load/"+name, fields, "", 0, 0, nil, data)
+	body, err := marshalBulk(data)
+	if err != nil {
+		return nil, err
+	}
+	req, err := c.client.requestHelp(http.MethodPost, referenceSetsServiceAPIPrefix+"/bulk_load/"+name, fields, "", 0, 0, nil, body)
 	if err != nil {
 		return nil, err
 	}
@@ -107,3 +113,126 @@ func (c *ReferenceSetService) BulkLoad(ctx context.Context, fields, name string,
 	}
 	return &result, nil
 }
+
+// AddValue adds or updates a value in QRadar's Reference Set.
+func (c *ReferenceSetService) AddValue(ctx context.Context, fields, name, value, source string) (*ReferenceSet, error) {
+	req, err := c.client.requestHelp(http.MethodPost, referenceSetsServiceAPIPrefix+"/"+url.PathEscape(name), fields, "", 0, 0, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	q := req.URL.Query()
+	q.Add("value", value)
+	if source != "" {
+		q.Add("source", source)
+	}
+	req.URL.RawQuery = q.Encode()
+
+	var result ReferenceSet
+	_, err = c.client.Do(ctx, req, &result)
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// DeleteValue removes a value from QRadar's Reference Set.
+func (c *ReferenceSetService) DeleteValue(ctx context.Context, fields, name, value string) (*ReferenceSet, error) {
+	req, err := c.client.requestHelp(http.MethodDelete, referenceSetsServiceAPIPrefix+"/"+url.PathEscape(name)+"/"+url.PathEscape(value), fields, "", 0, 0, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	var result ReferenceSet
+	_, err = c.client.Do(ctx, req, &result)
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// Update updates TimeToLive and TimeoutType of QRadar's Reference Set by
+// name. The sets endpoint has no update of the set itself, so the set is
+// updated by ID with ReferenceDataCollectionsService.UpdateSetByID.
+func (c *ReferenceSetService) Update(ctx context.Context, fields, name string, data *ReferenceSet) (*ReferenceSet, error) {
+	filter := "name=" + filterString(name)
+	sets, err := c.client.ReferenceDataCollections.GetSets(ctx, "id", filter, 0, 0)
+	if err != nil {
+		return nil, err
+	}
+	if len(sets) != 1 || sets[0].ID == nil {
+		return nil, fmt.Errorf("found %d reference sets by %s", len(sets), filter)
+	}
+
+	update := &ReferenceCollection{}
+	if data != nil {
+		update.TimeToLive = data.TimeToLive
+		if data.TimeoutType != nil {
+			expiryType := *data.TimeoutType
+			if expiryType == "UNKNOWN" {
+				expiryType = "NO_EXPIRY"
+			}
+			update.ExpiryType = &expiryType
+		}
+	}
+	_, err = c.client.ReferenceDataCollections.UpdateSetByID(ctx, "id", *sets[0].ID, update)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := c.Get(ctx, fields, filter, 0, 0)
+	if err != nil {
+		return nil, err
+	}
+	if len(result) != 1 {
+		return nil, fmt.Errorf("found %d reference sets by %s", len(result), filter)
+	}
+	return &result[0], nil
+}
+
+// Purge creates a Delete Task in QRadar installation that removes all values
+// of the Reference Set and keeps the set itself.
+func (c *ReferenceSetService) Purge(ctx context.Context, fields, name string) (*DeleteTask, error) {
+	return c.client.deleteReferenceData(ctx, referenceSetsServiceAPIPrefix, fields, name, true)
+}
+
+// Delete creates a Delete Task in QRadar installation that removes the
+// Reference Set.
+func (c *ReferenceSetService) Delete(ctx context.Context, fields, name string) (*DeleteTask, error) {
+	return c.client.deleteReferenceData(ctx, referenceSetsServiceAPIPrefix, fields, name, false)
+}
+
+// DeleteTaskStatus returns the status of the Reference Set Delete Task by ID.
+func (c *ReferenceSetService) DeleteTaskStatus(ctx context.Context, fields string, id int) (*DeleteTask, error) {
+	return c.client.referenceDataDeleteTask(ctx, referenceSetsServiceAPIPrefix, fields, id)
+}
+
+// WaitForDeleteTask polls the Reference Set Delete Task by ID every number
+// of seconds until it's finished.
+func (c *ReferenceSetService) WaitForDeleteTask(ctx context.Context, id, seconds int) (*DeleteTask, error) {
+	return c.client.waitForReferenceDataDeleteTask(ctx, referenceSetsServiceAPIPrefix, id, seconds)
+}
+
+// BulkLoadTyped validates the values against the element type of QRadar's
+// Reference Set and uploads them in chunks of BulkLoadChunkSize elements.
+// Returns the Reference Set after the last chunk is loaded.
+func (c *ReferenceSetService) BulkLoadTyped(ctx context.Context, fields, name string, data SetBulk) (*ReferenceSet, error) {
+	current, err := c.GetWithData(ctx, "element_type", "", name, 0, 0)
+	if err != nil {
+		return nil, err
+	}
+	if current.ElementType == nil {
+		return nil, fmt.Errorf("reference set %q has no element type", name)
+	}
+	if err := data.Validate(*current.ElementType); err != nil {
+		return nil, err
+	}
+
+	var result *ReferenceSet
+	for _, chunk := range data.Chunks(BulkLoadChunkSize) {
+		result, err = c.BulkLoad(ctx, fields, name, chunk)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,59 @@
+package qradar
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestReferenceSetUpdate(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    *ReferenceSet
+		sets    string
+		want    string
+		wantErr bool
+	}{
+		{"ttl and timeout", &ReferenceSet{TimeToLive: strPtr("1 days"), TimeoutType: strPtr("LAST_SEEN")}, `[{"id":3}]`, `{"expiry_type":"LAST_SEEN","time_to_live":"1 days"}`, false},
+		{"unknown timeout", &ReferenceSet{TimeoutType: strPtr("UNKNOWN")}, `[{"id":3}]`, `{"expiry_type":"NO_EXPIRY"}`, false},
+		{"not found", &ReferenceSet{TimeToLive: strPtr("1 days")}, `[]`, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var update string
+			c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+				switch r.URL.Path {
+				case "/api/reference_data_collections/sets":
+					if f := r.URL.Query().Get("filter"); f != `name="bad \"ips\""` {
+						t.Errorf("filter %s", f)
+					}
+					w.Write([]byte(tt.sets))
+				case "/api/reference_data_collections/sets/3":
+					if r.Method != http.MethodPost {
+						t.Errorf("update method %s", r.Method)
+					}
+					body, _ := io.ReadAll(r.Body)
+					update = strings.TrimSpace(string(body))
+					w.Write([]byte(`{"id":3}`))
+				case "/api/reference_data/sets":
+					w.Write([]byte(`[{"name":"bad \"ips\"","time_to_live":"1 days"}]`))
+				default:
+					http.NotFound(w, r)
+				}
+			})
+
+			s, err := c.ReferenceSet.Update(context.Background(), "", `bad "ips"`, tt.data)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Update() error %v, wantErr %v", err, tt.wantErr)
+			}
+			if update != tt.want {
+				t.Errorf("update %s, want %s", update, tt.want)
+			}
+			if err == nil && (s.Name == nil || *s.Name != `bad "ips"`) {
+				t.Errorf("Update() = %+v", s)
+			}
+		})
+	}
+}
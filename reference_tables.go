@@ -2,7 +2,9 @@ package qradar
 
 import (
 	"context"
+	"fmt"
 	"net/http"
+	"net/url"
 )
 
 // ReferenceTableService handles methods related to Reference tables of the QRadar API.
@@ -21,6 +23,8 @@ type ReferenceTable struct {
 	TimeToLive       *string `json:"time_to_live,omitempty"`
 	TimeoutType      *string `json:"timeout_type,omitempty"`
 
+	KeyNameTypes map[string]string `json:"key_name_types,omitempty"`
+
 	Data map[string]map[string]ReferenceData `json:"data,omitempty"`
 }
 
@@ -88,10 +92,58 @@ func (c *ReferenceTableService) GetWithData(ctx context.Context, fields, filter,
 
 // BulkLoad uploads many values in QRadar's Reference Table
 func (c *ReferenceTableService) BulkLoad(ctx context.Context, fields, name string, data interface{}) (*ReferenceTable, error) {
-	req, err := c.client.requestHelp(http.MethodPost, referenceTableServiceAPIPrefix+"/bulk_load/"+name, fields, "", 0, 0, nil, data)
+	body, err := marshalBulk(data)
+	if err != nil {
+		return nil, err
+	}
+	req, err := c.client.requestHelp(http.MethodPost, referenceTableServiceAPIPrefix+"/bulk_load/"+name, fields, "", 0, 0, nil, body)
+	if err != nil {
+		return nil, err
+	}
+	var result ReferenceTable
+	_, err = c.client.Do(ctx, req, &result)
 	if err != nil {
 		return nil, err
 	}
+	return &result, nil
+}
+
+// PutValue adds or updates the value of the inner key under the outer key in QRadar's Reference Table.
+func (c *ReferenceTableService) PutValue(ctx context.Context, fields, name, outerKey, innerKey, value, source string) (*ReferenceTable, error) {
+	req, err := c.client.requestHelp(http.MethodPost, referenceTableServiceAPIPrefix+"/"+url.PathEscape(name), fields, "", 0, 0, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	q := req.URL.Query()
+	q.Add("outer_key", outerKey)
+	q.Add("inner_key", innerKey)
+	q.Add("value", value)
+	if source != "" {
+		q.Add("source", source)
+	}
+	req.URL.RawQuery = q.Encode()
+
+	var result ReferenceTable
+	_, err = c.client.Do(ctx, req, &result)
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// DeleteInnerKey removes the inner key under the outer key from QRadar's Reference Table.
+// QRadar requires the current value of the inner key to be provided.
+func (c *ReferenceTableService) DeleteInnerKey(ctx context.Context, fields, name, outerKey, innerKey, value string) (*ReferenceTable, error) {
+	req, err := c.client.requestHelp(http.MethodDelete, referenceTableServiceAPIPrefix+"/"+url.PathEscape(name)+"/"+url.PathEscape(outerKey)+"/"+url.PathEscape(innerKey), fields, "", 0, 0, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	q := req.URL.Query()
+	q.Add("value", value)
+	req.URL.RawQuery = q.Encode()
+
 	var result ReferenceTable
 	_, err = c.client.Do(ctx, req, &result)
 	if err != nil {
@@ -99,3 +151,81 @@ func (c *ReferenceTableService) BulkLoad(ctx context.Context, fields, name strin
 	}
 	return &result, nil
 }
+
+// DeleteOuterKey removes the outer key with all its inner keys from QRadar's Reference Table.
+// QRadar removes inner keys one by one, so the inner keys of the outer key
+// are collected first by scrolling the table one window at a time.
+func (c *ReferenceTableService) DeleteOuterKey(ctx context.Context, fields, name, outerKey string) (*ReferenceTable, error) {
+	var inner []ReferenceEntry
+	sc := c.NewScroller(name)
+	for sc.Next(ctx) {
+		e := sc.Result()
+		if e.OuterKey == outerKey {
+			inner = append(inner, e)
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	if len(inner) == 0 {
+		return nil, fmt.Errorf("no outer key %q in reference table %q", outerKey, name)
+	}
+
+	var result *ReferenceTable
+	for _, e := range inner {
+		var err error
+		result, err = c.DeleteInnerKey(ctx, fields, name, outerKey, e.Key, e.Value)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+// Purge creates a Delete Task in QRadar installation that removes all keys
+// of the Reference Table and keeps the table itself.
+func (c *ReferenceTableService) Purge(ctx context.Context, fields, name string) (*DeleteTask, error) {
+	return c.client.deleteReferenceData(ctx, referenceTableServiceAPIPrefix, fields, name, true)
+}
+
+// Delete creates a Delete Task in QRadar installation that removes the
+// Reference Table.
+func (c *ReferenceTableService) Delete(ctx context.Context, fields, name string) (*DeleteTask, error) {
+	return c.client.deleteReferenceData(ctx, referenceTableServiceAPIPrefix, fields, name, false)
+}
+
+// DeleteTaskStatus returns the status of the Reference Table Delete Task by ID.
+func (c *ReferenceTableService) DeleteTaskStatus(ctx context.Context, fields string, id int) (*DeleteTask, error) {
+	return c.client.referenceDataDeleteTask(ctx, referenceTableServiceAPIPrefix, fields, id)
+}
+
+// WaitForDeleteTask polls the Reference Table Delete Task by ID every number
+// of seconds until it's finished.
+func (c *ReferenceTableService) WaitForDeleteTask(ctx context.Context, id, seconds int) (*DeleteTask, error) {
+	return c.client.waitForReferenceDataDeleteTask(ctx, referenceTableServiceAPIPrefix, id, seconds)
+}
+
+// BulkLoadTyped validates the values against the element type of QRadar's
+// Reference Table and uploads them in chunks of BulkLoadChunkSize elements.
+// Returns the Reference Table after the last chunk is loaded.
+func (c *ReferenceTableService) BulkLoadTyped(ctx context.Context, fields, name string, data TableBulk) (*ReferenceTable, error) {
+	current, err := c.GetWithData(ctx, "element_type,key_name_types", "", name, 0, 0)
+	if err != nil {
+		return nil, err
+	}
+	if current.ElementType == nil {
+		return nil, fmt.Errorf("reference table %q has no element type", name)
+	}
+	if err := data.Validate(*current.ElementType, current.KeyNameTypes); err != nil {
+		return nil, err
+	}
+
+	var result *ReferenceTable
+	for _, chunk := range data.Chunks(BulkLoadChunkSize) {
+		result, err = c.BulkLoad(ctx, fields, name, chunk)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,204 @@
+package refio
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+
+	qradar "github.com/ilyaglow/go-qradar"
+)
+
+// CSVOptions represents the layout of the imported CSV file. Columns are
+// zero-based, unused columns are ignored.
+type CSVOptions struct {
+	// Comma is a field delimiter, defaults to ','.
+	Comma rune
+	// SkipHeader skips the first record.
+	SkipHeader bool
+	// OuterKeyColumn is a column of the outer key of the Reference Table.
+	OuterKeyColumn int
+	// KeyColumn is a column of the key of the Reference Map and Map of
+	// Sets or the inner key of the Reference Table.
+	KeyColumn int
+	// ValueColumn is a column of the value.
+	ValueColumn int
+}
+
+// RowError represents a rejected record of the imported file. Line is the
+// line of the CSV file the record starts at, for the values of the STIX
+// bundle it's the one-based position of the value among the values of its
+// observable.
+type RowError struct {
+	Line int
+	Err  error
+}
+
+// Error satisfies the error interface.
+func (e *RowError) Error() string {
+	return fmt.Sprintf("line %d: %s", e.Line, e.Err)
+}
+
+// ImportResult represents the outcome of the import.
+type ImportResult struct {
+	Loaded   int
+	Rejected []RowError
+}
+
+// ImportCSV loads the records of the CSV file into the existing Reference
+// collection of the kind. Values are validated against the element type of
+// the collection, invalid records are rejected and the rest is uploaded with
+// the bulk load.
+func ImportCSV(ctx context.Context, client *qradar.Client, kind qradar.ReferenceKind, name string, r io.Reader, opts CSVOptions) (*ImportResult, error) {
+	elementType, keyNameTypes, err := elementTypes(ctx, client, kind, name)
+	if err != nil {
+		return nil, err
+	}
+
+	cr := csv.NewReader(r)
+	if opts.Comma != 0 {
+		cr.Comma = opts.Comma
+	}
+	cr.FieldsPerRecord = -1
+
+	res := &ImportResult{}
+	sets := qradar.SetBulk{}
+	maps := qradar.MapBulk{}
+	mapsOfSets := qradar.MapOfSetsBulk{}
+	tables := qradar.TableBulk{}
+
+	for n := 0; ; n++ {
+		record, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if n == 0 && opts.SkipHeader {
+			continue
+		}
+		// the line the record starts at, quoted fields may span lines
+		line, _ := cr.FieldPos(0)
+
+		value, err := column(record, opts.ValueColumn)
+		if err != nil {
+			res.Rejected = append(res.Rejected, RowError{line, err})
+			continue
+		}
+
+		switch kind {
+		case qradar.ReferenceKindSet:
+			err = qradar.ValidateElement(elementType, value)
+			if err == nil {
+				sets = append(sets, value)
+			}
+		case qradar.ReferenceKindMap, qradar.ReferenceKindMapOfSets:
+			var key string
+			key, err = column(record, opts.KeyColumn)
+			if err == nil {
+				err = qradar.ValidateElement(elementType, value)
+			}
+			if err == nil && kind == qradar.ReferenceKindMap {
+				maps[key] = value
+			} else if err == nil {
+				mapsOfSets[key] = append(mapsOfSets[key], value)
+			}
+		case qradar.ReferenceKindTable:
+			var outerKey, innerKey string
+			outerKey, err = column(record, opts.OuterKeyColumn)
+			if err == nil {
+				innerKey, err = column(record, opts.KeyColumn)
+			}
+			if err == nil {
+				t, ok := keyNameTypes[innerKey]
+				if !ok {
+					t = elementType
+				}
+				err = qradar.ValidateElement(t, value)
+			}
+			if err == nil {
+				if tables[outerKey] == nil {
+					tables[outerKey] = make(map[string]string)
+				}
+				tables[outerKey][innerKey] = value
+			}
+		}
+		if err != nil {
+			res.Rejected = append(res.Rejected, RowError{line, err})
+			continue
+		}
+		res.Loaded++
+	}
+
+	if res.Loaded == 0 {
+		return res, nil
+	}
+
+	switch kind {
+	case qradar.ReferenceKindSet:
+		_, err = client.ReferenceSet.BulkLoadTyped(ctx, "name", name, sets)
+	case qradar.ReferenceKindMap:
+		_, err = client.ReferenceMap.BulkLoadTyped(ctx, "name", name, maps)
+	case qradar.ReferenceKindMapOfSets:
+		_, err = client.ReferenceMapOfSets.BulkLoadTyped(ctx, "name", name, mapsOfSets)
+	case qradar.ReferenceKindTable:
+		_, err = client.ReferenceTable.BulkLoadTyped(ctx, "name", name, tables)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+func column(record []string, i int) (string, error) {
+	if i < 0 || i >= len(record) {
+		return "", fmt.Errorf("no column %d", i)
+	}
+	return record[i], nil
+}
+
+// elementTypes returns the element type of the collection and the types of
+// the inner keys of the Reference Table.
+func elementTypes(ctx context.Context, client *qradar.Client, kind qradar.ReferenceKind, name string) (string, map[string]string, error) {
+	var elementType *string
+	var keyNameTypes map[string]string
+
+	switch kind {
+	case qradar.ReferenceKindSet:
+		s, err := client.ReferenceSet.GetWithData(ctx, "element_type", "", name, 0, 0)
+		if err != nil {
+			return "", nil, err
+		}
+		elementType = s.ElementType
+	case qradar.ReferenceKindMap:
+		m, err := client.ReferenceMap.GetWithData(ctx, "element_type", "", name, 0, 0)
+		if err != nil {
+			return "", nil, err
+		}
+		elementType = m.ElementType
+	case qradar.ReferenceKindMapOfSets:
+		m, err := client.ReferenceMapOfSets.GetWithData(ctx, "element_type", "", name, 0, 0)
+		if err != nil {
+			return "", nil, err
+		}
+		elementType = m.ElementType
+	case qradar.ReferenceKindTable:
+		t, err := client.ReferenceTable.GetWithData(ctx, "element_type,key_name_types", "", name, 0, 0)
+		if err != nil {
+			return "", nil, err
+		}
+		elementType = t.ElementType
+		keyNameTypes = t.KeyNameTypes
+	default:
+		return "", nil, fmt.Errorf("unknown reference collection kind %q", kind)
+	}
+
+	if elementType == nil {
+		return "", nil, fmt.Errorf("reference %s %q has no element type", kind, name)
+	}
+
+	return *elementType, keyNameTypes, nil
+}
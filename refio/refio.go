@@ -0,0 +1,124 @@
+// Package refio imports and exports QRadar's Reference data collections as
+// CSV, JSON and STIX 2.1 bundles.
+package refio
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	qradar "github.com/ilyaglow/go-qradar"
+)
+
+// Format of the exported data.
+type Format string
+
+const (
+	// FormatCSV comma separated values with a header
+	FormatCSV Format = "csv"
+
+	// FormatJSON array of the entries
+	FormatJSON Format = "json"
+)
+
+// Export writes all elements of the Reference collection of the kind to w in
+// the format. Elements are streamed window by window, so collections of any
+// size can be exported.
+func Export(ctx context.Context, client *qradar.Client, kind qradar.ReferenceKind, name string, format Format, w io.Writer) error {
+	sc, err := client.NewReferenceScroller(kind, name)
+	if err != nil {
+		return err
+	}
+
+	switch format {
+	case FormatCSV:
+		err = exportCSV(ctx, sc, kind, w)
+	case FormatJSON:
+		err = exportJSON(ctx, sc, w)
+	default:
+		return fmt.Errorf("unknown export format %q", format)
+	}
+	if err != nil {
+		return err
+	}
+
+	return sc.Err()
+}
+
+func csvHeader(kind qradar.ReferenceKind) []string {
+	switch kind {
+	case qradar.ReferenceKindMap, qradar.ReferenceKindMapOfSets:
+		return []string{"key", "value", "source", "first_seen", "last_seen"}
+	case qradar.ReferenceKindTable:
+		return []string{"outer_key", "inner_key", "value", "source", "first_seen", "last_seen"}
+	default:
+		return []string{"value", "source", "first_seen", "last_seen"}
+	}
+}
+
+func exportCSV(ctx context.Context, sc *qradar.ReferenceDataScroller, kind qradar.ReferenceKind, w io.Writer) error {
+	cw := csv.NewWriter(w)
+	err := cw.Write(csvHeader(kind))
+	if err != nil {
+		return err
+	}
+
+	for sc.Next(ctx) {
+		e := sc.Result()
+		var record []string
+		switch kind {
+		case qradar.ReferenceKindMap, qradar.ReferenceKindMapOfSets:
+			record = append(record, e.Key)
+		case qradar.ReferenceKindTable:
+			record = append(record, e.OuterKey, e.Key)
+		}
+		record = append(record, e.Value, e.Source, formatTime(e.FirstSeen), formatTime(e.LastSeen))
+		err = cw.Write(record)
+		if err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+func exportJSON(ctx context.Context, sc *qradar.ReferenceDataScroller, w io.Writer) error {
+	_, err := io.WriteString(w, "[")
+	if err != nil {
+		return err
+	}
+
+	first := true
+	for sc.Next(ctx) {
+		e := sc.Result()
+		bs, err := json.Marshal(e)
+		if err != nil {
+			return err
+		}
+		if !first {
+			_, err = io.WriteString(w, ",\n")
+			if err != nil {
+				return err
+			}
+		}
+		first = false
+		_, err = w.Write(bs)
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err = io.WriteString(w, "]\n")
+	return err
+}
+
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
@@ -0,0 +1,188 @@
+package refio
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	qradar "github.com/ilyaglow/go-qradar"
+)
+
+// newClient returns a client of the test server that serves the GET
+// responses by path and records the bodies of the other requests.
+func newClient(t *testing.T, responses map[string]string, bodies *[]string) *qradar.Client {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			b, _ := io.ReadAll(r.Body)
+			*bodies = append(*bodies, r.URL.Path+" "+string(b))
+			w.Write([]byte(`{}`))
+			return
+		}
+		resp, ok := responses[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(resp))
+	}))
+	t.Cleanup(srv.Close)
+	client, err := qradar.NewClient(srv.URL+"/", qradar.SetSECKey("test"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return client
+}
+
+func TestImportCSV(t *testing.T) {
+	responses := map[string]string{
+		"/api/reference_data/sets/ips": `{"element_type":"IP"}`,
+		"/api/reference_data/maps/m":   `{"element_type":"ALN"}`,
+	}
+	tests := []struct {
+		name     string
+		kind     qradar.ReferenceKind
+		collName string
+		input    string
+		opts     CSVOptions
+		loaded   int
+		rejected []int
+		body     string
+	}{
+		{
+			name:     "set with header",
+			kind:     qradar.ReferenceKindSet,
+			collName: "ips",
+			input:    "ip\n10.0.0.1\nnot-an-ip\n10.0.0.0/24\n",
+			opts:     CSVOptions{SkipHeader: true},
+			loaded:   2,
+			rejected: []int{3},
+			body:     `/api/reference_data/sets/bulk_load/ips ["10.0.0.1","10.0.0.0/24"]`,
+		},
+		{
+			name:     "multi-line quoted fields",
+			kind:     qradar.ReferenceKindMap,
+			collName: "m",
+			input:    "k1,\"line one\nline two\"\n\"k\n2\"\nk3,v3\n",
+			opts:     CSVOptions{KeyColumn: 0, ValueColumn: 1},
+			loaded:   2,
+			rejected: []int{3},
+			body:     `/api/reference_data/maps/bulk_load/m {"k1":"line one\nline two","k3":"v3"}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var bodies []string
+			client := newClient(t, responses, &bodies)
+			res, err := ImportCSV(context.Background(), client, tt.kind, tt.collName, strings.NewReader(tt.input), tt.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if res.Loaded != tt.loaded {
+				t.Errorf("Loaded = %d, want %d", res.Loaded, tt.loaded)
+			}
+			var lines []int
+			for _, r := range res.Rejected {
+				lines = append(lines, r.Line)
+			}
+			if !reflect.DeepEqual(lines, tt.rejected) {
+				t.Errorf("rejected lines = %v, want %v", lines, tt.rejected)
+			}
+			if len(bodies) != 1 || bodies[0] != tt.body {
+				t.Errorf("bulk loads = %q, want %q", bodies, tt.body)
+			}
+		})
+	}
+}
+
+func TestStixUnsupported(t *testing.T) {
+	tests := []struct {
+		pattern string
+		want    bool
+	}{
+		{`[ipv4-addr:value = '1.2.3.4']`, false},
+		{`[ipv4-addr:value = '1.2.3.4' OR ipv4-addr:value = '5.6.7.8']`, false},
+		{`[url:value = 'http://x/?a=1 AND b=2']`, false},
+		{`[url:value = 'it\'s AND more']`, false},
+		{`[ipv4-addr:value = '1.2.3.4' AND network-traffic:dst_port = 443]`, true},
+		{`[ipv4-addr:value = '1.2.3.4'] FOLLOWEDBY [domain-name:value = 'x.com']`, true},
+		{`([ipv4-addr:value = '1.2.3.4'])AND([domain-name:value = 'x.com'])`, true},
+		{`[ipv4-addr:value NOT = '1.2.3.4']`, true},
+		{`[NOT ipv4-addr:value = '1.2.3.4']`, true},
+		{`[domain-name:value = 'NOT.example']`, false},
+	}
+	for _, tt := range tests {
+		if got := stixUnsupported(tt.pattern); got != tt.want {
+			t.Errorf("stixUnsupported(%q) = %v, want %v", tt.pattern, got, tt.want)
+		}
+	}
+}
+
+func TestParseSTIX(t *testing.T) {
+	bundle := `{"type":"bundle","objects":[
+		{"type":"indicator","id":"i1","pattern_type":"stix","pattern":"[ipv4-addr:value = '1.2.3.4' OR ipv4-addr:value = '1.2.3.4']"},
+		{"type":"indicator","id":"i2","pattern":"[file:hashes.'SHA-256' = 'abc']"},
+		{"type":"indicator","id":"i3","pattern":"[url:value = 'http://x/?q=a AND b']"},
+		{"type":"indicator","id":"i4","pattern":"[ipv4-addr:value = '5.6.7.8' AND network-traffic:dst_port = 443]"},
+		{"type":"indicator","id":"i5","pattern_type":"sigma","pattern":"title: x"},
+		{"type":"indicator","id":"i6","revoked":true,"pattern":"[ipv4-addr:value = '9.9.9.9']"},
+		{"type":"indicator","id":"i7","pattern":"[NOT ipv4-addr:value = '8.8.8.8']"},
+		{"type":"malware","id":"m1"}
+	]}`
+	v, err := ParseSTIX(strings.NewReader(bundle))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]qradar.SetBulk{
+		STIXIPv4:   {"1.2.3.4"},
+		STIXSHA256: {"abc"},
+		STIXURL:    {"http://x/?q=a AND b"},
+	}
+	if !reflect.DeepEqual(v.Values, want) {
+		t.Errorf("Values = %v, want %v", v.Values, want)
+	}
+	if !reflect.DeepEqual(v.Skipped, []string{"i4", "i5", "i7"}) {
+		t.Errorf("Skipped = %v", v.Skipped)
+	}
+
+	if _, err := ParseSTIX(strings.NewReader(`{"type":"indicator"}`)); err == nil {
+		t.Error("ParseSTIX() of not a bundle = nil error")
+	}
+}
+
+func TestExport(t *testing.T) {
+	tests := []struct {
+		format Format
+		data   string
+		want   string
+	}{
+		{
+			FormatCSV,
+			`{"number_of_elements":2,"data":{"b":{"value":"2","source":"s"},"a":{"value":"1","first_seen":1700000000000}}}`,
+			"key,value,source,first_seen,last_seen\na,1,,2023-11-14T22:13:20Z,\nb,2,s,,\n",
+		},
+		{
+			FormatJSON,
+			`{"number_of_elements":2,"data":{"b":{"value":"2","source":"s"},"a":{"value":"1"}}}`,
+			`[{"key":"a","value":"1","first_seen":"0001-01-01T00:00:00Z","last_seen":"0001-01-01T00:00:00Z"},` + "\n" +
+				`{"key":"b","value":"2","source":"s","first_seen":"0001-01-01T00:00:00Z","last_seen":"0001-01-01T00:00:00Z"}]` + "\n",
+		},
+	}
+	for _, tt := range tests {
+		var bodies []string
+		client := newClient(t, map[string]string{"/api/reference_data/maps/m": tt.data}, &bodies)
+		var buf bytes.Buffer
+		err := Export(context.Background(), client, qradar.ReferenceKindMap, "m", tt.format, &buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if buf.String() != tt.want {
+			t.Errorf("Export(%s) =\n%s\nwant\n%s", tt.format, buf.String(), tt.want)
+		}
+	}
+}
@@ -0,0 +1,190 @@
+package refio
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+
+	qradar "github.com/ilyaglow/go-qradar"
+)
+
+// Observables of the STIX indicator patterns.
+const (
+	STIXIPv4   = "ipv4-addr"
+	STIXIPv6   = "ipv6-addr"
+	STIXDomain = "domain-name"
+	STIXURL    = "url"
+	STIXMD5    = "file:hashes.MD5"
+	STIXSHA1   = "file:hashes.SHA-1"
+	STIXSHA256 = "file:hashes.SHA-256"
+)
+
+// stixComparison matches a single equality comparison of the STIX pattern,
+// e.g. ipv4-addr:value = '1.2.3.4' or file:hashes.'SHA-256' = '...'.
+var stixComparison = regexp.MustCompile(`([a-z0-9-]+):([A-Za-z0-9_.'-]+)\s*=\s*'((?:[^'\\]|\\.)*)'`)
+
+type stixBundle struct {
+	Type    string       `json:"type"`
+	Objects []stixObject `json:"objects"`
+}
+
+type stixObject struct {
+	Type        string `json:"type"`
+	ID          string `json:"id"`
+	Pattern     string `json:"pattern"`
+	PatternType string `json:"pattern_type"`
+	Revoked     bool   `json:"revoked"`
+}
+
+// STIXValues represents values extracted from the STIX indicators grouped by
+// the observable, e.g. STIXIPv4 or STIXSHA256.
+type STIXValues struct {
+	Values map[string]qradar.SetBulk
+	// Skipped are IDs of the indicators with unsupported patterns.
+	Skipped []string
+}
+
+// ParseSTIX extracts values of the indicators of the STIX 2.1 bundle.
+// Only patterns of equality comparisons joined with OR are supported, since
+// the conditions joined with AND and the negated comparisons can't be
+// expressed by a Reference Set. Revoked indicators are ignored.
+func ParseSTIX(r io.Reader) (*STIXValues, error) {
+	var b stixBundle
+	err := json.NewDecoder(r).Decode(&b)
+	if err != nil {
+		return nil, err
+	}
+	if b.Type != "bundle" {
+		return nil, fmt.Errorf("not a STIX bundle: type %q", b.Type)
+	}
+
+	res := &STIXValues{Values: make(map[string]qradar.SetBulk)}
+	seen := make(map[string]map[string]struct{})
+
+	for _, o := range b.Objects {
+		if o.Type != "indicator" || o.Revoked {
+			continue
+		}
+		if o.PatternType != "" && o.PatternType != "stix" {
+			res.Skipped = append(res.Skipped, o.ID)
+			continue
+		}
+		if stixUnsupported(o.Pattern) {
+			res.Skipped = append(res.Skipped, o.ID)
+			continue
+		}
+
+		matches := stixComparison.FindAllStringSubmatch(o.Pattern, -1)
+		if len(matches) == 0 {
+			res.Skipped = append(res.Skipped, o.ID)
+			continue
+		}
+
+		for _, m := range matches {
+			key := stixObservable(m[1], m[2])
+			value := strings.NewReplacer(`\'`, `'`, `\\`, `\`).Replace(m[3])
+			if seen[key] == nil {
+				seen[key] = make(map[string]struct{})
+			}
+			if _, ok := seen[key][value]; ok {
+				continue
+			}
+			seen[key][value] = struct{}{}
+			res.Values[key] = append(res.Values[key], value)
+		}
+	}
+
+	return res, nil
+}
+
+// stixUnsupported returns true if the pattern joins the comparisons or the
+// observations with AND or FOLLOWEDBY or negates a comparison with NOT.
+// Quoted values are skipped, so a value containing the keywords doesn't count.
+func stixUnsupported(pattern string) bool {
+	var unquoted strings.Builder
+	quoted := false
+	for i := 0; i < len(pattern); i++ {
+		c := pattern[i]
+		switch {
+		case quoted && c == '\\':
+			i++
+		case c == '\'':
+			quoted = !quoted
+			unquoted.WriteByte(' ')
+		case !quoted:
+			unquoted.WriteByte(c)
+		}
+	}
+	for _, w := range strings.Fields(strings.NewReplacer("(", " ", ")", " ", "[", " ", "]", " ").Replace(unquoted.String())) {
+		if w == "AND" || w == "FOLLOWEDBY" || w == "NOT" {
+			return true
+		}
+	}
+	return false
+}
+
+// stixObservable returns the observable key of the object path.
+func stixObservable(object, path string) string {
+	path = strings.ReplaceAll(path, "'", "")
+	if path == "value" {
+		return object
+	}
+	return object + ":" + path
+}
+
+// ImportSTIX loads the values of the STIX 2.1 bundle indicators into the
+// Reference Sets. Targets map the observable, e.g. STIXIPv4, to the name of
+// the Reference Set, values of the observables without a target are ignored.
+// Invalid values are rejected with the observable in the error and, in place
+// of the line, the one-based position of the value among the values of the
+// observable.
+func ImportSTIX(ctx context.Context, client *qradar.Client, r io.Reader, targets map[string]string) (map[string]*ImportResult, error) {
+	values, err := ParseSTIX(r)
+	if err != nil {
+		return nil, err
+	}
+
+	observables := make([]string, 0, len(targets))
+	for o := range targets {
+		observables = append(observables, o)
+	}
+	sort.Strings(observables)
+
+	results := make(map[string]*ImportResult)
+	for _, o := range observables {
+		name := targets[o]
+		res := &ImportResult{}
+		results[o] = res
+
+		elementType, _, err := elementTypes(ctx, client, qradar.ReferenceKindSet, name)
+		if err != nil {
+			return results, err
+		}
+
+		var valid qradar.SetBulk
+		for i, v := range values.Values[o] {
+			err = qradar.ValidateElement(elementType, v)
+			if err != nil {
+				res.Rejected = append(res.Rejected, RowError{i + 1, fmt.Errorf("%s: %w", o, err)})
+				continue
+			}
+			valid = append(valid, v)
+		}
+
+		if len(valid) == 0 {
+			continue
+		}
+
+		_, err = client.ReferenceSet.BulkLoadTyped(ctx, "name", name, valid)
+		if err != nil {
+			return results, err
+		}
+		res.Loaded = len(valid)
+	}
+
+	return results, nil
+}
@@ -0,0 +1,425 @@
+// Package refsync reconciles QRadar's Reference data with the desired state.
+//
+// Unlike a purge followed by a bulk load, the Syncer only adds the missing
+// values and removes the stale ones, so rules never see an empty collection.
+package refsync
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	qradar "github.com/ilyaglow/go-qradar"
+)
+
+// State represents the desired contents of the Reference data collections by
+// their names. Collections are expected to exist.
+type State struct {
+	Sets       map[string]qradar.SetBulk
+	Maps       map[string]qradar.MapBulk
+	MapsOfSets map[string]qradar.MapOfSetsBulk
+	Tables     map[string]qradar.TableBulk
+}
+
+// Result represents the outcome of the collection reconciliation.
+type Result struct {
+	Kind      qradar.ReferenceKind
+	Name      string
+	Added     int
+	Removed   int
+	Unchanged int
+	Errors    []error
+}
+
+// Report represents the outcome of the reconciliation of all collections.
+type Report struct {
+	DryRun  bool
+	Results []Result
+}
+
+// Failed returns true if any of the collections had errors.
+func (r *Report) Failed() bool {
+	for i := range r.Results {
+		if len(r.Results[i].Errors) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// Syncer applies the desired state to QRadar's Reference data.
+type Syncer struct {
+	client *qradar.Client
+
+	// DryRun computes the difference without applying it.
+	DryRun bool
+	// Out receives the planned changes one per line if set.
+	Out io.Writer
+}
+
+// NewSyncer returns a new Syncer of the Reference data.
+func NewSyncer(client *qradar.Client) *Syncer {
+	return &Syncer{client: client}
+}
+
+// Sync reconciles every collection of the state. Errors of a single
+// collection don't stop the reconciliation of the others and are reported
+// in its Result; the returned error is only set if the context is done.
+func (s *Syncer) Sync(ctx context.Context, state *State) (*Report, error) {
+	r := &Report{DryRun: s.DryRun}
+
+	for _, name := range sortedKeys(state.Sets) {
+		r.Results = append(r.Results, s.syncSet(ctx, name, state.Sets[name]))
+		if ctx.Err() != nil {
+			return r, ctx.Err()
+		}
+	}
+	for _, name := range sortedKeys(state.Maps) {
+		r.Results = append(r.Results, s.syncMap(ctx, name, state.Maps[name]))
+		if ctx.Err() != nil {
+			return r, ctx.Err()
+		}
+	}
+	for _, name := range sortedKeys(state.MapsOfSets) {
+		r.Results = append(r.Results, s.syncMapOfSets(ctx, name, state.MapsOfSets[name]))
+		if ctx.Err() != nil {
+			return r, ctx.Err()
+		}
+	}
+	for _, name := range sortedKeys(state.Tables) {
+		r.Results = append(r.Results, s.syncTable(ctx, name, state.Tables[name]))
+		if ctx.Err() != nil {
+			return r, ctx.Err()
+		}
+	}
+
+	return r, nil
+}
+
+// WriteSummary writes a line per collection with its counts and errors.
+func (r *Report) WriteSummary(w io.Writer) error {
+	for _, res := range r.Results {
+		_, err := fmt.Fprintf(w, "%s %s: +%d -%d =%d errors=%d\n", res.Kind, res.Name, res.Added, res.Removed, res.Unchanged, len(res.Errors))
+		if err != nil {
+			return err
+		}
+		for _, e := range res.Errors {
+			_, err = fmt.Fprintf(w, "  %s\n", e)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (s *Syncer) plan(kind qradar.ReferenceKind, name, op string, parts ...string) {
+	if s.Out == nil {
+		return
+	}
+	fmt.Fprintf(s.Out, "%s %s %s %s\n", op, kind, name, strings.Join(parts, " "))
+}
+
+func (s *Syncer) syncSet(ctx context.Context, name string, desired qradar.SetBulk) Result {
+	res := Result{Kind: qradar.ReferenceKindSet, Name: name}
+
+	meta, err := s.client.ReferenceSet.GetWithData(ctx, "element_type", "", name, 0, 0)
+	if err != nil {
+		res.Errors = append(res.Errors, err)
+		return res
+	}
+	elementType := stringValue(meta.ElementType)
+
+	current := make(map[string]string)
+	sc := s.client.ReferenceSet.NewScroller(name)
+	for sc.Next(ctx) {
+		e := sc.Result()
+		current[qradar.NormalizeElement(elementType, e.Value)] = e.Value
+	}
+	if sc.Err() != nil {
+		res.Errors = append(res.Errors, sc.Err())
+		return res
+	}
+
+	want := make(map[string]struct{}, len(desired))
+	var adds qradar.SetBulk
+	for _, v := range desired {
+		k := qradar.NormalizeElement(elementType, v)
+		if _, ok := want[k]; ok {
+			continue
+		}
+		want[k] = struct{}{}
+		if _, ok := current[k]; ok {
+			res.Unchanged++
+			continue
+		}
+		adds = append(adds, v)
+		s.plan(qradar.ReferenceKindSet, name, "+", v)
+	}
+
+	var removes []string
+	for _, k := range sortedKeys(current) {
+		if _, ok := want[k]; !ok {
+			removes = append(removes, current[k])
+			s.plan(qradar.ReferenceKindSet, name, "-", current[k])
+		}
+	}
+
+	if s.DryRun {
+		res.Added, res.Removed = len(adds), len(removes)
+		return res
+	}
+
+	if len(adds) > 0 {
+		_, err = s.client.ReferenceSet.BulkLoadTyped(ctx, "name", name, adds)
+		if err != nil {
+			res.Errors = append(res.Errors, err)
+		} else {
+			res.Added = len(adds)
+		}
+	}
+
+	for _, v := range removes {
+		_, err = s.client.ReferenceSet.DeleteValue(ctx, "name", name, v)
+		if err != nil {
+			res.Errors = append(res.Errors, fmt.Errorf("delete %q: %w", v, err))
+			continue
+		}
+		res.Removed++
+	}
+
+	return res
+}
+
+func (s *Syncer) syncMap(ctx context.Context, name string, desired qradar.MapBulk) Result {
+	res := Result{Kind: qradar.ReferenceKindMap, Name: name}
+
+	current := make(map[string]string)
+	sc := s.client.ReferenceMap.NewScroller(name)
+	for sc.Next(ctx) {
+		e := sc.Result()
+		current[e.Key] = e.Value
+	}
+	if sc.Err() != nil {
+		res.Errors = append(res.Errors, sc.Err())
+		return res
+	}
+
+	adds := make(qradar.MapBulk)
+	for _, k := range sortedKeys(desired) {
+		if v, ok := current[k]; ok && v == desired[k] {
+			res.Unchanged++
+			continue
+		}
+		adds[k] = desired[k]
+		s.plan(qradar.ReferenceKindMap, name, "+", k, desired[k])
+	}
+
+	var removes []string
+	for _, k := range sortedKeys(current) {
+		if _, ok := desired[k]; !ok {
+			removes = append(removes, k)
+			s.plan(qradar.ReferenceKindMap, name, "-", k, current[k])
+		}
+	}
+
+	if s.DryRun {
+		res.Added, res.Removed = len(adds), len(removes)
+		return res
+	}
+
+	if len(adds) > 0 {
+		_, err := s.client.ReferenceMap.BulkLoadTyped(ctx, "name", name, adds)
+		if err != nil {
+			res.Errors = append(res.Errors, err)
+		} else {
+			res.Added = len(adds)
+		}
+	}
+
+	for _, k := range removes {
+		_, err := s.client.ReferenceMap.DeleteKey(ctx, "name", name, k, current[k])
+		if err != nil {
+			res.Errors = append(res.Errors, fmt.Errorf("delete %q: %w", k, err))
+			continue
+		}
+		res.Removed++
+	}
+
+	return res
+}
+
+func (s *Syncer) syncMapOfSets(ctx context.Context, name string, desired qradar.MapOfSetsBulk) Result {
+	res := Result{Kind: qradar.ReferenceKindMapOfSets, Name: name}
+
+	meta, err := s.client.ReferenceMapOfSets.GetWithData(ctx, "element_type", "", name, 0, 0)
+	if err != nil {
+		res.Errors = append(res.Errors, err)
+		return res
+	}
+	elementType := stringValue(meta.ElementType)
+
+	current := make(map[string]map[string]string)
+	sc := s.client.ReferenceMapOfSets.NewScroller(name)
+	for sc.Next(ctx) {
+		e := sc.Result()
+		if current[e.Key] == nil {
+			current[e.Key] = make(map[string]string)
+		}
+		current[e.Key][qradar.NormalizeElement(elementType, e.Value)] = e.Value
+	}
+	if sc.Err() != nil {
+		res.Errors = append(res.Errors, sc.Err())
+		return res
+	}
+
+	adds := make(qradar.MapOfSetsBulk)
+	want := make(map[string]map[string]struct{})
+	for _, k := range sortedKeys(desired) {
+		want[k] = make(map[string]struct{})
+		for _, v := range desired[k] {
+			n := qradar.NormalizeElement(elementType, v)
+			if _, ok := want[k][n]; ok {
+				continue
+			}
+			want[k][n] = struct{}{}
+			if _, ok := current[k][n]; ok {
+				res.Unchanged++
+				continue
+			}
+			adds[k] = append(adds[k], v)
+			s.plan(qradar.ReferenceKindMapOfSets, name, "+", k, v)
+		}
+	}
+
+	type removal struct{ key, value string }
+	var removes []removal
+	for _, k := range sortedKeys(current) {
+		for _, n := range sortedKeys(current[k]) {
+			if _, ok := want[k][n]; !ok {
+				removes = append(removes, removal{k, current[k][n]})
+				s.plan(qradar.ReferenceKindMapOfSets, name, "-", k, current[k][n])
+			}
+		}
+	}
+
+	added := 0
+	for _, vs := range adds {
+		added += len(vs)
+	}
+
+	if s.DryRun {
+		res.Added, res.Removed = added, len(removes)
+		return res
+	}
+
+	if added > 0 {
+		_, err = s.client.ReferenceMapOfSets.BulkLoadTyped(ctx, "name", name, adds)
+		if err != nil {
+			res.Errors = append(res.Errors, err)
+		} else {
+			res.Added = added
+		}
+	}
+
+	for _, r := range removes {
+		_, err = s.client.ReferenceMapOfSets.DeleteValue(ctx, "name", name, r.key, r.value)
+		if err != nil {
+			res.Errors = append(res.Errors, fmt.Errorf("delete %q/%q: %w", r.key, r.value, err))
+			continue
+		}
+		res.Removed++
+	}
+
+	return res
+}
+
+func (s *Syncer) syncTable(ctx context.Context, name string, desired qradar.TableBulk) Result {
+	res := Result{Kind: qradar.ReferenceKindTable, Name: name}
+
+	current := make(map[string]map[string]string)
+	sc := s.client.ReferenceTable.NewScroller(name)
+	for sc.Next(ctx) {
+		e := sc.Result()
+		if current[e.OuterKey] == nil {
+			current[e.OuterKey] = make(map[string]string)
+		}
+		current[e.OuterKey][e.Key] = e.Value
+	}
+	if sc.Err() != nil {
+		res.Errors = append(res.Errors, sc.Err())
+		return res
+	}
+
+	adds := make(qradar.TableBulk)
+	added := 0
+	for _, outer := range sortedKeys(desired) {
+		for _, ik := range sortedKeys(desired[outer]) {
+			v := desired[outer][ik]
+			if cv, found := current[outer][ik]; found && cv == v {
+				res.Unchanged++
+				continue
+			}
+			if adds[outer] == nil {
+				adds[outer] = make(map[string]string)
+			}
+			adds[outer][ik] = v
+			added++
+			s.plan(qradar.ReferenceKindTable, name, "+", outer, ik, v)
+		}
+	}
+
+	type removal struct{ outer, inner, value string }
+	var removes []removal
+	for _, outer := range sortedKeys(current) {
+		for _, ik := range sortedKeys(current[outer]) {
+			if _, found := desired[outer][ik]; !found {
+				removes = append(removes, removal{outer, ik, current[outer][ik]})
+				s.plan(qradar.ReferenceKindTable, name, "-", outer, ik, current[outer][ik])
+			}
+		}
+	}
+
+	if s.DryRun {
+		res.Added, res.Removed = added, len(removes)
+		return res
+	}
+
+	if added > 0 {
+		_, err := s.client.ReferenceTable.BulkLoadTyped(ctx, "name", name, adds)
+		if err != nil {
+			res.Errors = append(res.Errors, err)
+		} else {
+			res.Added = added
+		}
+	}
+
+	for _, r := range removes {
+		_, err := s.client.ReferenceTable.DeleteInnerKey(ctx, "name", name, r.outer, r.inner, r.value)
+		if err != nil {
+			res.Errors = append(res.Errors, fmt.Errorf("delete %q/%q: %w", r.outer, r.inner, err))
+			continue
+		}
+		res.Removed++
+	}
+
+	return res
+}
+
+func stringValue(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
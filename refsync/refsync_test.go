@@ -0,0 +1,148 @@
+package refsync
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	qradar "github.com/ilyaglow/go-qradar"
+)
+
+// fakeQRadar serves the Reference data responses by path and records the
+// changing requests.
+type fakeQRadar struct {
+	mu        sync.Mutex
+	responses map[string]string
+	changes   []string
+}
+
+func (f *fakeQRadar) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		body, _ := io.ReadAll(r.Body)
+		f.mu.Lock()
+		f.changes = append(f.changes, strings.TrimSpace(r.Method+" "+r.URL.Path+" "+r.URL.RawQuery+" "+string(body)))
+		f.mu.Unlock()
+		w.Write([]byte(`{}`))
+		return
+	}
+	resp, ok := f.responses[r.URL.Path]
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	w.Write([]byte(resp))
+}
+
+func newSyncer(t *testing.T, f *fakeQRadar) *Syncer {
+	t.Helper()
+	srv := httptest.NewServer(f)
+	t.Cleanup(srv.Close)
+	client, err := qradar.NewClient(srv.URL+"/", qradar.SetSECKey("test"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return NewSyncer(client)
+}
+
+func TestSync(t *testing.T) {
+	responses := map[string]string{
+		"/api/reference_data/sets/s": `{"element_type":"ALNIC","number_of_elements":2,"data":[{"value":"A"},{"value":"b"}]}`,
+		"/api/reference_data/maps/m": `{"element_type":"ALN","number_of_elements":2,"data":{"k1":{"value":"v1"},"k2":{"value":"old"}}}`,
+	}
+	state := &State{
+		Sets: map[string]qradar.SetBulk{"s": {"a", "C", "c"}},
+		Maps: map[string]qradar.MapBulk{"m": {"k1": "v1", "k2": "new", "k3": "v3"}},
+	}
+
+	tests := []struct {
+		name    string
+		dryRun  bool
+		plan    string
+		changes []string
+		results []Result
+	}{
+		{
+			name:   "dry run",
+			dryRun: true,
+			plan: "+ set s C\n- set s b\n" +
+				"+ map m k2 new\n+ map m k3 v3\n",
+			results: []Result{
+				{Kind: qradar.ReferenceKindSet, Name: "s", Added: 1, Removed: 1, Unchanged: 1},
+				{Kind: qradar.ReferenceKindMap, Name: "m", Added: 2, Unchanged: 1},
+			},
+		},
+		{
+			name: "apply",
+			changes: []string{
+				`DELETE /api/reference_data/sets/s/b fields=name`,
+				`POST /api/reference_data/maps/bulk_load/m fields=name {"k2":"new","k3":"v3"}`,
+				`POST /api/reference_data/sets/bulk_load/s fields=name ["C"]`,
+			},
+			results: []Result{
+				{Kind: qradar.ReferenceKindSet, Name: "s", Added: 1, Removed: 1, Unchanged: 1},
+				{Kind: qradar.ReferenceKindMap, Name: "m", Added: 2, Unchanged: 1},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := &fakeQRadar{responses: responses}
+			s := newSyncer(t, f)
+			var plan bytes.Buffer
+			s.DryRun = tt.dryRun
+			s.Out = &plan
+
+			r, err := s.Sync(context.Background(), state)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if r.Failed() {
+				t.Fatalf("Sync() failed: %+v", r.Results)
+			}
+			if tt.plan != "" && plan.String() != tt.plan {
+				t.Errorf("plan = %q, want %q", plan.String(), tt.plan)
+			}
+			sort.Strings(f.changes)
+			if strings.Join(f.changes, "\n") != strings.Join(tt.changes, "\n") {
+				t.Errorf("changes =\n%s\nwant\n%s", strings.Join(f.changes, "\n"), strings.Join(tt.changes, "\n"))
+			}
+			if len(r.Results) != len(tt.results) {
+				t.Fatalf("results = %+v, want %+v", r.Results, tt.results)
+			}
+			for i, want := range tt.results {
+				got := r.Results[i]
+				got.Errors = nil
+				if got.Kind != want.Kind || got.Name != want.Name || got.Added != want.Added ||
+					got.Removed != want.Removed || got.Unchanged != want.Unchanged {
+					t.Errorf("result %d = %+v, want %+v", i, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestSyncReportsMissingCollection(t *testing.T) {
+	s := newSyncer(t, &fakeQRadar{})
+	r, err := s.Sync(context.Background(), &State{Sets: map[string]qradar.SetBulk{"missing": {"a"}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !r.Failed() {
+		t.Error("Failed() = false for a missing collection")
+	}
+
+	var summary bytes.Buffer
+	if err := r.WriteSummary(&summary); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(summary.String(), "set missing: +0 -0 =0 errors=1\n") {
+		t.Errorf("WriteSummary() = %q", summary.String())
+	}
+}
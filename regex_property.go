@@ -114,7 +114,7 @@ func (c *RegexPropertyService) DeleteByID(ctx context.Context, fields string, id
 
 // GetByName returns Regex Property of the current QRadar installation by Name.
 func (c *RegexPropertyService) GetByName(ctx context.Context, fields string, name string) (*RegexProperty, error) {
-	req, err := c.client.requestHelp(http.MethodGet, regexPropertyAPIPrefix, fields, fmt.Sprintf("name=\"%s\"", name), 0, 0, nil, nil)
+	req, err := c.client.requestHelp(http.MethodGet, regexPropertyAPIPrefix, fields, "name="+filterString(name), 0, 0, nil, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -134,7 +134,7 @@ func (c *RegexPropertyService) GetByName(ctx context.Context, fields string, nam
 
 // GetByUUID returns Regex Property of the current QRadar installation by UUID.
 func (c *RegexPropertyService) GetByUUID(ctx context.Context, fields string, uuid string) (*RegexProperty, error) {
-	req, err := c.client.requestHelp(http.MethodGet, regexPropertyAPIPrefix, fields, fmt.Sprintf("identifier=\"%s\"", uuid), 0, 0, nil, nil)
+	req, err := c.client.requestHelp(http.MethodGet, regexPropertyAPIPrefix, fields, "identifier="+filterString(uuid), 0, 0, nil, nil)
 	if err != nil {
 		return nil, err
 	}
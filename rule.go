@@ -87,7 +87,7 @@ func (c *RuleService) DeleteByID(ctx context.Context, fields string, id int) (*D
 
 // GetByName returns Rule of the current QRadar installation by Name.
 func (c *RuleService) GetByName(ctx context.Context, fields string, name string) (*Rule, error) {
-	req, err := c.client.requestHelp(http.MethodGet, ruleAPIPrefix, fields, fmt.Sprintf("name=\"%s\"", name), 0, 0, nil, nil)
+	req, err := c.client.requestHelp(http.MethodGet, ruleAPIPrefix, fields, "name="+filterString(name), 0, 0, nil, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -107,7 +107,7 @@ func (c *RuleService) GetByName(ctx context.Context, fields string, name string)
 
 // GetByUUID returns Rule of the current QRadar installation by UUID.
 func (c *RuleService) GetByUUID(ctx context.Context, fields string, uuid string) (*Rule, error) {
-	req, err := c.client.requestHelp(http.MethodGet, ruleAPIPrefix, fields, fmt.Sprintf("identifier=\"%s\"", uuid), 0, 0, nil, nil)
+	req, err := c.client.requestHelp(http.MethodGet, ruleAPIPrefix, fields, "identifier="+filterString(uuid), 0, 0, nil, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -55,3 +55,46 @@ func (c *RuleGroupService) GetByID(ctx context.Context, fields string, id int) (
 	}
 	return &result, nil
 }
+
+// Create creates Rule Group in the current QRadar installation.
+func (c *RuleGroupService) Create(ctx context.Context, fields string, data interface{}) (*RuleGroup, error) {
+	req, err := c.client.requestHelp(http.MethodPost, ruleGroupAPIPrefix, fields, "", 0, 0, nil, data)
+	if err != nil {
+		return nil, err
+	}
+	var result RuleGroup
+	_, err = c.client.Do(ctx, req, &result)
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// UpdateByID updates Rule Group of the current QRadar installation by ID,
+// e.g. the child_items to change the rules of the group.
+func (c *RuleGroupService) UpdateByID(ctx context.Context, fields string, id int, data interface{}) (*RuleGroup, error) {
+	req, err := c.client.requestHelp(http.MethodPost, ruleGroupAPIPrefix, fields, "", 0, 0, &id, data)
+	if err != nil {
+		return nil, err
+	}
+	var result RuleGroup
+	_, err = c.client.Do(ctx, req, &result)
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// DeleteByID deletes Rule Group of the current QRadar installation by ID.
+func (c *RuleGroupService) DeleteByID(ctx context.Context, fields string, id int) (*DeleteTask, error) {
+	req, err := c.client.requestHelp(http.MethodDelete, ruleGroupAPIPrefix, fields, "", 0, 0, &id, nil)
+	if err != nil {
+		return nil, err
+	}
+	var result DeleteTask
+	_, err = c.client.Do(ctx, req, &result)
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
@@ -0,0 +1,140 @@
+package qradar
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+)
+
+// ruleGroupFilterChunk is a number of rule IDs per request of the rules of
+// the groups, so the filter stays within the URL length limits.
+const ruleGroupFilterChunk = 100
+
+// RuleGroupNode represents a Rule Group within the hierarchy.
+type RuleGroupNode = GroupNode[RuleGroup]
+
+// RuleGroupTree represents the hierarchy of the Rule Groups.
+type RuleGroupTree = GroupTree[RuleGroup]
+
+// NewRuleGroupTree builds the hierarchy of the groups from their parent_id
+// and child_groups. Groups with an unknown parent become roots. Children
+// are ordered by name.
+func NewRuleGroupTree(groups []RuleGroup) *RuleGroupTree {
+	return newGroupTree(groups)
+}
+
+// Tree returns the hierarchy of the Rule Groups.
+func (c *RuleGroupService) Tree(ctx context.Context) (*RuleGroupTree, error) {
+	groups, err := c.Get(ctx, "", "", 0, 0)
+	if err != nil {
+		return nil, err
+	}
+	return NewRuleGroupTree(groups), nil
+}
+
+// RuleIDs returns the sorted IDs of the rules of the group node and, if
+// recursive is true, of all its descendants.
+func RuleIDs(n *RuleGroupNode, recursive bool) []int {
+	seen := make(map[int]struct{})
+	var collect func(n *RuleGroupNode)
+	collect = func(n *RuleGroupNode) {
+		for _, item := range n.Group.ChildItems {
+			id, err := strconv.Atoi(item)
+			if err == nil {
+				seen[id] = struct{}{}
+			}
+		}
+		if recursive {
+			for _, child := range n.Children {
+				collect(child)
+			}
+		}
+	}
+	collect(n)
+
+	ids := make([]int, 0, len(seen))
+	for id := range seen {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	return ids
+}
+
+// Rules returns the Rules of the group by ID and all its descendant groups.
+func (c *RuleGroupService) Rules(ctx context.Context, fields string, id int) ([]Rule, error) {
+	t, err := c.Tree(ctx)
+	if err != nil {
+		return nil, err
+	}
+	n := t.Node(id)
+	if n == nil {
+		return nil, fmt.Errorf("rule group %d not found", id)
+	}
+
+	ids := RuleIDs(n, true)
+	var result []Rule
+	for start := 0; start < len(ids); start += ruleGroupFilterChunk {
+		end := start + ruleGroupFilterChunk
+		if end > len(ids) {
+			end = len(ids)
+		}
+		rules, err := c.client.Rule.Get(ctx, fields, idsFilter(ids[start:end]), 0, 0)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, rules...)
+	}
+	return result, nil
+}
+
+// MoveRules moves the rules by IDs from one group to another. Rules that are
+// not in the source group are added to the destination group as well.
+func (c *RuleGroupService) MoveRules(ctx context.Context, ruleIDs []int, fromID, toID int) error {
+	if fromID == toID {
+		return nil
+	}
+	from, err := c.GetByID(ctx, "id,child_items", fromID)
+	if err != nil {
+		return err
+	}
+	to, err := c.GetByID(ctx, "id,child_items", toID)
+	if err != nil {
+		return err
+	}
+
+	moved := make(map[string]bool, len(ruleIDs))
+	for _, id := range ruleIDs {
+		moved[strconv.Itoa(id)] = true
+	}
+
+	toItems := make([]string, 0, len(to.ChildItems)+len(ruleIDs))
+	present := make(map[string]bool)
+	for _, item := range to.ChildItems {
+		toItems = append(toItems, item)
+		present[item] = true
+	}
+	for _, id := range ruleIDs {
+		item := strconv.Itoa(id)
+		if !present[item] {
+			toItems = append(toItems, item)
+			present[item] = true
+		}
+	}
+	_, err = c.UpdateByID(ctx, "id", toID, map[string][]string{"child_items": toItems})
+	if err != nil {
+		return err
+	}
+
+	fromItems := make([]string, 0, len(from.ChildItems))
+	for _, item := range from.ChildItems {
+		if !moved[item] {
+			fromItems = append(fromItems, item)
+		}
+	}
+	if len(fromItems) == len(from.ChildItems) {
+		return nil
+	}
+	_, err = c.UpdateByID(ctx, "id", fromID, map[string][]string{"child_items": fromItems})
+	return err
+}
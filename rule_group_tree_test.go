@@ -0,0 +1,95 @@
+package qradar
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestNewRuleGroupTree(t *testing.T) {
+	groups := []RuleGroup{
+		{ID: intPtr(1), Name: strPtr("MITRE"), ChildGroups: []int{3, 2}},
+		{ID: intPtr(2), Name: strPtr("Initial Access"), ChildItems: []string{"10", "11"}},
+		{ID: intPtr(3), Name: strPtr("Discovery"), ParentID: intPtr(1), ChildItems: []string{"11", "12"}},
+		{ID: intPtr(4), Name: strPtr("Orphan"), ParentID: intPtr(99)},
+		// a cycle is broken at the group with the lowest ID
+		{ID: intPtr(5), Name: strPtr("Loop A"), ParentID: intPtr(6)},
+		{ID: intPtr(6), Name: strPtr("Loop B"), ParentID: intPtr(5)},
+		{Name: strPtr("no id")},
+	}
+	tree := NewRuleGroupTree(groups)
+
+	var walked []string
+	err := tree.Walk(func(n *RuleGroupNode, depth int) error {
+		walked = append(walked, strings.Repeat("  ", depth)+n.Path())
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"Loop A",
+		"  Loop A/Loop B",
+		"MITRE",
+		"  MITRE/Discovery",
+		"  MITRE/Initial Access",
+		"Orphan",
+	}
+	if !reflect.DeepEqual(walked, want) {
+		t.Errorf("Walk() = %q, want %q", walked, want)
+	}
+
+	tests := []struct {
+		id        int
+		recursive bool
+		want      []int
+	}{
+		{1, false, []int{}},
+		{1, true, []int{10, 11, 12}},
+		{3, true, []int{11, 12}},
+	}
+	for _, tt := range tests {
+		got := RuleIDs(tree.Node(tt.id), tt.recursive)
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("RuleIDs(%d, %v) = %v, want %v", tt.id, tt.recursive, got, tt.want)
+		}
+	}
+	if tree.Node(99) != nil {
+		t.Error("Node(99) of an unknown group is not nil")
+	}
+}
+
+func TestRuleGroupMoveRules(t *testing.T) {
+	updates := map[string]string{}
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			body, _ := io.ReadAll(r.Body)
+			updates[r.URL.Path] = strings.TrimSpace(string(body))
+			w.Write([]byte(`{}`))
+			return
+		}
+		switch r.URL.Path {
+		case "/api/analytics/rule_groups/1":
+			w.Write([]byte(`{"id":1,"child_items":["10","11","12"]}`))
+		case "/api/analytics/rule_groups/2":
+			w.Write([]byte(`{"id":2,"child_items":["11"]}`))
+		default:
+			http.NotFound(w, r)
+		}
+	})
+
+	err := c.RuleGroup.MoveRules(context.Background(), []int{10, 11, 13}, 1, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{
+		"/api/analytics/rule_groups/1": `{"child_items":["12"]}`,
+		"/api/analytics/rule_groups/2": `{"child_items":["11","10","13"]}`,
+	}
+	if !reflect.DeepEqual(updates, want) {
+		t.Errorf("updates %v, want %v", updates, want)
+	}
+}
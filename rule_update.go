@@ -0,0 +1,224 @@
+package qradar
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+)
+
+// DefaultBatchConcurrency is a number of rules updated concurrently by the
+// batch methods if the concurrency is not set.
+const DefaultBatchConcurrency = 4
+
+// Enable enables the rule by ID.
+func (c *RuleService) Enable(ctx context.Context, fields string, id int) (*Rule, error) {
+	enabled := true
+	return c.UpdateByID(ctx, fields, id, &Rule{Enabled: &enabled})
+}
+
+// Disable disables the rule by ID.
+func (c *RuleService) Disable(ctx context.Context, fields string, id int) (*Rule, error) {
+	enabled := false
+	return c.UpdateByID(ctx, fields, id, &Rule{Enabled: &enabled})
+}
+
+// SetOwner changes the owner of the rule by ID.
+func (c *RuleService) SetOwner(ctx context.Context, fields string, id int, owner string) (*Rule, error) {
+	return c.UpdateByID(ctx, fields, id, &Rule{Owner: &owner})
+}
+
+// Rename changes the name of the rule by ID. The rules API doesn't allow to
+// change the name, so the rule XML is updated through the RuleWithData API.
+func (c *RuleService) Rename(ctx context.Context, fields string, id int, name string) (*Rule, error) {
+	r, err := c.client.RuleWithData.GetByID(ctx, "id,rule_xml", id)
+	if err != nil {
+		return nil, err
+	}
+	def, err := r.Definition()
+	if err != nil {
+		return nil, err
+	}
+	def.Name = name
+	err = r.SetDefinition(def)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = c.client.RuleWithData.UpdateByID(ctx, "id", id, &RuleWithData{
+		Rule:    Rule{Name: &name},
+		RuleXML: r.RuleXML,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return c.GetByID(ctx, fields, id)
+}
+
+// AssignToGroups makes the rule by ID a member of exactly the groups by IDs:
+// the rule is added to the missing groups and removed from the others.
+func (c *RuleService) AssignToGroups(ctx context.Context, id int, groupIDs []int) error {
+	groups, err := c.client.RuleGroup.Get(ctx, "id,child_items", "", 0, 0)
+	if err != nil {
+		return err
+	}
+
+	found := make(map[int]bool, len(groups))
+	for _, g := range groups {
+		if g.ID != nil {
+			found[*g.ID] = true
+		}
+	}
+	for _, gid := range groupIDs {
+		if !found[gid] {
+			return fmt.Errorf("rule group %d not found", gid)
+		}
+	}
+
+	item := strconv.Itoa(id)
+	for _, g := range groups {
+		if g.ID == nil {
+			continue
+		}
+		items := make([]string, 0, len(g.ChildItems))
+		has := false
+		for _, i := range g.ChildItems {
+			if i == item {
+				has = true
+				continue
+			}
+			items = append(items, i)
+		}
+		want := containsInt(groupIDs, *g.ID)
+		if want == has {
+			continue
+		}
+		if want {
+			items = append(items, item)
+		}
+
+		_, err = c.client.RuleGroup.UpdateByID(ctx, "id", *g.ID, map[string][]string{"child_items": items})
+		if err != nil {
+			return fmt.Errorf("rule group %d: %w", *g.ID, err)
+		}
+	}
+
+	return nil
+}
+
+func containsInt(s []int, v int) bool {
+	for _, i := range s {
+		if i == v {
+			return true
+		}
+	}
+	return false
+}
+
+// RuleBatchResult represents the outcome of the batch change of the rule.
+type RuleBatchResult struct {
+	ID   int
+	Name string
+	Err  error
+}
+
+// RuleBatchReport represents the outcome of the batch change of the rules in
+// the order they were returned by the filter.
+type RuleBatchReport struct {
+	Results []RuleBatchResult
+}
+
+// Failed returns the results of the rules that failed to change.
+func (r *RuleBatchReport) Failed() []RuleBatchResult {
+	var failed []RuleBatchResult
+	for _, res := range r.Results {
+		if res.Err != nil {
+			failed = append(failed, res)
+		}
+	}
+	return failed
+}
+
+// Batch applies the change to every rule matching the filter with at most
+// concurrency changes in flight. Errors of a single rule don't stop the others
+// and are reported in its result; the returned error is only set if the rules
+// can't be listed or the context is done.
+func (c *RuleService) Batch(ctx context.Context, filter string, concurrency int, change func(ctx context.Context, r Rule) error) (*RuleBatchReport, error) {
+	rules, err := c.Get(ctx, "id,name", filter, 0, 0)
+	if err != nil {
+		return nil, err
+	}
+	if concurrency <= 0 {
+		concurrency = DefaultBatchConcurrency
+	}
+
+	report := &RuleBatchReport{Results: make([]RuleBatchResult, len(rules))}
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+
+	for i, r := range rules {
+		res := &report.Results[i]
+		if r.ID != nil {
+			res.ID = *r.ID
+		}
+		if r.Name != nil {
+			res.Name = *r.Name
+		}
+
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			res.Err = ctx.Err()
+			continue
+		}
+
+		wg.Add(1)
+		go func(r Rule) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			if r.ID == nil {
+				res.Err = fmt.Errorf("rule without id")
+				return
+			}
+			res.Err = change(ctx, r)
+		}(r)
+	}
+	wg.Wait()
+
+	return report, ctx.Err()
+}
+
+// BatchEnable enables the rules matching the filter.
+func (c *RuleService) BatchEnable(ctx context.Context, filter string, concurrency int) (*RuleBatchReport, error) {
+	return c.Batch(ctx, filter, concurrency, func(ctx context.Context, r Rule) error {
+		_, err := c.Enable(ctx, "id", *r.ID)
+		return err
+	})
+}
+
+// BatchDisable disables the rules matching the filter.
+func (c *RuleService) BatchDisable(ctx context.Context, filter string, concurrency int) (*RuleBatchReport, error) {
+	return c.Batch(ctx, filter, concurrency, func(ctx context.Context, r Rule) error {
+		_, err := c.Disable(ctx, "id", *r.ID)
+		return err
+	})
+}
+
+// BatchSetOwner changes the owner of the rules matching the filter.
+func (c *RuleService) BatchSetOwner(ctx context.Context, filter, owner string, concurrency int) (*RuleBatchReport, error) {
+	return c.Batch(ctx, filter, concurrency, func(ctx context.Context, r Rule) error {
+		_, err := c.SetOwner(ctx, "id", *r.ID, owner)
+		return err
+	})
+}
+
+// BatchAssignToGroups assigns the rules matching the filter to the groups.
+// Group membership is updated one rule at a time, since the rules of a group
+// are changed as a whole.
+func (c *RuleService) BatchAssignToGroups(ctx context.Context, filter string, groupIDs []int) (*RuleBatchReport, error) {
+	return c.Batch(ctx, filter, 1, func(ctx context.Context, r Rule) error {
+		return c.AssignToGroups(ctx, *r.ID, groupIDs)
+	})
+}
@@ -0,0 +1,120 @@
+package qradar
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestRuleBatch(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("filter") != "enabled=false" {
+			t.Errorf("unexpected filter %q", r.URL.Query().Get("filter"))
+		}
+		w.Write([]byte(`[{"id":1,"name":"a"},{"id":2,"name":"b"},{"name":"c"}]`))
+	})
+
+	var mu sync.Mutex
+	var changed []int
+	report, err := c.Rule.Batch(context.Background(), "enabled=false", 2, func(ctx context.Context, r Rule) error {
+		mu.Lock()
+		changed = append(changed, *r.ID)
+		mu.Unlock()
+		if *r.ID == 2 {
+			return errors.New("locked")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sort.Ints(changed)
+	if !reflect.DeepEqual(changed, []int{1, 2}) {
+		t.Errorf("changed rules %v, want [1 2]", changed)
+	}
+
+	tests := []struct {
+		id      int
+		name    string
+		wantErr string
+	}{
+		{1, "a", ""},
+		{2, "b", "locked"},
+		{0, "c", "rule without id"},
+	}
+	if len(report.Results) != len(tests) {
+		t.Fatalf("got %d results, want %d", len(report.Results), len(tests))
+	}
+	for i, tt := range tests {
+		res := report.Results[i]
+		errText := ""
+		if res.Err != nil {
+			errText = res.Err.Error()
+		}
+		if res.ID != tt.id || res.Name != tt.name || errText != tt.wantErr {
+			t.Errorf("result %d = %+v, want %d %q %q", i, res, tt.id, tt.name, tt.wantErr)
+		}
+	}
+	if len(report.Failed()) != 2 {
+		t.Errorf("got %d failed, want 2", len(report.Failed()))
+	}
+}
+
+func TestRuleAssignToGroups(t *testing.T) {
+	tests := []struct {
+		name     string
+		groupIDs []int
+		updates  map[string]string
+		wantErr  bool
+	}{
+		{
+			name:     "move",
+			groupIDs: []int{11, 12},
+			updates: map[string]string{
+				"/api/analytics/rule_groups/10": `{"child_items":["6"]}`,
+				"/api/analytics/rule_groups/11": `{"child_items":["6","5"]}`,
+				"/api/analytics/rule_groups/12": `{"child_items":["5"]}`,
+			},
+		},
+		{
+			name:     "unchanged",
+			groupIDs: []int{10},
+			updates:  map[string]string{},
+		},
+		{
+			name:     "unknown group",
+			groupIDs: []int{13},
+			updates:  map[string]string{},
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			updates := map[string]string{}
+			c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+				if r.Method == http.MethodGet {
+					w.Write([]byte(`[{"id":10,"child_items":["5","6"]},{"id":11,"child_items":["6"]},{"id":12}]`))
+					return
+				}
+				body, _ := io.ReadAll(r.Body)
+				updates[r.URL.Path] = strings.TrimSpace(string(body))
+				w.Write([]byte(`{}`))
+			})
+
+			err := c.Rule.AssignToGroups(context.Background(), 5, tt.groupIDs)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("AssignToGroups() = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(updates, tt.updates) {
+				t.Errorf("updates %v, want %v", updates, tt.updates)
+			}
+		})
+	}
+}
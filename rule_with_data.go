@@ -90,7 +90,7 @@ func (c *RuleWithDataService) UpdateByID(ctx context.Context, fields string, id
 
 // GetByName returns RuleWithData of the current QRadar installation by Name. Undocumented API.
 func (c *RuleWithDataService) GetByName(ctx context.Context, fields string, name string) (*RuleWithData, error) {
-	req, err := c.client.requestHelp(http.MethodGet, ruleWithDataAPIPrefix, fields, fmt.Sprintf("name=\"%s\"", name), 0, 0, nil, nil)
+	req, err := c.client.requestHelp(http.MethodGet, ruleWithDataAPIPrefix, fields, "name="+filterString(name), 0, 0, nil, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -113,7 +113,7 @@ func (c *RuleWithDataService) GetByName(ctx context.Context, fields string, name
 
 // GetByUUID returns RuleWithData of the current QRadar installation by UUID. Undocumented API.
 func (c *RuleWithDataService) GetByUUID(ctx context.Context, fields string, uuid string) (*RuleWithData, error) {
-	req, err := c.client.requestHelp(http.MethodGet, ruleWithDataAPIPrefix, fields, fmt.Sprintf("identifier=\"%s\"", uuid), 0, 0, nil, nil)
+	req, err := c.client.requestHelp(http.MethodGet, ruleWithDataAPIPrefix, fields, "identifier="+filterString(uuid), 0, 0, nil, nil)
 	if err != nil {
 		return nil, err
 	}
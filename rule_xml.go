@@ -0,0 +1,480 @@
+package qradar
+
+import (
+	"encoding/xml"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// RuleDefinition represents the CRE rule definition carried by the RuleXML
+// of RuleWithData and BuildingBlockWithData.
+//
+// Attributes and elements without a dedicated field are kept in Attrs and
+// Extra, so a parsed definition is marshaled back without losing data. The
+// attributes and the elements of the parsed definition, its tests and their
+// parameters are marshaled back in the document order.
+type RuleDefinition struct {
+	XMLName        xml.Name   `xml:"rule"`
+	ID             string     `xml:"id,attr,omitempty"`
+	Owner          string     `xml:"owner,attr,omitempty"`
+	Scope          string     `xml:"scope,attr,omitempty"`
+	Type           string     `xml:"type,attr,omitempty"`
+	Enabled        string     `xml:"enabled,attr,omitempty"`
+	BuildingBlock  string     `xml:"buildingBlock,attr,omitempty"`
+	RoleDefinition string     `xml:"roleDefinition,attr,omitempty"`
+	Attrs          []xml.Attr `xml:",any,attr"`
+
+	Name      string         `xml:"name"`
+	Notes     string         `xml:"notes,omitempty"`
+	Tests     []RuleTest     `xml:"testDefinitions>test"`
+	Actions   *RuleActions   `xml:"actions,omitempty"`
+	Responses *RuleResponses `xml:"responses,omitempty"`
+	Groups    *RuleGroups    `xml:"groups,omitempty"`
+	Extra     []XMLElement   `xml:",any"`
+
+	order xmlOrder
+}
+
+// RuleGroups represents the names of the rule groups the rule belongs to.
+type RuleGroups struct {
+	Names []string `xml:"group"`
+}
+
+// RuleTest represents a test of the rule definition.
+type RuleTest struct {
+	ID                   string     `xml:"id,attr,omitempty"`
+	UID                  string     `xml:"uid,attr,omitempty"`
+	Name                 string     `xml:"name,attr,omitempty"`
+	Group                string     `xml:"group,attr,omitempty"`
+	RequiredCapabilities string     `xml:"requiredCapabilities,attr,omitempty"`
+	Negate               string     `xml:"negate,attr,omitempty"`
+	Attrs                []xml.Attr `xml:",any,attr"`
+
+	Text       string              `xml:"text"`
+	Parameters []RuleTestParameter `xml:"parameter"`
+	Extra      []XMLElement        `xml:",any"`
+
+	order xmlOrder
+}
+
+// RuleTestParameter represents a parameter of the rule test.
+type RuleTestParameter struct {
+	ID    string     `xml:"id,attr,omitempty"`
+	Attrs []xml.Attr `xml:",any,attr"`
+
+	InitialText        string       `xml:"initialText,omitempty"`
+	SelectionLabel     string       `xml:"selectionLabel,omitempty"`
+	UserSelection      string       `xml:"userSelection,omitempty"`
+	UserSelectionTypes *XMLElement  `xml:"userSelectionTypes,omitempty"`
+	UserOptions        *XMLElement  `xml:"userOptions,omitempty"`
+	Extra              []XMLElement `xml:",any"`
+
+	order xmlOrder
+}
+
+// RuleActions represents the actions of the rule definition, e.g. the offense
+// creation and the event annotation, which are expressed as attributes.
+type RuleActions struct {
+	Attrs []xml.Attr   `xml:",any,attr"`
+	Extra []XMLElement `xml:",any"`
+}
+
+// RuleResponses represents the responses of the rule definition.
+type RuleResponses struct {
+	Attrs []xml.Attr `xml:",any,attr"`
+	// Items are the responses like newevent, email, syslog or
+	// referenceSet by the element name.
+	Items []XMLElement `xml:",any"`
+}
+
+// XMLElement represents an arbitrary XML element kept verbatim.
+type XMLElement struct {
+	XMLName xml.Name
+	Attrs   []xml.Attr `xml:",any,attr"`
+	Content string     `xml:",innerxml"`
+}
+
+// xmlOrder represents the order of the attributes and the child elements of
+// the parsed element by their local names.
+type xmlOrder struct {
+	attrs    []string
+	children []string
+}
+
+// newXMLOrder records the order of the attributes of the start element and
+// of the elements of its inner XML.
+func newXMLOrder(start xml.StartElement, inner string) xmlOrder {
+	var o xmlOrder
+	for _, a := range start.Attr {
+		o.attrs = append(o.attrs, a.Name.Local)
+	}
+	d := xml.NewDecoder(strings.NewReader(inner))
+	depth := 0
+	for {
+		tok, err := d.Token()
+		if err != nil {
+			break
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			if depth == 0 {
+				o.children = append(o.children, t.Name.Local)
+			}
+			depth++
+		case xml.EndElement:
+			depth--
+		}
+	}
+	return o
+}
+
+// sortAttrs orders the attributes as recorded, the other ones follow in the
+// given order.
+func (o xmlOrder) sortAttrs(attrs []xml.Attr) []xml.Attr {
+	pos := func(a xml.Attr) int {
+		for i, name := range o.attrs {
+			if name == a.Name.Local {
+				return i
+			}
+		}
+		return len(o.attrs)
+	}
+	sort.SliceStable(attrs, func(i, j int) bool { return pos(attrs[i]) < pos(attrs[j]) })
+	return attrs
+}
+
+// xmlChild represents a child element to marshal. Empty children are only
+// marshaled if the parsed element had them.
+type xmlChild struct {
+	name  xml.Name
+	value interface{}
+	empty bool
+}
+
+func newXMLChild(name string, value interface{}, empty bool) xmlChild {
+	return xmlChild{xml.Name{Local: name}, value, empty}
+}
+
+// encode marshals the element with the children in the recorded order, the
+// other non-empty children follow in the given order.
+func (o xmlOrder) encode(e *xml.Encoder, start xml.StartElement, children []xmlChild) error {
+	err := e.EncodeToken(start)
+	if err != nil {
+		return err
+	}
+	used := make([]bool, len(children))
+	emit := func(i int) error {
+		used[i] = true
+		return e.EncodeElement(children[i].value, xml.StartElement{Name: children[i].name})
+	}
+	for _, name := range o.children {
+		for i := range children {
+			if used[i] || children[i].name.Local != name {
+				continue
+			}
+			err = emit(i)
+			if err != nil {
+				return err
+			}
+			break
+		}
+	}
+	for i := range children {
+		if used[i] || children[i].empty {
+			continue
+		}
+		err = emit(i)
+		if err != nil {
+			return err
+		}
+	}
+	return e.EncodeToken(start.End())
+}
+
+// stringAttrs returns the attributes of the name and value pairs with
+// non-empty values.
+func stringAttrs(pairs ...string) []xml.Attr {
+	var attrs []xml.Attr
+	for i := 0; i+1 < len(pairs); i += 2 {
+		if pairs[i+1] != "" {
+			attrs = append(attrs, xml.Attr{Name: xml.Name{Local: pairs[i]}, Value: pairs[i+1]})
+		}
+	}
+	return attrs
+}
+
+func extraChildren(children []xmlChild, extra []XMLElement) []xmlChild {
+	for _, x := range extra {
+		children = append(children, xmlChild{x.XMLName, x, false})
+	}
+	return children
+}
+
+// UnmarshalXML satisfies the xml.Unmarshaler interface.
+func (d *RuleDefinition) UnmarshalXML(dec *xml.Decoder, start xml.StartElement) error {
+	// the embedded type must be exported to be decoded
+	type Definition RuleDefinition
+	var v struct {
+		Definition
+		Inner string `xml:",innerxml"`
+	}
+	err := dec.DecodeElement(&v, &start)
+	if err != nil {
+		return err
+	}
+	*d = RuleDefinition(v.Definition)
+	d.order = newXMLOrder(start, v.Inner)
+	return nil
+}
+
+// MarshalXML satisfies the xml.Marshaler interface.
+func (d RuleDefinition) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	start.Name = xml.Name{Local: "rule"}
+	start.Attr = d.order.sortAttrs(append(stringAttrs(
+		"id", d.ID,
+		"owner", d.Owner,
+		"scope", d.Scope,
+		"type", d.Type,
+		"enabled", d.Enabled,
+		"buildingBlock", d.BuildingBlock,
+		"roleDefinition", d.RoleDefinition,
+	), d.Attrs...))
+
+	type testDefinitions struct {
+		Tests []RuleTest `xml:"test"`
+	}
+	return d.order.encode(e, start, extraChildren([]xmlChild{
+		newXMLChild("name", d.Name, false),
+		newXMLChild("notes", d.Notes, d.Notes == ""),
+		newXMLChild("testDefinitions", testDefinitions{d.Tests}, len(d.Tests) == 0),
+		newXMLChild("actions", d.Actions, d.Actions == nil),
+		newXMLChild("responses", d.Responses, d.Responses == nil),
+		newXMLChild("groups", d.Groups, d.Groups == nil),
+	}, d.Extra))
+}
+
+// UnmarshalXML satisfies the xml.Unmarshaler interface.
+func (t *RuleTest) UnmarshalXML(dec *xml.Decoder, start xml.StartElement) error {
+	// the embedded type must be exported to be decoded
+	type Test RuleTest
+	var v struct {
+		Test
+		Inner string `xml:",innerxml"`
+	}
+	err := dec.DecodeElement(&v, &start)
+	if err != nil {
+		return err
+	}
+	*t = RuleTest(v.Test)
+	t.order = newXMLOrder(start, v.Inner)
+	return nil
+}
+
+// MarshalXML satisfies the xml.Marshaler interface.
+func (t RuleTest) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	start.Attr = t.order.sortAttrs(append(stringAttrs(
+		"id", t.ID,
+		"uid", t.UID,
+		"name", t.Name,
+		"group", t.Group,
+		"requiredCapabilities", t.RequiredCapabilities,
+		"negate", t.Negate,
+	), t.Attrs...))
+
+	children := []xmlChild{newXMLChild("text", t.Text, false)}
+	for _, p := range t.Parameters {
+		children = append(children, newXMLChild("parameter", p, false))
+	}
+	return t.order.encode(e, start, extraChildren(children, t.Extra))
+}
+
+// UnmarshalXML satisfies the xml.Unmarshaler interface.
+func (p *RuleTestParameter) UnmarshalXML(dec *xml.Decoder, start xml.StartElement) error {
+	// the embedded type must be exported to be decoded
+	type Parameter RuleTestParameter
+	var v struct {
+		Parameter
+		Inner string `xml:",innerxml"`
+	}
+	err := dec.DecodeElement(&v, &start)
+	if err != nil {
+		return err
+	}
+	*p = RuleTestParameter(v.Parameter)
+	p.order = newXMLOrder(start, v.Inner)
+	return nil
+}
+
+// MarshalXML satisfies the xml.Marshaler interface.
+func (p RuleTestParameter) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	start.Attr = p.order.sortAttrs(append(stringAttrs("id", p.ID), p.Attrs...))
+	return p.order.encode(e, start, extraChildren([]xmlChild{
+		newXMLChild("initialText", p.InitialText, p.InitialText == ""),
+		newXMLChild("selectionLabel", p.SelectionLabel, p.SelectionLabel == ""),
+		newXMLChild("userSelection", p.UserSelection, p.UserSelection == ""),
+		newXMLChild("userSelectionTypes", p.UserSelectionTypes, p.UserSelectionTypes == nil),
+		newXMLChild("userOptions", p.UserOptions, p.UserOptions == nil),
+	}, p.Extra))
+}
+
+// ParseRuleXML parses the rule definition.
+func ParseRuleXML(s string) (*RuleDefinition, error) {
+	var d RuleDefinition
+	err := xml.Unmarshal([]byte(s), &d)
+	if err != nil {
+		return nil, err
+	}
+	return &d, nil
+}
+
+// MarshalRuleXML returns the rule definition as XML.
+func (d *RuleDefinition) MarshalRuleXML() (string, error) {
+	bs, err := xml.Marshal(d)
+	if err != nil {
+		return "", err
+	}
+	return string(bs), nil
+}
+
+// IsEnabled returns true if the rule definition is enabled.
+func (d *RuleDefinition) IsEnabled() bool {
+	b, _ := strconv.ParseBool(d.Enabled)
+	return b
+}
+
+// SetEnabled sets the enabled attribute of the rule definition.
+func (d *RuleDefinition) SetEnabled(enabled bool) {
+	d.Enabled = strconv.FormatBool(enabled)
+}
+
+// RuleReferenceParameters are the IDs of the parameters that refer to the
+// rules or building blocks by the class name of the CRE test.
+var RuleReferenceParameters = map[string]string{
+	"com.q1labs.semsources.cre.tests.RuleMatch_Test": "2",
+}
+
+// RuleReferences returns the parameters of the test that refer to the rules
+// or building blocks: the parameter of RuleReferenceParameters for the known
+// test classes, otherwise the parameters with the user options of the rules,
+// e.g. of the getRulesTreeMenu method. Other parameters, like the counts and
+// the time windows of the function tests, are not references even if their
+// values match the IDs of the rules.
+func (t *RuleTest) RuleReferences() []*RuleTestParameter {
+	var result []*RuleTestParameter
+	id, known := RuleReferenceParameters[t.Name]
+	for i := range t.Parameters {
+		p := &t.Parameters[i]
+		switch {
+		case known && p.ID == id:
+		case !known && p.UserOptions != nil && strings.Contains(strings.ToLower(p.UserOptions.Attr("method")), "rule"):
+		default:
+			continue
+		}
+		result = append(result, p)
+	}
+	return result
+}
+
+// IsNegated returns true if the test is negated, e.g. "and NOT when".
+func (t *RuleTest) IsNegated() bool {
+	b, _ := strconv.ParseBool(t.Negate)
+	return b
+}
+
+// Attr returns the value of the attribute by name.
+func (e *XMLElement) Attr(name string) string {
+	return attr(e.Attrs, name)
+}
+
+// SetAttr sets the value of the attribute by name.
+func (e *XMLElement) SetAttr(name, value string) {
+	e.Attrs = setAttr(e.Attrs, name, value)
+}
+
+// Attr returns the value of the attribute by name.
+func (a *RuleActions) Attr(name string) string {
+	return attr(a.Attrs, name)
+}
+
+// SetAttr sets the value of the attribute by name.
+func (a *RuleActions) SetAttr(name, value string) {
+	a.Attrs = setAttr(a.Attrs, name, value)
+}
+
+// Attr returns the value of the attribute by name.
+func (r *RuleResponses) Attr(name string) string {
+	return attr(r.Attrs, name)
+}
+
+// SetAttr sets the value of the attribute by name.
+func (r *RuleResponses) SetAttr(name, value string) {
+	r.Attrs = setAttr(r.Attrs, name, value)
+}
+
+// Find returns the responses by the element name.
+func (r *RuleResponses) Find(name string) []*XMLElement {
+	var result []*XMLElement
+	for i := range r.Items {
+		if r.Items[i].XMLName.Local == name {
+			result = append(result, &r.Items[i])
+		}
+	}
+	return result
+}
+
+func attr(attrs []xml.Attr, name string) string {
+	for _, a := range attrs {
+		if a.Name.Local == name {
+			return a.Value
+		}
+	}
+	return ""
+}
+
+func setAttr(attrs []xml.Attr, name, value string) []xml.Attr {
+	for i := range attrs {
+		if attrs[i].Name.Local == name {
+			attrs[i].Value = value
+			return attrs
+		}
+	}
+	return append(attrs, xml.Attr{Name: xml.Name{Local: name}, Value: value})
+}
+
+// Definition parses the RuleXML of the rule.
+func (r *RuleWithData) Definition() (*RuleDefinition, error) {
+	if r.RuleXML == nil {
+		return nil, fmt.Errorf("rule has no rule_xml")
+	}
+	return ParseRuleXML(*r.RuleXML)
+}
+
+// SetDefinition replaces the RuleXML of the rule with the definition.
+func (r *RuleWithData) SetDefinition(d *RuleDefinition) error {
+	s, err := d.MarshalRuleXML()
+	if err != nil {
+		return err
+	}
+	r.RuleXML = &s
+	return nil
+}
+
+// Definition parses the RuleXML of the building block.
+func (b *BuildingBlockWithData) Definition() (*RuleDefinition, error) {
+	if b.RuleXML == nil {
+		return nil, fmt.Errorf("building block has no rule_xml")
+	}
+	return ParseRuleXML(*b.RuleXML)
+}
+
+// SetDefinition replaces the RuleXML of the building block with the definition.
+func (b *BuildingBlockWithData) SetDefinition(d *RuleDefinition) error {
+	s, err := d.MarshalRuleXML()
+	if err != nil {
+		return err
+	}
+	b.RuleXML = &s
+	return nil
+}
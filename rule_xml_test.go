@@ -0,0 +1,197 @@
+package qradar
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// creRuleXML is a rule definition as exported from a QRadar console.
+const creRuleXML = `<rule buildingBlock="false" enabled="true" id="100045" name="Excessive Firewall Denies Between Hosts" origin="SYSTEM" owner="admin" roleDefinition="false" scope="LOCAL" type="EVENT">` +
+	`<name>Excessive Firewall Denies Between Hosts</name>` +
+	`<notes>Reports excessive firewall denies between a single source and destination.</notes>` +
+	`<origin source="content-pack" version="7.5"/>` +
+	`<testDefinitions>` +
+	`<test group="Log Source Tests" id="39" name="com.q1labs.semsources.cre.tests.DeviceTypeID_Test" uid="0">` +
+	`<text>when the event(s) were detected by one or more of &lt;a href='javascript:editParameter("0", "1")' class='dynamic'&gt;Cisco PIX&lt;/a&gt;</text>` +
+	`<parameter id="1">` +
+	`<initialText>these log source types</initialText>` +
+	`<selectionLabel>Log Source Types</selectionLabel>` +
+	`<userOptions format="list" method="com.q1labs.sem.ui.semservices.UISemServices.getDeviceTypes" multiselect="true" source="class"/>` +
+	`<userSelection>6</userSelection>` +
+	`<userSelectionTypes/>` +
+	`</parameter>` +
+	`</test>` +
+	`<test group="Functions - Sequence" id="93" name="com.q1labs.semsources.cre.tests.MultiRuleCountMatchTest" negate="false" uid="1">` +
+	`<text>when at least &lt;a href='javascript:editParameter("1", "1")' class='dynamic'&gt;40&lt;/a&gt; events are seen</text>` +
+	`<parameter id="1"><initialText>this many</initialText><userSelection>40</userSelection></parameter>` +
+	`</test>` +
+	`</testDefinitions>` +
+	`<actions flowAnalysisInterval="0" forceOffenseCreation="true" includeAttackerEventsInterval="0" offenseMapping="0"/>` +
+	`<responses referenceMap="false" referenceTable="false">` +
+	`<newevent contributeOffenseName="true" lowLevelCategory="3039" name="Excessive Firewall Denies Between Hosts" qid="70750083"/>` +
+	`</responses>` +
+	`</rule>`
+
+func TestRuleXMLRoundTrip(t *testing.T) {
+	d, err := ParseRuleXML(creRuleXML)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if d.ID != "100045" || !d.IsEnabled() || d.Name != "Excessive Firewall Denies Between Hosts" {
+		t.Errorf("unexpected rule %q %q enabled %v", d.ID, d.Name, d.IsEnabled())
+	}
+	if len(d.Tests) != 2 {
+		t.Fatalf("got %d tests, want 2", len(d.Tests))
+	}
+	p := d.Tests[0].Parameters[0]
+	if p.UserSelection != "6" || p.UserOptions == nil || p.UserOptions.Attr("method") != "com.q1labs.sem.ui.semservices.UISemServices.getDeviceTypes" {
+		t.Errorf("unexpected parameter %+v", p)
+	}
+	if d.Actions.Attr("forceOffenseCreation") != "true" {
+		t.Errorf("actions lost forceOffenseCreation: %+v", d.Actions)
+	}
+	if events := d.Responses.Find("newevent"); len(events) != 1 || events[0].Attr("qid") != "70750083" {
+		t.Errorf("unexpected newevent responses %+v", events)
+	}
+
+	s, err := d.MarshalRuleXML()
+	if err != nil {
+		t.Fatal(err)
+	}
+	again, err := ParseRuleXML(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(d, again) {
+		t.Errorf("round trip changed the definition:\n%s", s)
+	}
+	if got, want := xmlTokens(t, s), xmlTokens(t, creRuleXML); !reflect.DeepEqual(got, want) {
+		t.Errorf("round trip changed the document:\n got %q\nwant %q", got, want)
+	}
+
+	// the parameter without the user options must not gain empty elements
+	for _, empty := range []string{"<selectionLabel></selectionLabel>", "<userOptions></userOptions>"} {
+		if strings.Contains(s, `<parameter id="1"><initialText>this many</initialText>`+empty) {
+			t.Errorf("marshaled rule contains %s:\n%s", empty, s)
+		}
+	}
+	if strings.Count(s, "<userSelectionTypes>") != 1 {
+		t.Errorf("want a single userSelectionTypes element:\n%s", s)
+	}
+}
+
+// xmlTokens returns the tokens of the document, so the documents that differ
+// only in the self-closing tags are equal.
+func xmlTokens(t *testing.T, s string) []string {
+	t.Helper()
+	var tokens []string
+	d := xml.NewDecoder(strings.NewReader(s))
+	for {
+		tok, err := d.Token()
+		if err == io.EOF {
+			return tokens
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		switch tt := tok.(type) {
+		case xml.StartElement:
+			var b strings.Builder
+			b.WriteString("<" + tt.Name.Local)
+			for _, a := range tt.Attr {
+				fmt.Fprintf(&b, " %s=%q", a.Name.Local, a.Value)
+			}
+			tokens = append(tokens, b.String()+">")
+		case xml.EndElement:
+			tokens = append(tokens, "</"+tt.Name.Local+">")
+		case xml.CharData:
+			tokens = append(tokens, string(tt))
+		}
+	}
+}
+
+func TestRuleDefinitionEdit(t *testing.T) {
+	d, err := ParseRuleXML(`<rule enabled="false" id="1"><testDefinitions/><name>r</name></rule>`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	d.SetEnabled(true)
+	d.Notes = "added"
+	d.Tests = append(d.Tests, RuleTest{Name: "t", Text: "when"})
+	s, err := d.MarshalRuleXML()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `<rule enabled="true" id="1"><testDefinitions><test name="t"><text>when</text></test></testDefinitions><name>r</name><notes>added</notes></rule>`
+	if s != want {
+		t.Errorf("got %s, want %s", s, want)
+	}
+}
+
+func TestRuleReferences(t *testing.T) {
+	rules := &XMLElement{Attrs: []xml.Attr{{Name: xml.Name{Local: "method"}, Value: "com.q1labs.sem.ui.semservices.UISemServices.getRulesTreeMenu"}}}
+	tests := []struct {
+		name string
+		test RuleTest
+		want []string
+	}{
+		{
+			name: "known test",
+			test: RuleTest{Name: "com.q1labs.semsources.cre.tests.RuleMatch_Test", Parameters: []RuleTestParameter{{ID: "1", UserSelection: "0"}, {ID: "2", UserSelection: "100, 101"}}},
+			want: []string{"2"},
+		},
+		{
+			name: "count and rules",
+			test: RuleTest{Name: "com.q1labs.semsources.cre.tests.MultiRuleCountMatchTest", Parameters: []RuleTestParameter{{ID: "1", UserSelection: "100"}, {ID: "2", UserSelection: "101", UserOptions: rules}}},
+			want: []string{"2"},
+		},
+		{
+			name: "no references",
+			test: RuleTest{Name: "com.q1labs.semsources.cre.tests.EventPropertyTest", Parameters: []RuleTestParameter{{ID: "1", UserSelection: "100"}}},
+		},
+	}
+	for _, tt := range tests {
+		var got []string
+		for _, p := range tt.test.RuleReferences() {
+			got = append(got, p.ID)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: RuleReferences() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestRuleTestParameterMarshal(t *testing.T) {
+	tests := []struct {
+		name string
+		p    RuleTestParameter
+		want string
+	}{
+		{
+			name: "selection only",
+			p:    RuleTestParameter{ID: "1", UserSelection: "40"},
+			want: `<RuleTestParameter id="1"><userSelection>40</userSelection></RuleTestParameter>`,
+		},
+		{
+			name: "user options",
+			p:    RuleTestParameter{ID: "2", UserOptions: &XMLElement{}},
+			want: `<RuleTestParameter id="2"><userOptions></userOptions></RuleTestParameter>`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bs, err := xml.Marshal(tt.p)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := string(bs); got != tt.want {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,86 @@
+package rulesync
+
+import (
+	"strings"
+)
+
+// diffContext is the number of unchanged lines around the changes.
+const diffContext = 2
+
+// diff returns the line difference of a and b, the lines are prefixed with
+// "-" if removed, "+" if added and " " if unchanged. Unchanged lines far from
+// the changes are collapsed to "...".
+func diff(a, b string) string {
+	if a == b {
+		return ""
+	}
+	x := splitLines(a)
+	y := splitLines(b)
+
+	// lcs[i][j] is the length of the longest common subsequence of x[i:]
+	// and y[j:].
+	lcs := make([][]int, len(x)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(y)+1)
+	}
+	for i := len(x) - 1; i >= 0; i-- {
+		for j := len(y) - 1; j >= 0; j-- {
+			if x[i] == y[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	var lines []string
+	i, j := 0, 0
+	for i < len(x) || j < len(y) {
+		switch {
+		case i < len(x) && j < len(y) && x[i] == y[j]:
+			lines = append(lines, " "+x[i])
+			i++
+			j++
+		case i < len(x) && (j == len(y) || lcs[i+1][j] >= lcs[i][j+1]):
+			lines = append(lines, "-"+x[i])
+			i++
+		default:
+			lines = append(lines, "+"+y[j])
+			j++
+		}
+	}
+
+	var out strings.Builder
+	skipped := false
+	for k, l := range lines {
+		if l[0] == ' ' && !nearChange(lines, k) {
+			if !skipped {
+				out.WriteString(" ...\n")
+				skipped = true
+			}
+			continue
+		}
+		skipped = false
+		out.WriteString(l)
+		out.WriteByte('\n')
+	}
+	return out.String()
+}
+
+func nearChange(lines []string, k int) bool {
+	for i := k - diffContext; i <= k+diffContext; i++ {
+		if i >= 0 && i < len(lines) && lines[i][0] != ' ' {
+			return true
+		}
+	}
+	return false
+}
+
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+}
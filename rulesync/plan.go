@@ -0,0 +1,419 @@
+package rulesync
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	qradar "github.com/ilyaglow/go-qradar"
+)
+
+// Action represents a change of the content item.
+type Action string
+
+const (
+	// ActionCreate the item doesn't exist in the installation
+	ActionCreate Action = "create"
+
+	// ActionUpdate the item differs from the installation
+	ActionUpdate Action = "update"
+)
+
+// Change represents a planned change of the content item.
+type Change struct {
+	Kind string
+	// Identifier is the identifier of the item or the name of the group.
+	Identifier string
+	Name       string
+	Action     Action
+	// Diff is the line difference of the item in the installation and the
+	// tree.
+	Diff string
+
+	rule     *RuleFile
+	property *Property
+	group    *Group
+}
+
+// Plan represents the changes that make the installation match the tree.
+// Items of the installation that are not in the tree are left intact.
+type Plan struct {
+	Changes []Change
+
+	idx *index
+}
+
+// NewPlan compares the desired content with the installation. Changes are
+// ordered so that properties and building blocks are applied before the
+// rules using them and rules before the groups containing them.
+func NewPlan(ctx context.Context, client *qradar.Client, desired *Content) (*Plan, error) {
+	current, idx, err := fetch(ctx, client)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &Plan{idx: idx}
+
+	currentProps := make(map[string]*Property)
+	for i := range current.Properties {
+		currentProps[current.Properties[i].Identifier] = &current.Properties[i]
+	}
+	for i := range desired.Properties {
+		d := &desired.Properties[i]
+		ch := Change{Kind: KindProperty, Identifier: d.Identifier, Name: d.Name, property: d}
+		err = p.add(ch, d, currentProps[d.Identifier], marshalJSON)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	currentRules := make(map[string]*RuleFile)
+	for i := range current.Rules {
+		currentRules[current.Rules[i].Identifier] = &current.Rules[i]
+	}
+	for i := range desired.Rules {
+		d := &desired.Rules[i]
+		ch := Change{Kind: d.Kind, Identifier: d.Identifier, Name: d.Definition.Name, rule: d}
+		err = p.add(ch, d, currentRules[d.Identifier], func(v interface{}) ([]byte, error) {
+			return v.(*RuleFile).Marshal()
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	currentGroups := make(map[string]*Group)
+	for i := range current.Groups {
+		currentGroups[current.Groups[i].Name] = &current.Groups[i]
+	}
+	for _, d := range parentsFirst(desired.Groups) {
+		ch := Change{Kind: KindGroup, Identifier: d.Name, Name: d.Name, group: d}
+		err = p.add(ch, d, currentGroups[d.Name], marshalJSON)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return p, nil
+}
+
+// add adds the change if the desired item differs from the current one.
+// The current item is a typed nil pointer if it doesn't exist.
+func (p *Plan) add(ch Change, desired, current interface{}, marshal func(interface{}) ([]byte, error)) error {
+	want, err := marshal(desired)
+	if err != nil {
+		return fmt.Errorf("%s %s: %w", ch.Kind, ch.Identifier, err)
+	}
+
+	ch.Action = ActionCreate
+	var have []byte
+	if !isNil(current) {
+		ch.Action = ActionUpdate
+		have, err = marshal(current)
+		if err != nil {
+			return fmt.Errorf("%s %s: %w", ch.Kind, ch.Identifier, err)
+		}
+	}
+
+	ch.Diff = diff(string(have), string(want))
+	if ch.Diff == "" {
+		return nil
+	}
+	p.Changes = append(p.Changes, ch)
+	return nil
+}
+
+func isNil(v interface{}) bool {
+	switch v := v.(type) {
+	case *Property:
+		return v == nil
+	case *RuleFile:
+		return v == nil
+	case *Group:
+		return v == nil
+	}
+	return v == nil
+}
+
+// parentsFirst returns the groups ordered so that parents precede children.
+func parentsFirst(groups []Group) []*Group {
+	byName := make(map[string]*Group)
+	for i := range groups {
+		byName[groups[i].Name] = &groups[i]
+	}
+
+	var result []*Group
+	visited := make(map[string]bool)
+	var visit func(g *Group)
+	visit = func(g *Group) {
+		if visited[g.Name] {
+			return
+		}
+		visited[g.Name] = true
+		if parent, ok := byName[g.Parent]; ok {
+			visit(parent)
+		}
+		result = append(result, g)
+	}
+	for i := range groups {
+		visit(&groups[i])
+	}
+	return result
+}
+
+// WriteDiff writes the planned changes with their differences.
+func (p *Plan) WriteDiff(w io.Writer) error {
+	for _, ch := range p.Changes {
+		_, err := fmt.Fprintf(w, "%s %s %s (%s)\n", ch.Action, ch.Kind, ch.Identifier, ch.Name)
+		if err != nil {
+			return err
+		}
+		for _, l := range splitLines(ch.Diff) {
+			_, err = fmt.Fprintf(w, "  %s\n", l)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Result represents the outcome of the change.
+type Result struct {
+	Change
+	// ID is the ID of the item in the installation.
+	ID  int
+	Err error
+}
+
+// Report represents the outcome of the plan.
+type Report struct {
+	Results []Result
+}
+
+// Failed returns true if any of the changes has failed.
+func (r *Report) Failed() bool {
+	for i := range r.Results {
+		if r.Results[i].Err != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// WriteSummary writes a line per change with its outcome.
+func (r *Report) WriteSummary(w io.Writer) error {
+	for _, res := range r.Results {
+		status := "ok"
+		if res.Err != nil {
+			status = res.Err.Error()
+		}
+		_, err := fmt.Fprintf(w, "%s %s %s: %s\n", res.Action, res.Kind, res.Identifier, status)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Apply applies the changes in order. Errors of a single change don't stop
+// the others and are reported in its Result; the returned error is only set
+// if the context is done.
+func (p *Plan) Apply(ctx context.Context, client *qradar.Client) (*Report, error) {
+	r := &Report{}
+	for _, ch := range p.Changes {
+		var id int
+		var err error
+		switch {
+		case ch.property != nil:
+			id, err = p.applyProperty(ctx, client, ch.property)
+		case ch.rule != nil:
+			id, err = p.applyRule(ctx, client, ch.rule)
+		case ch.group != nil:
+			id, err = p.applyGroup(ctx, client, ch.group)
+		}
+		r.Results = append(r.Results, Result{Change: ch, ID: id, Err: err})
+		if ctx.Err() != nil {
+			return r, ctx.Err()
+		}
+	}
+	return r, nil
+}
+
+// Import plans the changes of the tree in the directory, writes the
+// difference to w if set and applies the changes unless dryRun is true.
+func Import(ctx context.Context, client *qradar.Client, dir string, w io.Writer, dryRun bool) (*Report, error) {
+	desired, err := ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	p, err := NewPlan(ctx, client, desired)
+	if err != nil {
+		return nil, err
+	}
+	if w != nil {
+		err = p.WriteDiff(w)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if dryRun {
+		r := &Report{}
+		for _, ch := range p.Changes {
+			r.Results = append(r.Results, Result{Change: ch})
+		}
+		return r, nil
+	}
+	return p.Apply(ctx, client)
+}
+
+func (p *Plan) applyProperty(ctx context.Context, client *qradar.Client, d *Property) (int, error) {
+	data := qradar.RegexProperty{
+		Identifier:       &d.Identifier,
+		Name:             &d.Name,
+		Description:      optional(d.Description),
+		PropertyType:     optional(d.PropertyType),
+		DatetimeFormat:   optional(d.DatetimeFormat),
+		Locale:           optional(d.Locale),
+		UseForRuleEngine: &d.UseForRuleEngine,
+	}
+
+	var res *qradar.RegexProperty
+	id, ok := p.idx.properties[d.Identifier]
+	var err error
+	if ok {
+		res, err = client.RegexProperty.UpdateByID(ctx, "id", id, data)
+	} else {
+		res, err = client.RegexProperty.Create(ctx, "id", data)
+	}
+	if err != nil {
+		return 0, err
+	}
+	if res.ID != nil {
+		id = *res.ID
+	}
+	p.idx.properties[d.Identifier] = id
+	return id, nil
+}
+
+func (p *Plan) applyRule(ctx context.Context, client *qradar.Client, f *RuleFile) (int, error) {
+	s, err := f.Definition.MarshalRuleXML()
+	if err != nil {
+		return 0, err
+	}
+	def, err := qradar.ParseRuleXML(s)
+	if err != nil {
+		return 0, err
+	}
+
+	ids := make(map[string]string, len(p.idx.rules))
+	for ident, id := range p.idx.rules {
+		ids[ident] = strconv.Itoa(id)
+	}
+	replaceRuleRefs(def, ids)
+
+	id, ok := p.idx.rules[f.Identifier]
+	if ok {
+		def.ID = strconv.Itoa(id)
+	}
+	s, err = def.MarshalRuleXML()
+	if err != nil {
+		return 0, err
+	}
+	ident := f.Identifier
+
+	var resID *int
+	switch f.Kind {
+	case KindBuildingBlock:
+		data := qradar.BuildingBlockWithData{
+			BuildingBlock: qradar.BuildingBlock{Identifier: &ident},
+			RuleXML:       &s,
+		}
+		var res *qradar.BuildingBlockWithData
+		if ok {
+			res, err = client.BuildingBlockWithData.UpdateByID(ctx, "id", id, data)
+		} else {
+			res, err = client.BuildingBlockWithData.Create(ctx, "id", data)
+		}
+		if err != nil {
+			return 0, err
+		}
+		resID = res.ID
+	default:
+		data := qradar.RuleWithData{
+			Rule:    qradar.Rule{Identifier: &ident},
+			RuleXML: &s,
+		}
+		var res *qradar.RuleWithData
+		if ok {
+			res, err = client.RuleWithData.UpdateByID(ctx, "id", id, data)
+		} else {
+			res, err = client.RuleWithData.Create(ctx, "id", data)
+		}
+		if err != nil {
+			return 0, err
+		}
+		resID = res.ID
+	}
+
+	if resID != nil {
+		id = *resID
+	}
+	p.idx.rules[f.Identifier] = id
+	return id, nil
+}
+
+func (p *Plan) applyGroup(ctx context.Context, client *qradar.Client, g *Group) (int, error) {
+	items := make([]string, 0, len(g.Rules))
+	var missing []string
+	for _, ident := range g.Rules {
+		id, ok := p.idx.rules[ident]
+		if !ok {
+			missing = append(missing, ident)
+			continue
+		}
+		items = append(items, strconv.Itoa(id))
+	}
+	if len(missing) > 0 {
+		return 0, fmt.Errorf("unknown rules %s", strings.Join(missing, ", "))
+	}
+
+	data := map[string]interface{}{
+		"name":        g.Name,
+		"description": g.Description,
+		"child_items": items,
+	}
+	if g.Parent != "" {
+		parentID, ok := p.idx.groups[g.Parent]
+		if !ok {
+			return 0, fmt.Errorf("unknown parent group %q", g.Parent)
+		}
+		data["parent_id"] = parentID
+	}
+
+	var res *qradar.RuleGroup
+	id, ok := p.idx.groups[g.Name]
+	var err error
+	if ok {
+		res, err = client.RuleGroup.UpdateByID(ctx, "id", id, data)
+	} else {
+		res, err = client.RuleGroup.Create(ctx, "id", data)
+	}
+	if err != nil {
+		return 0, err
+	}
+	if res.ID != nil {
+		id = *res.ID
+	}
+	p.idx.groups[g.Name] = id
+	return id, nil
+}
+
+func optional(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
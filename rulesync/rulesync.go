@@ -0,0 +1,415 @@
+// Package rulesync exports QRadar's detection content to a directory tree and
+// imports it back, so rules, building blocks, rule groups and custom
+// properties can be kept in git.
+//
+// The tree has the following layout:
+//
+//	rules/<identifier>.xml
+//	building_blocks/<identifier>.xml
+//	properties/<identifier>.json
+//	groups.json
+//
+// IDs of the installation are replaced by the identifiers, so the tree is
+// stable between exports and portable between installations.
+package rulesync
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	qradar "github.com/ilyaglow/go-qradar"
+)
+
+// Kinds of the content.
+const (
+	KindRule          = "rule"
+	KindBuildingBlock = "building_block"
+	KindProperty      = "property"
+	KindGroup         = "group"
+)
+
+// Paths of the tree.
+const (
+	RulesDir          = "rules"
+	BuildingBlocksDir = "building_blocks"
+	PropertiesDir     = "properties"
+	GroupsFile        = "groups.json"
+)
+
+// RuleFile represents a rule or a building block of the tree. The id of the
+// definition is omitted and references to other rules in the tests are
+// replaced with their identifiers.
+type RuleFile struct {
+	XMLName    xml.Name               `xml:"ruleFile"`
+	Identifier string                 `xml:"identifier,attr"`
+	Kind       string                 `xml:"kind,attr"`
+	Origin     string                 `xml:"origin,attr,omitempty"`
+	Definition *qradar.RuleDefinition `xml:"rule"`
+}
+
+// Property represents a custom property of the tree.
+type Property struct {
+	Identifier       string `json:"identifier"`
+	Name             string `json:"name"`
+	Description      string `json:"description,omitempty"`
+	PropertyType     string `json:"property_type,omitempty"`
+	DatetimeFormat   string `json:"datetime_format,omitempty"`
+	Locale           string `json:"locale,omitempty"`
+	UseForRuleEngine bool   `json:"use_for_rule_engine"`
+}
+
+// Group represents a rule group of the tree. The parent is referenced by
+// name and the rules by their identifiers.
+type Group struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description,omitempty"`
+	Parent      string   `json:"parent,omitempty"`
+	Rules       []string `json:"rules,omitempty"`
+}
+
+// Content represents the detection content in a stable order: rule files by
+// the kind and identifier, groups by name and properties by identifier.
+type Content struct {
+	Rules      []RuleFile
+	Groups     []Group
+	Properties []Property
+}
+
+// index maps the identifiers of the content to the IDs of the installation.
+type index struct {
+	rules      map[string]int
+	properties map[string]int
+	groups     map[string]int
+}
+
+// Fetch returns the detection content of the installation.
+func Fetch(ctx context.Context, client *qradar.Client) (*Content, error) {
+	c, _, err := fetch(ctx, client)
+	return c, err
+}
+
+// Export writes the detection content of the installation to the directory.
+func Export(ctx context.Context, client *qradar.Client, dir string) error {
+	c, err := Fetch(ctx, client)
+	if err != nil {
+		return err
+	}
+	return c.WriteDir(dir)
+}
+
+func fetch(ctx context.Context, client *qradar.Client) (*Content, *index, error) {
+	rules, err := client.RuleWithData.Get(ctx, "", "", 0, 0)
+	if err != nil {
+		return nil, nil, err
+	}
+	bbs, err := client.BuildingBlockWithData.Get(ctx, "", "", 0, 0)
+	if err != nil {
+		return nil, nil, err
+	}
+	groups, err := client.RuleGroup.Get(ctx, "", "", 0, 0)
+	if err != nil {
+		return nil, nil, err
+	}
+	props, err := client.RegexProperty.Get(ctx, "", "", 0, 0)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	idx := &index{
+		rules:      make(map[string]int),
+		properties: make(map[string]int),
+		groups:     make(map[string]int),
+	}
+	identifiers := make(map[string]string)
+
+	type source struct {
+		kind   string
+		id     *int
+		ident  *string
+		origin *string
+		xml    *string
+	}
+	var sources []source
+	for _, r := range rules {
+		if r.IsBuildingBlock != nil && *r.IsBuildingBlock {
+			continue
+		}
+		sources = append(sources, source{KindRule, r.ID, r.Identifier, r.Origin, r.RuleXML})
+	}
+	for _, b := range bbs {
+		sources = append(sources, source{KindBuildingBlock, b.ID, b.Identifier, b.Origin, b.RuleXML})
+	}
+	for _, s := range sources {
+		if s.id == nil || s.ident == nil || *s.ident == "" {
+			return nil, nil, fmt.Errorf("%s without id or identifier", s.kind)
+		}
+		idx.rules[*s.ident] = *s.id
+		identifiers[strconv.Itoa(*s.id)] = *s.ident
+	}
+
+	c := &Content{}
+	for _, s := range sources {
+		if s.xml == nil {
+			return nil, nil, fmt.Errorf("%s %s has no rule_xml", s.kind, *s.ident)
+		}
+		def, err := qradar.ParseRuleXML(*s.xml)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%s %s: %w", s.kind, *s.ident, err)
+		}
+		def.ID = ""
+		replaceRuleRefs(def, identifiers)
+		c.Rules = append(c.Rules, RuleFile{
+			Identifier: *s.ident,
+			Kind:       s.kind,
+			Origin:     stringValue(s.origin),
+			Definition: def,
+		})
+	}
+
+	groupNames := make(map[int]string)
+	for _, g := range groups {
+		if g.ID == nil || g.Name == nil {
+			continue
+		}
+		if _, ok := idx.groups[*g.Name]; ok {
+			return nil, nil, fmt.Errorf("duplicate rule group name %q", *g.Name)
+		}
+		idx.groups[*g.Name] = *g.ID
+		groupNames[*g.ID] = *g.Name
+	}
+	for _, g := range groups {
+		if g.ID == nil || g.Name == nil {
+			continue
+		}
+		group := Group{Name: *g.Name, Description: stringValue(g.Description)}
+		if g.ParentID != nil {
+			group.Parent = groupNames[*g.ParentID]
+		}
+		for _, item := range g.ChildItems {
+			if ident, ok := identifiers[item]; ok {
+				group.Rules = append(group.Rules, ident)
+			}
+		}
+		sort.Strings(group.Rules)
+		c.Groups = append(c.Groups, group)
+	}
+
+	for _, p := range props {
+		if p.ID == nil || p.Identifier == nil {
+			continue
+		}
+		idx.properties[*p.Identifier] = *p.ID
+		c.Properties = append(c.Properties, Property{
+			Identifier:       *p.Identifier,
+			Name:             stringValue(p.Name),
+			Description:      stringValue(p.Description),
+			PropertyType:     stringValue(p.PropertyType),
+			DatetimeFormat:   stringValue(p.DatetimeFormat),
+			Locale:           stringValue(p.Locale),
+			UseForRuleEngine: p.UseForRuleEngine != nil && *p.UseForRuleEngine,
+		})
+	}
+
+	c.sort()
+	return c, idx, nil
+}
+
+func (c *Content) sort() {
+	sort.Slice(c.Rules, func(i, j int) bool {
+		if c.Rules[i].Kind != c.Rules[j].Kind {
+			return c.Rules[i].Kind < c.Rules[j].Kind
+		}
+		return c.Rules[i].Identifier < c.Rules[j].Identifier
+	})
+	sort.Slice(c.Groups, func(i, j int) bool { return c.Groups[i].Name < c.Groups[j].Name })
+	sort.Slice(c.Properties, func(i, j int) bool { return c.Properties[i].Identifier < c.Properties[j].Identifier })
+}
+
+// WriteDir writes the content to the directory. Files of the rules, building
+// blocks and properties that are not in the content are removed, so the tree
+// mirrors the content.
+func (c *Content) WriteDir(dir string) error {
+	for _, sub := range []string{RulesDir, BuildingBlocksDir, PropertiesDir} {
+		err := os.RemoveAll(filepath.Join(dir, sub))
+		if err != nil {
+			return err
+		}
+		err = os.MkdirAll(filepath.Join(dir, sub), 0o755)
+		if err != nil {
+			return err
+		}
+	}
+
+	for i := range c.Rules {
+		f := &c.Rules[i]
+		bs, err := f.Marshal()
+		if err != nil {
+			return fmt.Errorf("%s %s: %w", f.Kind, f.Identifier, err)
+		}
+		err = os.WriteFile(filepath.Join(dir, ruleDir(f.Kind), fileName(f.Identifier, ".xml")), bs, 0o644)
+		if err != nil {
+			return err
+		}
+	}
+
+	for i := range c.Properties {
+		bs, err := marshalJSON(c.Properties[i])
+		if err != nil {
+			return err
+		}
+		err = os.WriteFile(filepath.Join(dir, PropertiesDir, fileName(c.Properties[i].Identifier, ".json")), bs, 0o644)
+		if err != nil {
+			return err
+		}
+	}
+
+	groups := c.Groups
+	if groups == nil {
+		groups = []Group{}
+	}
+	bs, err := marshalJSON(groups)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, GroupsFile), bs, 0o644)
+}
+
+// ReadDir reads the content from the directory.
+func ReadDir(dir string) (*Content, error) {
+	c := &Content{}
+
+	for _, kind := range []string{KindRule, KindBuildingBlock} {
+		paths, err := filepath.Glob(filepath.Join(dir, ruleDir(kind), "*.xml"))
+		if err != nil {
+			return nil, err
+		}
+		for _, p := range paths {
+			bs, err := os.ReadFile(p)
+			if err != nil {
+				return nil, err
+			}
+			var f RuleFile
+			err = xml.Unmarshal(bs, &f)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", p, err)
+			}
+			if f.Identifier == "" || f.Definition == nil {
+				return nil, fmt.Errorf("%s: no identifier or rule definition", p)
+			}
+			if f.Kind != kind {
+				return nil, fmt.Errorf("%s: %s in the %s directory", p, f.Kind, ruleDir(kind))
+			}
+			c.Rules = append(c.Rules, f)
+		}
+	}
+
+	paths, err := filepath.Glob(filepath.Join(dir, PropertiesDir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range paths {
+		bs, err := os.ReadFile(p)
+		if err != nil {
+			return nil, err
+		}
+		var prop Property
+		err = json.Unmarshal(bs, &prop)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", p, err)
+		}
+		if prop.Identifier == "" {
+			return nil, fmt.Errorf("%s: no identifier", p)
+		}
+		c.Properties = append(c.Properties, prop)
+	}
+
+	bs, err := os.ReadFile(filepath.Join(dir, GroupsFile))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	if err == nil {
+		err = json.Unmarshal(bs, &c.Groups)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", GroupsFile, err)
+		}
+	}
+	for i := range c.Groups {
+		sort.Strings(c.Groups[i].Rules)
+	}
+
+	c.sort()
+	return c, nil
+}
+
+// Marshal returns the rule file as indented XML.
+func (f *RuleFile) Marshal() ([]byte, error) {
+	bs, err := xml.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), append(bs, '\n')...), nil
+}
+
+func marshalJSON(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	err := enc.Encode(v)
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func ruleDir(kind string) string {
+	if kind == KindBuildingBlock {
+		return BuildingBlocksDir
+	}
+	return RulesDir
+}
+
+// fileName returns a file name of the identifier safe for the file systems.
+func fileName(identifier, ext string) string {
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case '/', '\\', ':', '*', '?', '"', '<', '>', '|':
+			return '_'
+		}
+		return r
+	}, identifier) + ext
+}
+
+// replaceRuleRefs replaces the references to the rules in the tests of the
+// definition by the mapping, tokens without a mapping are kept as is. Only
+// the parameters that refer to the rules are changed, so the counts that
+// equal the IDs of the rules are kept.
+func replaceRuleRefs(def *qradar.RuleDefinition, mapping map[string]string) {
+	for i := range def.Tests {
+		for _, p := range def.Tests[i].RuleReferences() {
+			parts := strings.Split(p.UserSelection, ",")
+			for k, part := range parts {
+				token := strings.TrimSpace(part)
+				if to, ok := mapping[token]; ok {
+					parts[k] = strings.Replace(part, token, to, 1)
+				}
+			}
+			p.UserSelection = strings.Join(parts, ",")
+		}
+	}
+}
+
+func stringValue(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
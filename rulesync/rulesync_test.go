@@ -0,0 +1,135 @@
+package rulesync
+
+import (
+	"reflect"
+	"testing"
+
+	qradar "github.com/ilyaglow/go-qradar"
+)
+
+func TestDiff(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b string
+		want string
+	}{
+		{"equal", "a\nb\n", "a\nb\n", ""},
+		{"create", "", "a\nb\n", "+a\n+b\n"},
+		{"change", "a\nb\nc\n", "a\nx\nc\n", " a\n-b\n+x\n c\n"},
+		{
+			name: "collapsed",
+			a:    "1\n2\n3\n4\n5\n6\n7\n",
+			b:    "1\n2\n3\n4\n5\n6\nseven\n",
+			want: " ...\n 5\n 6\n-7\n+seven\n",
+		},
+	}
+	for _, tt := range tests {
+		if got := diff(tt.a, tt.b); got != tt.want {
+			t.Errorf("%s: diff() = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestReplaceRuleRefs(t *testing.T) {
+	def := &qradar.RuleDefinition{
+		Tests: []qradar.RuleTest{
+			{
+				Name:       "com.q1labs.semsources.cre.tests.RuleMatch_Test",
+				Parameters: []qradar.RuleTestParameter{{ID: "1", UserSelection: "100"}, {ID: "2", UserSelection: "100, 200,300"}},
+			},
+			{
+				Name:       "com.q1labs.semsources.cre.tests.DeviceTypeID_Test",
+				Parameters: []qradar.RuleTestParameter{{ID: "1", UserSelection: "100"}},
+			},
+			{
+				// the count equals the ID of a remapped rule
+				Name:       "com.q1labs.semsources.cre.tests.MultiRuleCountMatchTest",
+				Parameters: []qradar.RuleTestParameter{{ID: "1", UserSelection: "100"}},
+			},
+		},
+	}
+	replaceRuleRefs(def, map[string]string{"100": "uuid-a", "300": "uuid-c"})
+
+	var got []string
+	for _, test := range def.Tests {
+		for _, p := range test.Parameters {
+			got = append(got, p.UserSelection)
+		}
+	}
+	want := []string{"100", "uuid-a, 200,uuid-c", "100", "100"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestFileName(t *testing.T) {
+	tests := []struct {
+		identifier string
+		want       string
+	}{
+		{"SYSTEM-1234", "SYSTEM-1234.xml"},
+		{`a/b\c:d*e?f"g<h>i|j`, "a_b_c_d_e_f_g_h_i_j.xml"},
+	}
+	for _, tt := range tests {
+		if got := fileName(tt.identifier, ".xml"); got != tt.want {
+			t.Errorf("fileName(%q) = %q, want %q", tt.identifier, got, tt.want)
+		}
+	}
+}
+
+func TestParentsFirst(t *testing.T) {
+	groups := []Group{
+		{Name: "leaf", Parent: "middle"},
+		{Name: "root"},
+		{Name: "middle", Parent: "root"},
+		{Name: "orphan", Parent: "missing"},
+	}
+	var got []string
+	for _, g := range parentsFirst(groups) {
+		got = append(got, g.Name)
+	}
+	want := []string{"root", "middle", "leaf", "orphan"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parentsFirst() = %v, want %v", got, want)
+	}
+}
+
+func TestWriteReadDir(t *testing.T) {
+	def, err := qradar.ParseRuleXML(`<rule enabled="true" owner="admin"><name>Rule A</name><testDefinitions>` +
+		`<test name="com.q1labs.semsources.cre.tests.RuleMatch_Test"><text>when</text>` +
+		`<parameter id="2"><userSelection>uuid-b</userSelection></parameter></test></testDefinitions></rule>`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bb, err := qradar.ParseRuleXML(`<rule enabled="true"><name>BB B</name><testDefinitions></testDefinitions></rule>`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := &Content{
+		Rules: []RuleFile{
+			{Identifier: "uuid-b", Kind: KindBuildingBlock, Definition: bb},
+			{Identifier: "uuid-a", Kind: KindRule, Origin: "USER", Definition: def},
+		},
+		Groups:     []Group{{Name: "Custom", Parent: "Root", Rules: []string{"uuid-a", "uuid-b"}}},
+		Properties: []Property{{Identifier: "prop-1", Name: "Prop", PropertyType: "string", UseForRuleEngine: true}},
+	}
+
+	dir := t.TempDir()
+	err = want.WriteDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the decoder sets the element names the literals leave empty
+	for i := range got.Rules {
+		got.Rules[i].XMLName = want.Rules[i].XMLName
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ReadDir() = %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,53 @@
+package qradar
+
+import (
+	"context"
+	"net/http"
+)
+
+// SourceAddressService handles methods related to Offense Source Addresses of the QRadar API.
+type SourceAddressService service
+
+const sourceAddressAPIPrefix = "api/siem/source_addresses"
+
+// SourceAddress represents QRadar's Offense Source Address.
+type SourceAddress struct {
+	ID                         *int    `json:"id,omitempty"`
+	SourceIP                   *string `json:"source_ip,omitempty"`
+	Magnitude                  *int    `json:"magnitude,omitempty"`
+	Network                    *string `json:"network,omitempty"`
+	DomainID                   *int    `json:"domain_id,omitempty"`
+	EventFlowCount             *int    `json:"event_flow_count,omitempty"`
+	FirstEventFlowSeen         *int    `json:"first_event_flow_seen,omitempty"`
+	LastEventFlowSeen          *int    `json:"last_event_flow_seen,omitempty"`
+	OffenseIDs                 []int   `json:"offense_ids,omitempty"`
+	LocalDestinationAddressIDs []int   `json:"local_destination_address_ids,omitempty"`
+}
+
+// Get returns Offense Source Addresses of the current QRadar installation.
+func (c *SourceAddressService) Get(ctx context.Context, fields, filter string, from, to int) ([]SourceAddress, error) {
+	req, err := c.client.requestHelp(http.MethodGet, sourceAddressAPIPrefix, fields, filter, from, to, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	var result []SourceAddress
+	_, err = c.client.Do(ctx, req, &result)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetByID returns Offense Source Address of the current QRadar installation by ID.
+func (c *SourceAddressService) GetByID(ctx context.Context, fields string, id int) (*SourceAddress, error) {
+	req, err := c.client.requestHelp(http.MethodGet, sourceAddressAPIPrefix, fields, "", 0, 0, &id, nil)
+	if err != nil {
+		return nil, err
+	}
+	var result SourceAddress
+	_, err = c.client.Do(ctx, req, &result)
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
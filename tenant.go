@@ -95,7 +95,7 @@ func (c *TenantService) DeleteByID(ctx context.Context, fields string, id int) (
 
 // GetByName returns Tenant of the current QRadar installation by Name.
 func (c *TenantService) GetByName(ctx context.Context, fields string, name string) (*Tenant, error) {
-	req, err := c.client.requestHelp(http.MethodGet, tenantAPIPrefix, fields, fmt.Sprintf("name=\"%s\"", name), 0, 0, nil, nil)
+	req, err := c.client.requestHelp(http.MethodGet, tenantAPIPrefix, fields, "name="+filterString(name), 0, 0, nil, nil)
 	if err != nil {
 		return nil, err
 	}